@@ -29,6 +29,7 @@ import (
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/dline"
+	"github.com/filecoin-project/go-state-types/exitcode"
 
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/power"
@@ -130,6 +131,18 @@ type FullNode interface {
 	// If oldmsgskip is set, messages from before the requested roots are also not included.
 	ChainExport(ctx context.Context, nroots abi.ChainEpoch, oldmsgskip bool, tsk types.TipSetKey) (<-chan []byte, error)
 
+	// ChainExportDelta returns a stream of bytes with a CAR dump of the chain
+	// data added between the base and the target tipset: block headers,
+	// messages, receipts and the state objects which aren't part of the base
+	// state. The base tipset must be an ancestor of the target tipset.
+	ChainExportDelta(ctx context.Context, base types.TipSetKey, target types.TipSetKey) (<-chan []byte, error)
+
+	// ChainStateIndexBackfill indexes the state and receipt roots of the
+	// ancestors of the given tipset down to toHeight, so that state queries at
+	// those epochs don't need to recompute the state. It returns the number of
+	// tipsets indexed.
+	ChainStateIndexBackfill(ctx context.Context, tsk types.TipSetKey, toHeight abi.ChainEpoch) (int, error)
+
 	// MethodGroup: Beacon
 	// The Beacon method group contains methods for interacting with the random beacon (DRAND)
 
@@ -219,8 +232,27 @@ type FullNode interface {
 	// MpoolBatchPushUntrusted batch pushes a signed message to mempool from untrusted sources.
 	MpoolBatchPushUntrusted(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
 
-	// MpoolBatchPushMessage batch pushes a unsigned message to mempool.
-	MpoolBatchPushMessage(context.Context, []*types.Message, *MessageSendSpec) ([]*types.SignedMessage, error)
+	// MpoolBatchPushMessage estimates gas for, signs and pushes a batch of
+	// unsigned messages, assigning contiguous nonces to the messages of each
+	// sender. The results are in the order of the messages; once a message
+	// of a sender fails, its later messages aren't pushed.
+	MpoolBatchPushMessage(context.Context, []*types.Message, *MessageSendSpec) ([]MessagePushResult, error)
+
+	// MpoolBumpStuck replaces the pending messages of the sender with copies
+	// whose gas values are re-estimated for the current chain, spending up
+	// to maxFee per message (DefaultMaxFee when 0). It returns the cids of
+	// the replacements, and an error listing the messages for which maxFee
+	// is too low to outbid them.
+	MpoolBumpStuck(ctx context.Context, from address.Address, maxFee types.BigInt) ([]cid.Cid, error)
+
+	// MpoolCheckGaps returns the nonce gaps in the pending messages of the
+	// senders which pushed messages through this node.
+	MpoolCheckGaps(context.Context) ([]NonceGap, error)
+
+	// MpoolFillGap pushes zero-value self-sends with the missing nonces of
+	// the gap, as returned by MpoolCheckGaps. It returns the cids of the
+	// pushed messages, also on error.
+	MpoolFillGap(context.Context, NonceGap) ([]cid.Cid, error)
 
 	// MpoolGetNonce gets next nonce for the specified sender.
 	// Note that this method may not be atomic. Use MpoolPushMessage instead.
@@ -379,6 +411,12 @@ type FullNode interface {
 	StateReadState(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*ActorState, error)
 	// StateListMessages looks back and returns all messages with a matching to or from address, stopping at the given height.
 	StateListMessages(ctx context.Context, match *MessageMatch, tsk types.TipSetKey, toht abi.ChainEpoch) ([]cid.Cid, error)
+	// StateSearchMessages returns the executed messages matching the filter
+	// which were included in tipsets between fromHeight and toHeight, newest
+	// first. A toHeight of 0 searches up to the head, a limit of 0 returns all
+	// matches. It uses the message index if it is enabled, and walks the chain
+	// for the heights below the start of the index.
+	StateSearchMessages(ctx context.Context, filter *MessageFilter, fromHeight, toHeight abi.ChainEpoch, limit int) ([]IndexedMessage, error)
 	// StateDecodeParams attempts to decode the provided params, based on the recipient actor address and method number.
 	StateDecodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params []byte, tsk types.TipSetKey) (interface{}, error)
 
@@ -964,6 +1002,22 @@ type MpoolUpdate struct {
 	Message *types.SignedMessage
 }
 
+// MessagePushResult is the outcome of pushing one message of a batch.
+type MessagePushResult struct {
+	// Message is the pushed message, nil on error.
+	Message *types.SignedMessage
+	Error   string
+}
+
+// NonceGap describes the nonces missing between the state nonce of a sender
+// and its highest pending message, which keep the pending messages above them
+// from being included.
+type NonceGap struct {
+	From       address.Address
+	StateNonce uint64
+	Missing    []uint64
+}
+
 type ComputeStateOutput struct {
 	Root  cid.Cid
 	Trace []*InvocResult
@@ -1070,6 +1124,44 @@ type MessageMatch struct {
 	From address.Address
 }
 
+// MessageFilter selects the messages returned by StateSearchMessages. At least
+// one of To and From must be set, an empty Methods matches any method.
+type MessageFilter struct {
+	To      address.Address
+	From    address.Address
+	Methods []abi.MethodNum
+}
+
+// Matches returns whether the message passes the filter.
+func (f *MessageFilter) Matches(msg *types.Message) bool {
+	if f.To != address.Undef && f.To != msg.To {
+		return false
+	}
+	if f.From != address.Undef && f.From != msg.From {
+		return false
+	}
+	if len(f.Methods) == 0 {
+		return true
+	}
+	for _, m := range f.Methods {
+		if m == msg.Method {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexedMessage is an executed message found by StateSearchMessages.
+type IndexedMessage struct {
+	Cid    cid.Cid
+	To     address.Address
+	From   address.Address
+	Method abi.MethodNum
+	// Epoch is the height of the tipset including the message
+	Epoch    abi.ChainEpoch
+	ExitCode exitcode.ExitCode
+}
+
 type ExpertRegisterFileParams struct {
 	Expert    address.Address
 	RootID    cid.Cid
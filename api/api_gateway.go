@@ -10,7 +10,10 @@ import (
 	"github.com/filecoin-project/go-state-types/dline"
 	"github.com/filecoin-project/go-state-types/network"
 
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expert"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 )
 
@@ -42,4 +45,11 @@ type GatewayAPI interface {
 	StateSectorGetInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorOnChainInfo, error)
 	// StateVerifiedClientStatus(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*abi.StoragePower, error)
 	StateWaitMsg(ctx context.Context, msg cid.Cid, confidence uint64) (*MsgLookup, error)
+
+	StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
+	StateExpertInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*expert.ExpertInfo, error)
+	StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error)
+	StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error)
+	StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error)
+	StateRetrievalPledge(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*RetrievalState, error)
 }
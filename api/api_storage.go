@@ -52,6 +52,9 @@ type StorageMiner interface {
 
 	SectorsRefs(context.Context) (map[string][]SealedRef, error)
 
+	// SectorsNotify returns a channel of sector state transitions
+	SectorsNotify(ctx context.Context) (<-chan SectorStateChange, error)
+
 	// SectorStartSealing can be called on sectors in Empty or WaitDeals states
 	// to trigger sealing early
 	SectorStartSealing(context.Context, abi.SectorNumber) error
@@ -81,6 +84,15 @@ type StorageMiner interface {
 	StorageList(ctx context.Context) (map[stores.ID][]stores.Decl, error)
 	StorageLocal(ctx context.Context) (map[stores.ID]string, error)
 	StorageStat(ctx context.Context, id stores.ID) (fsutil.FsStat, error)
+	// StorageMoveSector moves the files of a sector between two local storage
+	// paths of the miner, verifying the copy before deleting the source
+	StorageMoveSector(ctx context.Context, sector abi.SectorNumber, from, to stores.ID) error
+	// StorageSetMeta updates the quota and draining state of a local storage
+	// path of the miner, persisting them in its sectorstore.json
+	StorageSetMeta(ctx context.Context, id stores.ID, upd stores.StorageMetaUpdate) error
+	// StorageScrubStatus returns the progress of the background sector
+	// integrity scrubber and the sectors which failed their latest check
+	StorageScrubStatus(ctx context.Context) (storiface.ScrubStatus, error)
 
 	// WorkerConnect tells the node to connect to workers RPC
 	WorkerConnect(context.Context, string) error
@@ -91,6 +103,13 @@ type StorageMiner interface {
 	// SealingSchedDiag dumps internal sealing scheduler state
 	SealingSchedDiag(ctx context.Context, doSched bool) (interface{}, error)
 	SealingAbort(ctx context.Context, call storiface.CallID) error
+	// SealingSchedSetPriority overrides the scheduling priority of the tasks of
+	// a sector, larger values being scheduled first. Sectors which are already
+	// proving or removed are rejected.
+	SealingSchedSetPriority(ctx context.Context, sector abi.SectorNumber, priority int) error
+	// SealingSchedQueue lists the tasks waiting in the sealing scheduler queue,
+	// in scheduling order
+	SealingSchedQueue(ctx context.Context) ([]storiface.SchedQueueEntry, error)
 
 	stores.SectorIndex
 
@@ -137,6 +156,11 @@ type StorageMiner interface {
 	CreateBackup(ctx context.Context, fpath string) error
 
 	CheckProvable(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storage.SectorRef, expensive bool) (map[abi.SectorNumber]string, error)
+
+	// ComputeWindowPoSt checks the sectors of a deadline and generates its
+	// window PoSt the way it's done when the deadline opens, without
+	// submitting anything
+	ComputeWindowPoSt(ctx context.Context, dlIdx uint64, tsk types.TipSetKey) (*WindowPoStDryRun, error)
 }
 
 type SealRes struct {
@@ -169,6 +193,9 @@ type SectorInfo struct {
 	Retries      uint64
 	/* ToUpgrade    bool */
 
+	// Retries out of each failed state
+	StateRetries map[SectorState]uint64
+
 	LastErr string
 
 	Log []SectorLog
@@ -189,6 +216,38 @@ type SectorInfo struct {
 	Early abi.ChainEpoch
 }
 
+type SectorStateChange struct {
+	SectorNumber abi.SectorNumber
+	From         SectorState
+	To           SectorState
+	Error        string // last sector error, if any
+	Time         time.Time
+}
+
+// WindowPoStDryRun is the result of ComputeWindowPoSt
+type WindowPoStDryRun struct {
+	Deadline uint64
+	Height   abi.ChainEpoch // of the tipset the proof was computed at
+
+	Partitions []PartitionDryRun
+	Elapsed    time.Duration
+}
+
+type PartitionDryRun struct {
+	Index   uint64
+	Sectors int // in the proof, including substitutes for the skipped ones
+
+	// sectors which failed the checks before proving, with the reasons
+	Bad map[abi.SectorNumber]string
+	// sectors the prover failed to prove
+	Skipped []abi.SectorNumber
+
+	CheckTime time.Duration
+	ProveTime time.Duration
+
+	Error string `json:",omitempty"`
+}
+
 type SealedRef struct {
 	SectorID abi.SectorNumber
 	Offset   abi.PaddedPieceSize
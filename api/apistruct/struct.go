@@ -30,6 +30,7 @@ import (
 	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/dline"
 	stnetwork "github.com/filecoin-project/go-state-types/network"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 	"github.com/filecoin-project/specs-storage/storage"
 
 	"github.com/EpiK-Protocol/go-epik/api"
@@ -107,6 +108,8 @@ type FullNodeStruct struct {
 		ChainGetMessage               func(context.Context, cid.Cid) (*types.Message, error)                                                             `perm:"read"`
 		ChainGetPath                  func(context.Context, types.TipSetKey, types.TipSetKey) ([]*api.HeadChange, error)                                 `perm:"read"`
 		ChainExport                   func(context.Context, abi.ChainEpoch, bool, types.TipSetKey) (<-chan []byte, error)                                `perm:"read"`
+		ChainExportDelta              func(context.Context, types.TipSetKey, types.TipSetKey) (<-chan []byte, error)                                     `perm:"read"`
+		ChainStateIndexBackfill       func(context.Context, types.TipSetKey, abi.ChainEpoch) (int, error)                                                `perm:"admin"`
 
 		BeaconGetEntry func(ctx context.Context, epoch abi.ChainEpoch) (*types.BeaconEntry, error) `perm:"read"`
 
@@ -140,9 +143,12 @@ type FullNodeStruct struct {
 		MpoolGetNonce    func(context.Context, address.Address) (uint64, error)                                    `perm:"read"`
 		MpoolSub         func(context.Context) (<-chan api.MpoolUpdate, error)                                     `perm:"read"`
 
-		MpoolBatchPush          func(ctx context.Context, smsgs []*types.SignedMessage) ([]cid.Cid, error)                                  `perm:"write"`
-		MpoolBatchPushUntrusted func(ctx context.Context, smsgs []*types.SignedMessage) ([]cid.Cid, error)                                  `perm:"write"`
-		MpoolBatchPushMessage   func(ctx context.Context, msgs []*types.Message, spec *api.MessageSendSpec) ([]*types.SignedMessage, error) `perm:"sign"`
+		MpoolBatchPush          func(ctx context.Context, smsgs []*types.SignedMessage) ([]cid.Cid, error)                                   `perm:"write"`
+		MpoolBatchPushUntrusted func(ctx context.Context, smsgs []*types.SignedMessage) ([]cid.Cid, error)                                   `perm:"write"`
+		MpoolBatchPushMessage   func(ctx context.Context, msgs []*types.Message, spec *api.MessageSendSpec) ([]api.MessagePushResult, error) `perm:"sign"`
+		MpoolBumpStuck          func(ctx context.Context, from address.Address, maxFee types.BigInt) ([]cid.Cid, error)                      `perm:"sign"`
+		MpoolCheckGaps          func(ctx context.Context) ([]api.NonceGap, error)                                                            `perm:"read"`
+		MpoolFillGap            func(ctx context.Context, gap api.NonceGap) ([]cid.Cid, error)                                               `perm:"sign"`
 
 		MinerGetBaseInfo func(context.Context, address.Address, abi.ChainEpoch, types.TipSetKey) (*api.MiningBaseInfo, error) `perm:"read"`
 		MinerCreateBlock func(context.Context, *api.BlockTemplate) (*types.BlockMsg, error)                                   `perm:"write"`
@@ -238,6 +244,7 @@ type FullNodeStruct struct {
 		StateGetReceipt            func(context.Context, cid.Cid, types.TipSetKey) (*types.MessageReceipt, error)                                      `perm:"read"`
 		StateMinerSectorCount      func(context.Context, address.Address, types.TipSetKey) (api.MinerSectors, error)                                   `perm:"read"`
 		StateListMessages          func(ctx context.Context, match *api.MessageMatch, tsk types.TipSetKey, toht abi.ChainEpoch) ([]cid.Cid, error)     `perm:"read"`
+		StateSearchMessages        func(context.Context, *api.MessageFilter, abi.ChainEpoch, abi.ChainEpoch, int) ([]api.IndexedMessage, error)        `perm:"read"`
 		StateDecodeParams          func(context.Context, address.Address, abi.MethodNum, []byte, types.TipSetKey) (interface{}, error)                 `perm:"read"`
 		StateCompute               func(context.Context, abi.ChainEpoch, []*types.Message, types.TipSetKey) (*api.ComputeStateOutput, error)           `perm:"read"`
 		/* StateVerifierStatus                func(context.Context, address.Address, types.TipSetKey) (*abi.StoragePower, error)                                   `perm:"read"`
@@ -341,6 +348,7 @@ type StorageMinerStruct struct {
 		SectorsListInStates           func(context.Context, []api.SectorState) ([]abi.SectorNumber, error)                          `perm:"read"`
 		SectorsSummary                func(ctx context.Context) (map[api.SectorState]int, error)                                    `perm:"read"`
 		SectorsRefs                   func(context.Context) (map[string][]api.SealedRef, error)                                     `perm:"read"`
+		SectorsNotify                 func(ctx context.Context) (<-chan api.SectorStateChange, error)                               `perm:"read"`
 		SectorStartSealing            func(context.Context, abi.SectorNumber) error                                                 `perm:"write"`
 		SectorSetSealDelay            func(context.Context, time.Duration) error                                                    `perm:"write"`
 		SectorGetSealDelay            func(context.Context) (time.Duration, error)                                                  `perm:"read"`
@@ -368,12 +376,19 @@ type StorageMinerStruct struct {
 		ReturnReadPiece       func(ctx context.Context, callID storiface.CallID, ok bool, err *storiface.CallError) error                   `perm:"admin" retry:"true"`
 		ReturnFetch           func(ctx context.Context, callID storiface.CallID, err *storiface.CallError) error                            `perm:"admin" retry:"true"`
 
-		SealingSchedDiag func(context.Context, bool) (interface{}, error)       `perm:"admin"`
-		SealingAbort     func(ctx context.Context, call storiface.CallID) error `perm:"admin"`
+		ReturnGenerateWindowPoSt func(ctx context.Context, callID storiface.CallID, res storiface.WindowPoStResult, err *storiface.CallError) error `perm:"admin" retry:"true"`
+
+		SealingSchedDiag        func(context.Context, bool) (interface{}, error)           `perm:"admin"`
+		SealingAbort            func(ctx context.Context, call storiface.CallID) error     `perm:"admin"`
+		SealingSchedSetPriority func(context.Context, abi.SectorNumber, int) error         `perm:"admin"`
+		SealingSchedQueue       func(context.Context) ([]storiface.SchedQueueEntry, error) `perm:"read"`
 
 		StorageList          func(context.Context) (map[stores.ID][]stores.Decl, error)                                                                                   `perm:"admin"`
 		StorageLocal         func(context.Context) (map[stores.ID]string, error)                                                                                          `perm:"admin"`
 		StorageStat          func(context.Context, stores.ID) (fsutil.FsStat, error)                                                                                      `perm:"admin"`
+		StorageMoveSector    func(context.Context, abi.SectorNumber, stores.ID, stores.ID) error                                                                          `perm:"admin"`
+		StorageSetMeta       func(context.Context, stores.ID, stores.StorageMetaUpdate) error                                                                             `perm:"admin"`
+		StorageScrubStatus   func(context.Context) (storiface.ScrubStatus, error)                                                                                         `perm:"read"`
 		StorageAttach        func(context.Context, stores.StorageInfo, fsutil.FsStat) error                                                                               `perm:"admin"`
 		StorageDeclareSector func(context.Context, stores.ID, abi.SectorID, storiface.SectorFileType, bool) error                                                         `perm:"admin"`
 		StorageDropSector    func(context.Context, stores.ID, abi.SectorID, storiface.SectorFileType) error                                                               `perm:"admin"`
@@ -406,7 +421,8 @@ type StorageMinerStruct struct {
 
 		CreateBackup func(ctx context.Context, fpath string) error `perm:"admin"`
 
-		CheckProvable func(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storage.SectorRef, expensive bool) (map[abi.SectorNumber]string, error) `perm:"admin"`
+		CheckProvable     func(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storage.SectorRef, expensive bool) (map[abi.SectorNumber]string, error) `perm:"admin"`
+		ComputeWindowPoSt func(ctx context.Context, dlIdx uint64, tsk types.TipSetKey) (*api.WindowPoStDryRun, error)                                             `perm:"admin"`
 	}
 }
 
@@ -432,6 +448,8 @@ type WorkerStruct struct {
 		ReadPiece       func(context.Context, io.Writer, storage.SectorRef, storiface.UnpaddedByteIndex, abi.UnpaddedPieceSize) (storiface.CallID, error)                                                             `perm:"admin"`
 		Fetch           func(context.Context, storage.SectorRef, storiface.SectorFileType, storiface.PathType, storiface.AcquireMode) (storiface.CallID, error)                                                       `perm:"admin"`
 
+		GenerateWindowPoSt func(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (storiface.CallID, error) `perm:"admin"`
+
 		TaskDisable func(ctx context.Context, tt sealtasks.TaskType) error `perm:"admin"`
 		TaskEnable  func(ctx context.Context, tt sealtasks.TaskType) error `perm:"admin"`
 
@@ -477,6 +495,13 @@ type GatewayStruct struct {
 		StateSectorGetInfo        func(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorOnChainInfo, error)
 		// StateVerifiedClientStatus         func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*abi.StoragePower, error)
 		StateWaitMsg func(ctx context.Context, msg cid.Cid, confidence uint64) (*api.MsgLookup, error)
+
+		StateListExperts     func(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
+		StateExpertInfo      func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*expert.ExpertInfo, error)
+		StateVoteTally       func(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error)
+		StateVoterInfo       func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error)
+		StateKnowledgeInfo   func(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error)
+		StateRetrievalPledge func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalState, error)
 	}
 }
 
@@ -782,10 +807,22 @@ func (c *FullNodeStruct) MpoolBatchPushUntrusted(ctx context.Context, smsgs []*t
 	return c.Internal.MpoolBatchPushUntrusted(ctx, smsgs)
 }
 
-func (c *FullNodeStruct) MpoolBatchPushMessage(ctx context.Context, msgs []*types.Message, spec *api.MessageSendSpec) ([]*types.SignedMessage, error) {
+func (c *FullNodeStruct) MpoolBatchPushMessage(ctx context.Context, msgs []*types.Message, spec *api.MessageSendSpec) ([]api.MessagePushResult, error) {
 	return c.Internal.MpoolBatchPushMessage(ctx, msgs, spec)
 }
 
+func (c *FullNodeStruct) MpoolBumpStuck(ctx context.Context, from address.Address, maxFee types.BigInt) ([]cid.Cid, error) {
+	return c.Internal.MpoolBumpStuck(ctx, from, maxFee)
+}
+
+func (c *FullNodeStruct) MpoolCheckGaps(ctx context.Context) ([]api.NonceGap, error) {
+	return c.Internal.MpoolCheckGaps(ctx)
+}
+
+func (c *FullNodeStruct) MpoolFillGap(ctx context.Context, gap api.NonceGap) ([]cid.Cid, error) {
+	return c.Internal.MpoolFillGap(ctx, gap)
+}
+
 func (c *FullNodeStruct) MpoolSub(ctx context.Context) (<-chan api.MpoolUpdate, error) {
 	return c.Internal.MpoolSub(ctx)
 }
@@ -938,6 +975,14 @@ func (c *FullNodeStruct) ChainExport(ctx context.Context, nroots abi.ChainEpoch,
 	return c.Internal.ChainExport(ctx, nroots, iom, tsk)
 }
 
+func (c *FullNodeStruct) ChainExportDelta(ctx context.Context, base types.TipSetKey, target types.TipSetKey) (<-chan []byte, error) {
+	return c.Internal.ChainExportDelta(ctx, base, target)
+}
+
+func (c *FullNodeStruct) ChainStateIndexBackfill(ctx context.Context, tsk types.TipSetKey, toHeight abi.ChainEpoch) (int, error) {
+	return c.Internal.ChainStateIndexBackfill(ctx, tsk, toHeight)
+}
+
 func (c *FullNodeStruct) BeaconGetEntry(ctx context.Context, epoch abi.ChainEpoch) (*types.BeaconEntry, error) {
 	return c.Internal.BeaconGetEntry(ctx, epoch)
 }
@@ -1146,6 +1191,10 @@ func (c *FullNodeStruct) StateListMessages(ctx context.Context, match *api.Messa
 	return c.Internal.StateListMessages(ctx, match, tsk, toht)
 }
 
+func (c *FullNodeStruct) StateSearchMessages(ctx context.Context, filter *api.MessageFilter, fromHeight, toHeight abi.ChainEpoch, limit int) ([]api.IndexedMessage, error) {
+	return c.Internal.StateSearchMessages(ctx, filter, fromHeight, toHeight, limit)
+}
+
 func (c *FullNodeStruct) StateDecodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params []byte, tsk types.TipSetKey) (interface{}, error) {
 	return c.Internal.StateDecodeParams(ctx, toAddr, method, params, tsk)
 }
@@ -1414,6 +1463,10 @@ func (c *StorageMinerStruct) SectorsStatus(ctx context.Context, sid abi.SectorNu
 }
 
 // List all staged sectors
+func (c *StorageMinerStruct) SectorsNotify(ctx context.Context) (<-chan api.SectorStateChange, error) {
+	return c.Internal.SectorsNotify(ctx)
+}
+
 func (c *StorageMinerStruct) SectorsList(ctx context.Context) ([]abi.SectorNumber, error) {
 	return c.Internal.SectorsList(ctx)
 }
@@ -1526,6 +1579,10 @@ func (c *StorageMinerStruct) ReturnFetch(ctx context.Context, callID storiface.C
 	return c.Internal.ReturnFetch(ctx, callID, err)
 }
 
+func (c *StorageMinerStruct) ReturnGenerateWindowPoSt(ctx context.Context, callID storiface.CallID, res storiface.WindowPoStResult, err *storiface.CallError) error {
+	return c.Internal.ReturnGenerateWindowPoSt(ctx, callID, res, err)
+}
+
 func (c *StorageMinerStruct) SealingSchedDiag(ctx context.Context, doSched bool) (interface{}, error) {
 	return c.Internal.SealingSchedDiag(ctx, doSched)
 }
@@ -1534,6 +1591,14 @@ func (c *StorageMinerStruct) SealingAbort(ctx context.Context, call storiface.Ca
 	return c.Internal.SealingAbort(ctx, call)
 }
 
+func (c *StorageMinerStruct) SealingSchedSetPriority(ctx context.Context, sector abi.SectorNumber, priority int) error {
+	return c.Internal.SealingSchedSetPriority(ctx, sector, priority)
+}
+
+func (c *StorageMinerStruct) SealingSchedQueue(ctx context.Context) ([]storiface.SchedQueueEntry, error) {
+	return c.Internal.SealingSchedQueue(ctx)
+}
+
 func (c *StorageMinerStruct) StorageAttach(ctx context.Context, si stores.StorageInfo, st fsutil.FsStat) error {
 	return c.Internal.StorageAttach(ctx, si, st)
 }
@@ -1562,6 +1627,18 @@ func (c *StorageMinerStruct) StorageStat(ctx context.Context, id stores.ID) (fsu
 	return c.Internal.StorageStat(ctx, id)
 }
 
+func (c *StorageMinerStruct) StorageMoveSector(ctx context.Context, sector abi.SectorNumber, from, to stores.ID) error {
+	return c.Internal.StorageMoveSector(ctx, sector, from, to)
+}
+
+func (c *StorageMinerStruct) StorageSetMeta(ctx context.Context, id stores.ID, upd stores.StorageMetaUpdate) error {
+	return c.Internal.StorageSetMeta(ctx, id, upd)
+}
+
+func (c *StorageMinerStruct) StorageScrubStatus(ctx context.Context) (storiface.ScrubStatus, error) {
+	return c.Internal.StorageScrubStatus(ctx)
+}
+
 func (c *StorageMinerStruct) StorageInfo(ctx context.Context, id stores.ID) (stores.StorageInfo, error) {
 	return c.Internal.StorageInfo(ctx, id)
 }
@@ -1710,6 +1787,10 @@ func (c *StorageMinerStruct) CheckProvable(ctx context.Context, pp abi.Registere
 	return c.Internal.CheckProvable(ctx, pp, sectors, expensive)
 }
 
+func (c *StorageMinerStruct) ComputeWindowPoSt(ctx context.Context, dlIdx uint64, tsk types.TipSetKey) (*api.WindowPoStDryRun, error) {
+	return c.Internal.ComputeWindowPoSt(ctx, dlIdx, tsk)
+}
+
 // WorkerStruct
 
 func (w *WorkerStruct) Version(ctx context.Context) (build.Version, error) {
@@ -1772,6 +1853,10 @@ func (w *WorkerStruct) Fetch(ctx context.Context, id storage.SectorRef, fileType
 	return w.Internal.Fetch(ctx, id, fileType, ptype, am)
 }
 
+func (w *WorkerStruct) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (storiface.CallID, error) {
+	return w.Internal.GenerateWindowPoSt(ctx, minerID, sectorInfo, randomness)
+}
+
 func (w *WorkerStruct) TaskDisable(ctx context.Context, tt sealtasks.TaskType) error {
 	return w.Internal.TaskDisable(ctx, tt)
 }
@@ -1916,6 +2001,30 @@ func (g GatewayStruct) StateWaitMsg(ctx context.Context, msg cid.Cid, confidence
 	return g.Internal.StateWaitMsg(ctx, msg, confidence)
 }
 
+func (g GatewayStruct) StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
+	return g.Internal.StateListExperts(ctx, tsk)
+}
+
+func (g GatewayStruct) StateExpertInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*expert.ExpertInfo, error) {
+	return g.Internal.StateExpertInfo(ctx, addr, tsk)
+}
+
+func (g GatewayStruct) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error) {
+	return g.Internal.StateVoteTally(ctx, tsk)
+}
+
+func (g GatewayStruct) StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error) {
+	return g.Internal.StateVoterInfo(ctx, addr, tsk)
+}
+
+func (g GatewayStruct) StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error) {
+	return g.Internal.StateKnowledgeInfo(ctx, tsk)
+}
+
+func (g GatewayStruct) StateRetrievalPledge(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalState, error) {
+	return g.Internal.StateRetrievalPledge(ctx, addr, tsk)
+}
+
 func (c *WalletStruct) WalletNew(ctx context.Context, typ types.KeyType) (address.Address, error) {
 	return c.Internal.WalletNew(ctx, typ)
 }
@@ -16,6 +16,7 @@ var (
 	MemPoolSizeLimitLoDefault = 20000
 	PruneCooldownDefault      = time.Minute
 	GasLimitOverestimation    = 1.25
+	PersistIntervalDefault    = 5 * time.Minute
 
 	ConfigKey = datastore.NewKey("/mpool/config")
 )
@@ -61,6 +62,17 @@ func validateConfg(cfg *types.MpoolConfig) error {
 	if cfg.GasLimitOverestimation < 1 {
 		return fmt.Errorf("'GasLimitOverestimation' cannot be less than 1")
 	}
+	if cfg.PersistInterval < 0 {
+		return fmt.Errorf("'PersistInterval' cannot be negative")
+	}
+	if cfg.MaxPendingPerSender < 0 {
+		return fmt.Errorf("'MaxPendingPerSender' cannot be negative")
+	}
+	for i, p := range cfg.MinPremium {
+		if p.MinPremium.Int == nil || p.MinPremium.Sign() < 0 {
+			return fmt.Errorf("'MinPremium' of policy %d must be a non-negative amount", i)
+		}
+	}
 	return nil
 }
 
@@ -88,5 +100,6 @@ func DefaultConfig() *types.MpoolConfig {
 		ReplaceByFeeRatio:      ReplaceByFeeRatioDefault,
 		PruneCooldown:          PruneCooldownDefault,
 		GasLimitOverestimation: GasLimitOverestimation,
+		PersistInterval:        PersistIntervalDefault,
 	}
 }
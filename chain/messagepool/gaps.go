@@ -0,0 +1,64 @@
+package messagepool
+
+import (
+	"sort"
+
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+// MaxReportedGap caps the number of missing nonces reported per sender.
+var MaxReportedGap = 1000
+
+// NonceGaps returns the nonces missing from the pending messages of the local
+// senders, between their state nonce and their highest pending nonce.
+// Messages above a gap can't be included until it's filled.
+func (mp *MessagePool) NonceGaps() ([]api.NonceGap, error) {
+	mp.curTsLk.Lock()
+	defer mp.curTsLk.Unlock()
+
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	var out []api.NonceGap
+	for a := range mp.localAddrs {
+		mset, ok := mp.pending[a]
+		if !ok || len(mset.msgs) == 0 {
+			continue
+		}
+
+		snonce, err := mp.getStateNonce(a, mp.curTs)
+		if err != nil {
+			return nil, xerrors.Errorf("getting state nonce of %s: %w", a, err)
+		}
+
+		var max uint64
+		for n := range mset.msgs {
+			if n > max {
+				max = n
+			}
+		}
+
+		var missing []uint64
+		for n := snonce; n < max && len(missing) < MaxReportedGap; n++ {
+			if _, ok := mset.msgs[n]; !ok {
+				missing = append(missing, n)
+			}
+		}
+
+		if len(missing) > 0 {
+			out = append(out, api.NonceGap{
+				From:       a,
+				StateNonce: snonce,
+				Missing:    missing,
+			})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].From.String() < out[j].From.String()
+	})
+
+	return out, nil
+}
@@ -76,10 +76,14 @@ var (
 	ErrRBFTooLowPremium       = errors.New("replace by fee has too low GasPremium")
 	ErrTooManyPendingMessages = errors.New("too many pending messages for actor")
 	ErrNonceGap               = errors.New("unfulfilled nonce gap")
+
+	ErrDeniedSender  = errors.New("sender is on the deny list")
+	ErrPremiumTooLow = errors.New("gas premium below the policy minimum")
 )
 
 const (
-	localMsgsDs = "/mpool/local"
+	localMsgsDs   = "/mpool/local"
+	pendingMsgsDs = "/mpool/pending"
 
 	localUpdates = "update"
 )
@@ -154,6 +158,9 @@ type MessagePool struct {
 
 	localMsgs datastore.Datastore
 
+	// pendingMsgs holds the snapshot of remote pending messages, see PersistPending
+	pendingMsgs datastore.Batching
+
 	netName dtypes.NetworkName
 
 	sigValCache *lru.TwoQueueCache
@@ -226,6 +233,9 @@ func (ms *msgSet) add(m *types.SignedMessage, mp *MessagePool, strict, untrusted
 		maxNonceGap = 0
 		maxActorPendingMessages = MaxUntrustedActorPendingMessages
 	}
+	if strict {
+		maxActorPendingMessages = mp.maxPendingFor(maxActorPendingMessages)
+	}
 
 	switch {
 	case m.Message.Nonce == nextNonce:
@@ -367,6 +377,7 @@ func New(api Provider, ds dtypes.MetadataDS, netName dtypes.NetworkName, j journ
 		sigValCache:   verifcache,
 		changes:       lps.New(50),
 		localMsgs:     namespace.Wrap(ds, datastore.NewKey(localMsgsDs)),
+		pendingMsgs:   namespace.Wrap(ds, datastore.NewKey(pendingMsgsDs)),
 		api:           api,
 		netName:       netName,
 		cfg:           cfg,
@@ -395,14 +406,19 @@ func New(api Provider, ds dtypes.MetadataDS, netName dtypes.NetworkName, j journ
 
 	go func() {
 		err := mp.loadLocal()
-
-		mp.lk.Unlock()
-		mp.curTsLk.Unlock()
-
 		if err != nil {
 			log.Errorf("loading local messages: %+v", err)
 		}
 
+		if cfg.PersistPending {
+			if err := mp.loadPending(); err != nil {
+				log.Errorf("loading persisted pending messages: %+v", err)
+			}
+		}
+
+		mp.lk.Unlock()
+		mp.curTsLk.Unlock()
+
 		log.Info("mpool ready")
 
 		mp.runLoop()
@@ -412,6 +428,12 @@ func New(api Provider, ds dtypes.MetadataDS, netName dtypes.NetworkName, j journ
 }
 
 func (mp *MessagePool) Close() error {
+	if mp.GetConfig().PersistPending {
+		if err := mp.savePending(); err != nil {
+			log.Errorf("persisting pending messages: %+v", err)
+		}
+	}
+
 	close(mp.closer)
 	return nil
 }
@@ -429,8 +451,24 @@ func (mp *MessagePool) Prune() {
 }
 
 func (mp *MessagePool) runLoop() {
+	// the configured interval is checked on every tick, so that config
+	// changes apply without a restart
+	persistTk := build.Clock.Ticker(persistCheckInterval)
+	defer persistTk.Stop()
+	lastPersist := build.Clock.Now()
+
 	for {
 		select {
+		case <-persistTk.C:
+			ival := mp.persistInterval()
+			if ival == 0 || build.Clock.Since(lastPersist) < ival {
+				continue
+			}
+			if err := mp.savePending(); err != nil {
+				log.Errorf("error while persisting pending messages: %s", err)
+			}
+			lastPersist = build.Clock.Now()
+
 		case <-mp.repubTk.C:
 			if err := mp.republishPendingMessages(); err != nil {
 				log.Errorf("error while republishing messages: %s", err)
@@ -494,6 +532,12 @@ func (mp *MessagePool) verifyMsgBeforeAdd(m *types.SignedMessage, curTs *types.T
 	// automatically.
 	publish := local
 
+	if !local {
+		if err := mp.checkPolicy(m, curTs); err != nil {
+			return false, err
+		}
+	}
+
 	var baseFee big.Int
 	if len(curTs.Blocks()) > 0 {
 		baseFee = curTs.Blocks()[0].ParentBaseFee
@@ -706,7 +750,10 @@ func (mp *MessagePool) addTs(m *types.SignedMessage, curTs *types.TipSet, local,
 	return publish, nil
 }
 
-func (mp *MessagePool) addLoaded(m *types.SignedMessage) error {
+// addLoaded adds a message loaded from the datastore, revalidating it against
+// the current tipset. Messages of remote senders are checked as strictly as
+// when they were first received.
+func (mp *MessagePool) addLoaded(m *types.SignedMessage, local bool) error {
 	err := mp.checkMessage(m)
 	if err != nil {
 		return err
@@ -727,7 +774,7 @@ func (mp *MessagePool) addLoaded(m *types.SignedMessage) error {
 		return xerrors.Errorf("minimum expected nonce is %d: %w", snonce, ErrNonceTooLow)
 	}
 
-	_, err = mp.verifyMsgBeforeAdd(m, curTs, true)
+	_, err = mp.verifyMsgBeforeAdd(m, curTs, local)
 	if err != nil {
 		return err
 	}
@@ -736,7 +783,7 @@ func (mp *MessagePool) addLoaded(m *types.SignedMessage) error {
 		return err
 	}
 
-	return mp.addLocked(m, false, false)
+	return mp.addLocked(m, !local, false)
 }
 
 func (mp *MessagePool) addSkipChecks(m *types.SignedMessage) error {
@@ -949,6 +996,18 @@ func (mp *MessagePool) allPending() ([]*types.SignedMessage, *types.TipSet) {
 	return out, mp.curTs
 }
 
+// LocalAddresses returns the senders of messages pushed through this node.
+func (mp *MessagePool) LocalAddresses() []address.Address {
+	mp.lk.Lock()
+	defer mp.lk.Unlock()
+
+	out := make([]address.Address, 0, len(mp.localAddrs))
+	for a := range mp.localAddrs {
+		out = append(out, a)
+	}
+	return out
+}
+
 func (mp *MessagePool) PendingFor(a address.Address) ([]*types.SignedMessage, *types.TipSet) {
 	mp.curTsLk.Lock()
 	defer mp.curTsLk.Unlock()
@@ -1305,7 +1364,7 @@ func (mp *MessagePool) loadLocal() error {
 			return xerrors.Errorf("unmarshaling local message: %w", err)
 		}
 
-		if err := mp.addLoaded(&sm); err != nil {
+		if err := mp.addLoaded(&sm, true); err != nil {
 			if xerrors.Is(err, ErrNonceTooLow) {
 				continue // todo: drop the message from local cache (if above certain confidence threshold)
 			}
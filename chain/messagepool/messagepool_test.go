@@ -13,6 +13,7 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
 
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
 
@@ -493,6 +494,244 @@ func TestLoadLocal(t *testing.T) {
 	}
 }
 
+func TestPersistPending(t *testing.T) {
+	tma := newTestMpoolAPI()
+	ds := datastore.NewMapDatastore()
+
+	mp, err := New(tma, ds, "mptest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := mp.GetConfig()
+	cfg.PersistPending = true
+	if err := mp.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// the actors
+	w1, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1, err := w1.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w2, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a2, err := w2.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tma.setBalance(a1, 1) // in EPK
+	tma.setBalance(a2, 1) // in EPK
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	msgs := make(map[cid.Cid]struct{})
+	for i := 0; i < 10; i++ {
+		m := makeTestMessage(w1, a1, a2, uint64(i), gasLimit, uint64(i+1))
+		mustAdd(t, mp, m)
+		if i >= 3 {
+			msgs[m.Cid()] = struct{}{}
+		}
+	}
+	err = mp.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first messages got included while the node was down
+	tma.setStateNonce(a1, 3)
+
+	mp, err = New(tma, ds, "mptest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pmsgs, _ := mp.Pending()
+	if len(msgs) != len(pmsgs) {
+		t.Fatalf("expected %d messages, but got %d", len(msgs), len(pmsgs))
+	}
+
+	for _, m := range pmsgs {
+		if _, ok := msgs[m.Cid()]; !ok {
+			t.Fatal("unknown message")
+		}
+	}
+}
+
+func TestPersistPendingManyRemote(t *testing.T) {
+	tma := newTestMpoolAPI()
+	ds := datastore.NewMapDatastore()
+
+	mp, err := New(tma, ds, "mptest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := mp.GetConfig()
+	cfg.PersistPending = true
+	if err := mp.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	w1, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1, err := w1.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w2, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a2, err := w2.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tma.setBalance(a1, 1) // in EPK
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	// more than an untrusted sender may have pending, with a nonce gap
+	count := 2 * MaxUntrustedActorPendingMessages
+	for i := 0; i < count; i++ {
+		nonce := uint64(i)
+		if i == count-1 {
+			nonce++
+		}
+		mustAdd(t, mp, makeTestMessage(w1, a1, a2, nonce, gasLimit, uint64(i+1)))
+	}
+	err = mp.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mp, err = New(tma, ds, "mptest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pmsgs, _ := mp.Pending()
+	if len(pmsgs) != count {
+		t.Fatalf("expected %d messages, but got %d", count, len(pmsgs))
+	}
+}
+
+func TestAdmissionPolicies(t *testing.T) {
+	mp, tma := makeTestMpool()
+
+	var addrs []address.Address
+	var wallets []*wallet.LocalWallet
+	for i := 0; i < 3; i++ {
+		w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		a, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tma.setBalance(a, 1) // in EPK
+		addrs = append(addrs, a)
+		wallets = append(wallets, w)
+	}
+
+	cfg := mp.GetConfig()
+	cfg.MaxPendingPerSender = 3
+	cfg.MinPremium = []types.MpoolPremiumPolicy{{
+		ActorCode:  builtin2.StorageMarketActorCodeID,
+		Methods:    []abi.MethodNum{2},
+		MinPremium: types.NewInt(5),
+	}}
+	cfg.DenyList = []address.Address{addrs[1]}
+	if err := mp.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	for i := 0; i < 3; i++ {
+		mustAdd(t, mp, makeTestMessage(wallets[0], addrs[0], addrs[2], uint64(i), gasLimit, 5))
+	}
+	err := mp.Add(makeTestMessage(wallets[0], addrs[0], addrs[2], 3, gasLimit, 5))
+	if !xerrors.Is(err, ErrTooManyPendingMessages) {
+		t.Fatalf("expected ErrTooManyPendingMessages, got %v", err)
+	}
+
+	err = mp.Add(makeTestMessage(wallets[2], addrs[2], addrs[0], 0, gasLimit, 4))
+	if !xerrors.Is(err, ErrPremiumTooLow) {
+		t.Fatalf("expected ErrPremiumTooLow, got %v", err)
+	}
+
+	denied := makeTestMessage(wallets[1], addrs[1], addrs[0], 0, gasLimit, 5)
+	err = mp.Add(denied)
+	if !xerrors.Is(err, ErrDeniedSender) {
+		t.Fatalf("expected ErrDeniedSender, got %v", err)
+	}
+
+	// local messages aren't subject to the policies
+	if _, err := mp.Push(denied); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNonceGaps(t *testing.T) {
+	mp, tma := makeTestMpool()
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a2, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tma.setBalance(a1, 1) // in EPK
+	tma.setStateNonce(a1, 1)
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	for _, nonce := range []uint64{1, 2, 4, 7} {
+		if _, err := mp.Push(makeTestMessage(w, a1, a2, nonce, gasLimit, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gaps, err := mp.NonceGaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gaps) != 1 {
+		t.Fatalf("expected 1 gap, got %d", len(gaps))
+	}
+	if gaps[0].From != a1 || gaps[0].StateNonce != 1 {
+		t.Fatalf("unexpected gap %+v", gaps[0])
+	}
+	if fmt.Sprint(gaps[0].Missing) != fmt.Sprint([]uint64{3, 5, 6}) {
+		t.Fatalf("expected missing nonces [3 5 6], got %v", gaps[0].Missing)
+	}
+}
+
 func TestClearAll(t *testing.T) {
 	tma := newTestMpoolAPI()
 	ds := datastore.NewMapDatastore()
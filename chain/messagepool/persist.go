@@ -0,0 +1,119 @@
+package messagepool
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// persistCheckInterval is the granularity of PersistInterval.
+var persistCheckInterval = time.Minute
+
+// persistInterval returns the interval between snapshots of the pending
+// messages, or 0 if persistence is disabled.
+func (mp *MessagePool) persistInterval() time.Duration {
+	cfg := mp.GetConfig()
+	if !cfg.PersistPending {
+		return 0
+	}
+	if cfg.PersistInterval <= 0 {
+		return PersistIntervalDefault
+	}
+	return cfg.PersistInterval
+}
+
+// savePending snapshots the pending messages of remote senders to the
+// datastore, replacing the previous snapshot. Local messages are persisted
+// separately as they are added.
+func (mp *MessagePool) savePending() error {
+	mp.lk.Lock()
+	var msgs []*types.SignedMessage
+	for a := range mp.pending {
+		if _, local := mp.localAddrs[a]; local {
+			continue
+		}
+		msgs = append(msgs, mp.pendingFor(a)...)
+	}
+	mp.lk.Unlock()
+
+	res, err := mp.pendingMsgs.Query(query.Query{KeysOnly: true})
+	if err != nil {
+		return xerrors.Errorf("query persisted pending messages: %w", err)
+	}
+	old, err := res.Rest()
+	if err != nil {
+		return xerrors.Errorf("query persisted pending messages: %w", err)
+	}
+
+	batch, err := mp.pendingMsgs.Batch()
+	if err != nil {
+		return err
+	}
+	for _, r := range old {
+		if err := batch.Delete(datastore.NewKey(r.Key)); err != nil {
+			return err
+		}
+	}
+	for _, m := range msgs {
+		msgb, err := m.Serialize()
+		if err != nil {
+			return xerrors.Errorf("error serializing message: %w", err)
+		}
+		if err := batch.Put(datastore.NewKey(string(m.Cid().Bytes())), msgb); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(); err != nil {
+		return xerrors.Errorf("persisting pending messages: %w", err)
+	}
+
+	log.Infow("persisted pending messages", "count", len(msgs))
+	return nil
+}
+
+// loadPending re-adds the persisted pending messages, revalidating them
+// against the current tipset like remote messages. Must be called with
+// curTsLk and lk held.
+func (mp *MessagePool) loadPending() error {
+	res, err := mp.pendingMsgs.Query(query.Query{})
+	if err != nil {
+		return xerrors.Errorf("query persisted pending messages: %w", err)
+	}
+
+	var msgs []*types.SignedMessage
+	for r := range res.Next() {
+		if r.Error != nil {
+			return xerrors.Errorf("r.Error: %w", r.Error)
+		}
+
+		sm := new(types.SignedMessage)
+		if err := sm.UnmarshalCBOR(bytes.NewReader(r.Value)); err != nil {
+			return xerrors.Errorf("unmarshaling pending message: %w", err)
+		}
+		msgs = append(msgs, sm)
+	}
+
+	// remote messages are added strictly, so they must come in nonce order
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].Message.Nonce < msgs[j].Message.Nonce
+	})
+
+	var loaded, dropped int
+	for _, sm := range msgs {
+		if err := mp.addLoaded(sm, false); err != nil {
+			log.Debugf("dropping persisted pending message %s: %s", sm.Cid(), err)
+			dropped++
+			continue
+		}
+		loaded++
+	}
+
+	log.Infow("loaded persisted pending messages", "loaded", loaded, "dropped", dropped)
+	return nil
+}
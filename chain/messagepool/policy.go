@@ -0,0 +1,69 @@
+package messagepool
+
+import (
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// checkPolicy applies the admission policies of the config to a message of a
+// remote sender. The per sender pending limit is enforced when adding the
+// message to its msgSet.
+func (mp *MessagePool) checkPolicy(m *types.SignedMessage, curTs *types.TipSet) error {
+	// the config is replaced, never modified, on SetConfig
+	mp.cfgLk.Lock()
+	cfg := mp.cfg
+	mp.cfgLk.Unlock()
+
+	for _, a := range cfg.DenyList {
+		if a == m.Message.From {
+			return xerrors.Errorf("sender %s: %w", a, ErrDeniedSender)
+		}
+	}
+
+	if len(cfg.MinPremium) == 0 {
+		return nil
+	}
+
+	code := cid.Undef
+	for _, p := range cfg.MinPremium {
+		if !p.ActorCode.Defined() {
+			continue
+		}
+
+		// messages to actors which don't exist yet only match the policies
+		// for any actor
+		act, err := mp.api.GetActorAfter(m.Message.To, curTs)
+		if err == nil {
+			code = act.Code
+		}
+		break
+	}
+
+	for _, p := range cfg.MinPremium {
+		if !p.Matches(code, m.Message.Method) {
+			continue
+		}
+		if m.Message.GasPremium.LessThan(p.MinPremium) {
+			return xerrors.Errorf("GasPremium %s is below the minimum %s for method %d: %w",
+				m.Message.GasPremium, p.MinPremium, m.Message.Method, ErrPremiumTooLow)
+		}
+	}
+
+	return nil
+}
+
+// maxPendingFor returns the pending messages limit of a sender, given its
+// default limit.
+func (mp *MessagePool) maxPendingFor(def int) int {
+	// called with lk held, which SetConfig doesn't take
+	mp.cfgLk.Lock()
+	limit := mp.cfg.MaxPendingPerSender
+	mp.cfgLk.Unlock()
+
+	if limit > 0 && limit < def {
+		return limit
+	}
+	return def
+}
@@ -59,24 +59,12 @@ func (ms *MessageSigner) SignMessage(ctx context.Context, msg *types.Message, cb
 	// Sign the message with the nonce
 	msg.Nonce = nonce
 
-	mb, err := msg.ToStorageBlock()
-	if err != nil {
-		return nil, xerrors.Errorf("serializing message: %w", err)
-	}
-
-	sig, err := ms.wallet.WalletSign(ctx, msg.From, mb.Cid().Bytes(), api.MsgMeta{
-		Type:  api.MTChainMsg,
-		Extra: mb.RawData(),
-	})
+	smsg, err := ms.sign(ctx, msg)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to sign message: %w", err)
+		return nil, err
 	}
 
 	// Callback with the signed message
-	smsg := &types.SignedMessage{
-		Message:   *msg,
-		Signature: *sig,
-	}
 	err = cb(smsg)
 	if err != nil {
 		return nil, err
@@ -90,6 +78,74 @@ func (ms *MessageSigner) SignMessage(ctx context.Context, msg *types.Message, cb
 	return smsg, nil
 }
 
+// SignMessages assigns contiguous nonces to messages from the same address
+// and signs them in order, calling cb with each signed message. It stops at
+// the first message which fails to sign or for which cb fails, keeping the
+// nonces of the messages before it, and returns the messages signed so far.
+func (ms *MessageSigner) SignMessages(ctx context.Context, msgs []*types.Message, cb func(*types.SignedMessage) error) ([]*types.SignedMessage, error) {
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+
+	from := msgs[0].From
+	for _, msg := range msgs {
+		if msg.From != from {
+			return nil, xerrors.Errorf("messages must all be sent from %s, got %s", from, msg.From)
+		}
+	}
+
+	ms.lk.Lock()
+	defer ms.lk.Unlock()
+
+	nonce, err := ms.nextNonce(from)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create nonce: %w", err)
+	}
+
+	out := make([]*types.SignedMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		msg.Nonce = nonce
+
+		smsg, err := ms.sign(ctx, msg)
+		if err != nil {
+			return out, err
+		}
+
+		if err := cb(smsg); err != nil {
+			return out, err
+		}
+
+		if err := ms.saveNonce(from, nonce); err != nil {
+			return out, xerrors.Errorf("failed to save nonce: %w", err)
+		}
+
+		out = append(out, smsg)
+		nonce++
+	}
+
+	return out, nil
+}
+
+func (ms *MessageSigner) sign(ctx context.Context, msg *types.Message) (*types.SignedMessage, error) {
+	mb, err := msg.ToStorageBlock()
+	if err != nil {
+		return nil, xerrors.Errorf("serializing message: %w", err)
+	}
+
+	sig, err := ms.wallet.WalletSign(ctx, msg.From, mb.Cid().Bytes(), api.MsgMeta{
+		Type:  api.MTChainMsg,
+		Extra: mb.RawData(),
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign message: %w", err)
+	}
+
+	return &types.SignedMessage{
+		Message:   *msg,
+		Signature: *sig,
+	}, nil
+}
+
 // nextNonce gets the next nonce for the given address.
 // If there is no nonce in the datastore, gets the nonce from the message pool.
 func (ms *MessageSigner) nextNonce(addr address.Address) (uint64, error) {
@@ -198,3 +198,50 @@ func TestMessageSignerSignMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageSignerSignMessages(t *testing.T) {
+	ctx := context.Background()
+
+	w, _ := wallet.NewWallet(wallet.NewMemKeyStore())
+	from, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	to, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+
+	mpool := newMockMpool()
+	mpool.setNonce(from, 3)
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	ms := NewMessageSigner(w, mpool, ds)
+
+	var msgs []*types.Message
+	for i := 0; i < 4; i++ {
+		msgs = append(msgs, &types.Message{To: to, From: from})
+	}
+
+	// the third message fails, the nonces of the first two are kept
+	var pushed int
+	smsgs, err := ms.SignMessages(ctx, msgs, func(*types.SignedMessage) error {
+		if pushed == 2 {
+			return xerrors.Errorf("err")
+		}
+		pushed++
+		return nil
+	})
+	require.Error(t, err)
+	require.Len(t, smsgs, 2)
+	require.Equal(t, uint64(3), smsgs[0].Message.Nonce)
+	require.Equal(t, uint64(4), smsgs[1].Message.Nonce)
+
+	smsgs, err = ms.SignMessages(ctx, msgs[2:], func(*types.SignedMessage) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, smsgs, 2)
+	require.Equal(t, uint64(5), smsgs[0].Message.Nonce)
+	require.Equal(t, uint64(6), smsgs[1].Message.Nonce)
+
+	_, err = ms.SignMessages(ctx, []*types.Message{{To: to, From: from}, {To: from, From: to}}, func(*types.SignedMessage) error {
+		return nil
+	})
+	require.Error(t, err)
+}
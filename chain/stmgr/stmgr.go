@@ -199,6 +199,23 @@ func (sm *StateManager) TipSetState(ctx context.Context, ts *types.TipSet) (st c
 	return st, rec, nil
 }
 
+// IndexedTipSetState returns the state of the tipset from the state index when
+// it is indexed, and computes it otherwise. The index is filled from the
+// headers of the child tipsets and isn't validated, so this is only meant for
+// state queries, never for validation.
+func (sm *StateManager) IndexedTipSetState(ctx context.Context, ts *types.TipSet) (cid.Cid, cid.Cid, error) {
+	if ts.Height() > 0 {
+		e, err := sm.cs.GetStateIndex(ts.Height())
+		if err != nil {
+			log.Warnf("looking up state index: %s", err)
+		} else if e != nil && e.TipSet == ts.Key() {
+			return e.StateRoot, e.Receipts, nil
+		}
+	}
+
+	return sm.TipSetState(ctx, ts)
+}
+
 func traceFunc(trace *[]*api.InvocResult) func(mcid cid.Cid, msg *types.Message, ret *vm.ApplyRet) error {
 	return func(mcid cid.Cid, msg *types.Message, ret *vm.ApplyRet) error {
 		ir := &api.InvocResult{
@@ -903,6 +920,44 @@ func (sm *StateManager) ValidateChain(ctx context.Context, ts *types.TipSet) err
 	return nil
 }
 
+// ValidateRecentChain recomputes the state of the count tipsets preceding ts,
+// and checks the results against the state and receipt roots recorded by
+// their children. Unlike ValidateChain it doesn't need the whole chain state,
+// so it can be used on snapshots.
+func (sm *StateManager) ValidateRecentChain(ctx context.Context, ts *types.TipSet, count int) error {
+	var children, parents []*types.TipSet
+	for cur := ts; len(children) < count && cur.Height() > 0; {
+		parent, err := sm.cs.LoadTipSet(cur.Parents())
+		if err != nil {
+			return xerrors.Errorf("loading parent of tipset at height %d: %w", cur.Height(), err)
+		}
+
+		children = append(children, cur)
+		parents = append(parents, parent)
+		cur = parent
+	}
+
+	for i := len(children) - 1; i >= 0; i-- {
+		child, parent := children[i], parents[i]
+		log.Infof("computing state (height: %d, ts=%s)", parent.Height(), parent.Cids())
+
+		// not TipSetState, the state cache and the state index may hold the
+		// very roots being checked
+		st, rec, err := sm.computeTipSetState(ctx, parent, nil)
+		if err != nil {
+			return xerrors.Errorf("computing state at height %d: %w", parent.Height(), err)
+		}
+		if st != child.ParentState() {
+			return xerrors.Errorf("state mismatch at height %d: computed %s, expected %s", parent.Height(), st, child.ParentState())
+		}
+		if rec != child.Blocks()[0].ParentMessageReceipts {
+			return xerrors.Errorf("receipts mismatch at height %d: computed %s, expected %s", parent.Height(), rec, child.Blocks()[0].ParentMessageReceipts)
+		}
+	}
+
+	return nil
+}
+
 func (sm *StateManager) SetVMConstructor(nvm func(context.Context, *vm.VMOpts) (*vm.VM, error)) {
 	sm.newVM = nvm
 }
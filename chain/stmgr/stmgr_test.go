@@ -0,0 +1,45 @@
+package stmgr_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/chain/gen"
+	. "github.com/EpiK-Protocol/go-epik/chain/stmgr"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestValidateRecentChain(t *testing.T) {
+	ctx := context.Background()
+
+	cg, err := gen.NewGenerator()
+	require.NoError(t, err)
+
+	var head *types.TipSet
+	for i := 0; i < 5; i++ {
+		mts, err := cg.NextTipSet()
+		require.NoError(t, err)
+		head = mts.TipSet.TipSet()
+	}
+
+	sm := NewStateManager(cg.ChainStore())
+	require.NoError(t, sm.ValidateRecentChain(ctx, head, 3))
+
+	// a header claiming a wrong parent state, indexed the way importing it as
+	// the head would
+	var blks []*types.BlockHeader
+	for _, b := range head.Blocks() {
+		nb := *b
+		nb.ParentStateRoot = cg.Genesis().ParentStateRoot
+		blks = append(blks, &nb)
+	}
+	tampered, err := types.NewTipSet(blks)
+	require.NoError(t, err)
+
+	_, err = cg.ChainStore().BackfillStateIndex(ctx, tampered, tampered.Height()-1)
+	require.NoError(t, err)
+
+	require.Error(t, sm.ValidateRecentChain(ctx, tampered, 1))
+}
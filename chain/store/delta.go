@@ -0,0 +1,267 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ipfs/go-cid"
+	levelds "github.com/ipfs/go-ds-leveldb"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	"github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// ExportDelta writes a CAR file with the chain data added between the base
+// and the target tipset: the block headers after base up to target, their
+// messages and receipts, and the objects of their parent states which aren't
+// part of the parent state of base. Base must be an ancestor of target.
+//
+// The root of the CAR is the target tipset, the delta can be applied on top
+// of a repo containing base with ImportDelta.
+func (cs *ChainStore) ExportDelta(ctx context.Context, base, target *types.TipSet, w io.Writer) error {
+	if base.Height() >= target.Height() {
+		return xerrors.Errorf("base tipset (height %d) must be below the target tipset (height %d)", base.Height(), target.Height())
+	}
+
+	anc, err := cs.GetTipsetByHeight(ctx, base.Height(), target, true)
+	if err != nil {
+		return xerrors.Errorf("loading ancestor of target at base height: %w", err)
+	}
+	if !anc.Equals(base) {
+		return xerrors.Errorf("base tipset %s is not an ancestor of the target tipset", base.Key())
+	}
+
+	h := &car.CarHeader{
+		Roots:   target.Cids(),
+		Version: 1,
+	}
+	if err := car.WriteHeader(h, w); err != nil {
+		return xerrors.Errorf("failed to write car header: %s", err)
+	}
+
+	log.Infow("delta export started", "base", base.Height(), "target", target.Height())
+	exportStart := build.Clock.Now()
+
+	// everything reachable from the base state is known to the importer. The
+	// base state is kept on disk, it is as large as the whole state tree;
+	// the objects seen after it are only the delta.
+	known, err := newDiskCidSet()
+	if err != nil {
+		return xerrors.Errorf("creating base state set: %w", err)
+	}
+	defer known.close()
+
+	if err := cs.walkNew(ctx, known, base.ParentState(), nil); err != nil {
+		return xerrors.Errorf("walking base state: %w", err)
+	}
+	seen := &deltaCidSet{known: known, seen: cid.NewSet()}
+
+	write := func(c cid.Cid) error {
+		blk, err := cs.bs.Get(c)
+		if err != nil {
+			return xerrors.Errorf("writing object to car, bs.Get: %w", err)
+		}
+
+		if err := carutil.LdWrite(w, c.Bytes(), blk.RawData()); err != nil {
+			return xerrors.Errorf("failed to write block to car output: %w", err)
+		}
+		return nil
+	}
+
+	for ts := target; ts.Height() > base.Height(); {
+		for _, b := range ts.Blocks() {
+			isNew, err := seen.Visit(b.Cid())
+			if err != nil {
+				return err
+			}
+			if !isNew {
+				continue
+			}
+			if err := write(b.Cid()); err != nil {
+				return err
+			}
+
+			for _, root := range []cid.Cid{b.Messages, b.ParentMessageReceipts, b.ParentStateRoot} {
+				if err := cs.walkNew(ctx, seen, root, write); err != nil {
+					return xerrors.Errorf("exporting block %s at height %d: %w", b.Cid(), b.Height, err)
+				}
+			}
+		}
+
+		ts, err = cs.LoadTipSet(ts.Parents())
+		if err != nil {
+			return xerrors.Errorf("loading parent tipset: %w", err)
+		}
+	}
+
+	log.Infow("delta export finished", "duration", build.Clock.Now().Sub(exportStart).Seconds())
+
+	return nil
+}
+
+// cidVisitor is a set of objects visited by a walk.
+type cidVisitor interface {
+	// Visit adds the object to the set, and returns false if it already was
+	Visit(cid.Cid) (bool, error)
+}
+
+// diskCidSet is a cidVisitor kept in a temporary leveldb.
+type diskCidSet struct {
+	dir string
+	ds  *levelds.Datastore
+}
+
+func newDiskCidSet() (*diskCidSet, error) {
+	dir, err := ioutil.TempDir("", "epik-delta-")
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := levelds.NewDatastore(dir, &levelds.Options{
+		Compression: ldbopts.NoCompression,
+		NoSync:      true,
+	})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &diskCidSet{dir: dir, ds: ds}, nil
+}
+
+func (s *diskCidSet) has(c cid.Cid) (bool, error) {
+	return s.ds.Has(dshelp.MultihashToDsKey(c.Hash()))
+}
+
+func (s *diskCidSet) Visit(c cid.Cid) (bool, error) {
+	has, err := s.has(c)
+	if err != nil || has {
+		return false, err
+	}
+	return true, s.ds.Put(dshelp.MultihashToDsKey(c.Hash()), nil)
+}
+
+func (s *diskCidSet) close() {
+	if err := s.ds.Close(); err != nil {
+		log.Warnf("closing delta export set: %s", err)
+	}
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Warnf("removing delta export set: %s", err)
+	}
+}
+
+// deltaCidSet visits the objects which aren't in known.
+type deltaCidSet struct {
+	known *diskCidSet
+	seen  *cid.Set
+}
+
+func (s *deltaCidSet) Visit(c cid.Cid) (bool, error) {
+	has, err := s.known.has(c)
+	if err != nil || has {
+		return false, err
+	}
+	return s.seen.Visit(c), nil
+}
+
+// walkNew calls cb for root and every object reachable from it which isn't in
+// seen yet, and adds them to seen. Like WalkSnapshot, only dag-cbor objects are
+// reported. It must not be called on block headers, which link to their
+// parents.
+func (cs *ChainStore) walkNew(ctx context.Context, seen cidVisitor, root cid.Cid, cb func(cid.Cid) error) error {
+	if root.Prefix().Codec != cid.DagCBOR {
+		return nil
+	}
+	if isNew, err := seen.Visit(root); err != nil || !isNew {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if cb != nil {
+		if err := cb(root); err != nil {
+			return err
+		}
+	}
+
+	data, err := cs.bs.Get(root)
+	if err != nil {
+		return xerrors.Errorf("getting %s: %w", root, err)
+	}
+
+	var links []cid.Cid
+	if err := cbg.ScanForLinks(bytes.NewReader(data.RawData()), func(c cid.Cid) {
+		links = append(links, c)
+	}); err != nil {
+		return xerrors.Errorf("scanning for links of %s: %w", root, err)
+	}
+
+	for _, l := range links {
+		if err := cs.walkNew(ctx, seen, l, cb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportDelta applies a delta written by ExportDelta. The tipset the delta
+// was based on must be present in the store, along with its state. It returns
+// the base and the target tipset of the delta.
+func (cs *ChainStore) ImportDelta(ctx context.Context, r io.Reader, opts ImportOptions) (*types.TipSet, *types.TipSet, error) {
+	if opts.Resume {
+		// the base is found from the headers in the delta, which all need to
+		// be read
+		return nil, nil, xerrors.Errorf("resuming delta imports isn't supported")
+	}
+
+	headers := cid.NewSet()
+	opts.onHeader = func(bh *types.BlockHeader) {
+		headers.Add(bh.Cid())
+	}
+
+	target, err := cs.ImportStream(ctx, r, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := target
+	for {
+		var inDelta bool
+		for _, c := range base.Cids() {
+			inDelta = inDelta || headers.Has(c)
+		}
+		if !inDelta {
+			break
+		}
+
+		base, err = cs.LoadTipSet(base.Parents())
+		if err != nil {
+			return nil, nil, xerrors.Errorf("base of the delta not found, loading tipset: %w", err)
+		}
+	}
+
+	if base.Equals(target) {
+		return nil, nil, xerrors.Errorf("delta contains no block headers")
+	}
+
+	has, err := cs.bs.Has(base.ParentState())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !has {
+		return nil, nil, xerrors.Errorf("state of the delta base tipset at height %d not found", base.Height())
+	}
+
+	return base, target, nil
+}
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	dstore "github.com/ipfs/go-datastore"
+	"github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+var importCheckpointKey = dstore.NewKey("/chain/import")
+
+const (
+	importBatchBlocks = 4096
+	importBatchBytes  = 32 << 20
+)
+
+// ImportProgress reports how far an import got.
+type ImportProgress struct {
+	// Bytes is the offset in the CAR stream.
+	Bytes uint64
+	// Blocks is the number of blocks imported.
+	Blocks uint64
+	// Epoch is the height of the last block header imported. Snapshots are
+	// written from the head down, so it decreases towards genesis.
+	Epoch abi.ChainEpoch
+}
+
+// ImportOptions configures ImportStream.
+type ImportOptions struct {
+	// Progress, if set, is called every time a batch of blocks is written.
+	Progress func(ImportProgress)
+
+	// Resume continues an interrupted import of the same CAR file, skipping
+	// the blocks that were already written. When the reader is an io.Seeker,
+	// it's seeked past them, otherwise they are read and discarded.
+	Resume bool
+
+	// Genesis, if defined, must be the CID of the genesis block of the
+	// imported chain.
+	Genesis cid.Cid
+
+	// onHeader is called for every block header imported.
+	onHeader func(*types.BlockHeader)
+}
+
+type importCheckpoint struct {
+	Roots  []cid.Cid
+	Offset uint64
+	Blocks uint64
+	Epoch  abi.ChainEpoch
+}
+
+// ImportStream reads a CAR stream into the blockstore and returns its root
+// tipset. The progress is checkpointed in the metadata datastore, so that an
+// interrupted import can be resumed.
+func (cs *ChainStore) ImportStream(ctx context.Context, r io.Reader, opts ImportOptions) (*types.TipSet, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+
+	header, offset, err := car.ReadHeader(br)
+	if err != nil {
+		return nil, xerrors.Errorf("reading car header: %w", err)
+	}
+	if len(header.Roots) == 0 {
+		return nil, xerrors.Errorf("empty car")
+	}
+	if header.Version != 1 {
+		return nil, xerrors.Errorf("invalid car version: %d", header.Version)
+	}
+
+	progress := ImportProgress{Bytes: offset}
+
+	if opts.Resume {
+		cp, err := cs.importCheckpoint()
+		if err != nil {
+			return nil, err
+		}
+		if cp != nil && types.NewTipSetKey(cp.Roots...) == types.NewTipSetKey(header.Roots...) && cp.Offset > offset {
+			log.Infow("resuming import", "offset", cp.Offset, "blocks", cp.Blocks)
+
+			if s, ok := r.(io.Seeker); ok {
+				if _, err := s.Seek(int64(cp.Offset), io.SeekStart); err != nil {
+					return nil, xerrors.Errorf("seeking to checkpoint: %w", err)
+				}
+				br.Reset(r)
+			} else if _, err := br.Discard(int(cp.Offset - offset)); err != nil {
+				return nil, xerrors.Errorf("skipping to checkpoint: %w", err)
+			}
+
+			progress = ImportProgress{Bytes: cp.Offset, Blocks: cp.Blocks, Epoch: cp.Epoch}
+		}
+	}
+
+	var batch []blocks.Block
+	var batchBytes int
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := cs.bs.PutMany(batch); err != nil {
+			return xerrors.Errorf("writing blocks: %w", err)
+		}
+		progress.Blocks += uint64(len(batch))
+		batch, batchBytes = batch[:0], 0
+
+		if err := cs.putImportCheckpoint(&importCheckpoint{
+			Roots:  header.Roots,
+			Offset: progress.Bytes,
+			Blocks: progress.Blocks,
+			Epoch:  progress.Epoch,
+		}); err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		c, l, data, err := carutil.ReadNode(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// keep what was read so far, so that the import can be resumed
+			if ferr := flush(); ferr != nil {
+				log.Errorf("flushing blocks of interrupted import: %s", ferr)
+			}
+			return nil, xerrors.Errorf("reading block at offset %d: %w", progress.Bytes, err)
+		}
+
+		blk, err := blocks.NewBlockWithCid(data, c)
+		if err != nil {
+			return nil, err
+		}
+		if hashed, err := c.Prefix().Sum(data); err != nil || !hashed.Equals(c) {
+			return nil, xerrors.Errorf("block at offset %d doesn't match its cid %s", progress.Bytes, c)
+		}
+
+		// block headers are cbor arrays of 16 fields, checking the first byte
+		// avoids decoding all other objects
+		if c.Prefix().Codec == cid.DagCBOR && len(data) > 0 && data[0] == 0x90 {
+			if bh, err := types.DecodeBlock(data); err == nil {
+				progress.Epoch = bh.Height
+				if opts.onHeader != nil {
+					opts.onHeader(bh)
+				}
+			}
+		}
+
+		batch = append(batch, blk)
+		batchBytes += len(data)
+		progress.Bytes += l
+
+		if len(batch) >= importBatchBlocks || batchBytes >= importBatchBytes {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	root, err := cs.LoadTipSet(types.NewTipSetKey(header.Roots...))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load root tipset from chainfile: %w", err)
+	}
+
+	if opts.Genesis.Defined() {
+		gen, err := cs.GetTipsetByHeight(ctx, 0, root, true)
+		if err != nil {
+			return nil, xerrors.Errorf("loading genesis of imported chain: %w", err)
+		}
+		if gen.Cids()[0] != opts.Genesis {
+			return nil, xerrors.Errorf("imported chain has genesis %s, expected %s", gen.Cids()[0], opts.Genesis)
+		}
+	}
+
+	if err := cs.ds.Delete(importCheckpointKey); err != nil {
+		return nil, xerrors.Errorf("removing import checkpoint: %w", err)
+	}
+
+	return root, nil
+}
+
+func (cs *ChainStore) importCheckpoint() (*importCheckpoint, error) {
+	b, err := cs.ds.Get(importCheckpointKey)
+	if err == dstore.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("loading import checkpoint: %w", err)
+	}
+
+	var cp importCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, xerrors.Errorf("decoding import checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+func (cs *ChainStore) putImportCheckpoint(cp *importCheckpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := cs.ds.Put(importCheckpointKey, b); err != nil {
+		return xerrors.Errorf("saving import checkpoint: %w", err)
+	}
+	return nil
+}
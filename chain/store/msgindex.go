@@ -0,0 +1,399 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	dstore "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	blockadt "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+var (
+	msgIndexToPrefix         = dstore.NewKey("/to")
+	msgIndexFromPrefix       = dstore.NewKey("/from")
+	msgIndexToMethodPrefix   = dstore.NewKey("/tomethod")
+	msgIndexFromMethodPrefix = dstore.NewKey("/frommethod")
+
+	msgIndexBaseKey = dstore.NewKey("/base")
+	msgIndexHeadKey = dstore.NewKey("/head")
+)
+
+// MsgIndex indexes executed messages by recipient and by sender, and by
+// recipient or sender and method. It is kept in sync with the chain head,
+// reverted tipsets being removed from it.
+//
+// Index keys end with the inverted inclusion epoch, so that entries are
+// iterated newest first in key order.
+type MsgIndex struct {
+	cs *ChainStore
+	ds dstore.Batching
+
+	base abi.ChainEpoch
+}
+
+// NewMsgIndex creates a message index stored in ds and subscribes it to the
+// head changes of cs. Messages included before the base height of the index,
+// which is the height of the head when the index was created or when it
+// missed head changes, are not indexed.
+func NewMsgIndex(cs *ChainStore, ds dstore.Batching) (*MsgIndex, error) {
+	var height abi.ChainEpoch
+	if head := cs.GetHeaviestTipSet(); head != nil {
+		height = head.Height()
+	}
+
+	base, err := msgIndexGetEpoch(ds, msgIndexBaseKey)
+	if err != nil {
+		return nil, xerrors.Errorf("loading message index base: %w", err)
+	}
+	indexed, err := msgIndexGetEpoch(ds, msgIndexHeadKey)
+	if err != nil {
+		return nil, xerrors.Errorf("loading message index head: %w", err)
+	}
+
+	// the index is only contiguous from the base if it followed the chain up
+	// to the current head
+	if base < 0 || indexed != height {
+		base = height
+		if err := ds.Put(msgIndexBaseKey, msgIndexEpochBytes(base)); err != nil {
+			return nil, xerrors.Errorf("saving message index base: %w", err)
+		}
+		if err := ds.Put(msgIndexHeadKey, msgIndexEpochBytes(height)); err != nil {
+			return nil, xerrors.Errorf("saving message index head: %w", err)
+		}
+	}
+
+	mi := &MsgIndex{cs: cs, ds: ds, base: base}
+	cs.SubscribeHeadChanges(mi.headChange)
+	return mi, nil
+}
+
+// Base returns the lowest inclusion height of the indexed messages.
+func (mi *MsgIndex) Base() abi.ChainEpoch {
+	return mi.base
+}
+
+func msgIndexGetEpoch(ds dstore.Datastore, k dstore.Key) (abi.ChainEpoch, error) {
+	b, err := ds.Get(k)
+	switch err {
+	case nil:
+		e, _ := binary.Varint(b)
+		return abi.ChainEpoch(e), nil
+	case dstore.ErrNotFound:
+		return -1, nil
+	default:
+		return 0, err
+	}
+}
+
+func msgIndexEpochBytes(e abi.ChainEpoch) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(buf, int64(e))
+	return buf[:n]
+}
+
+// msgIndexEpoch encodes the epoch so that later epochs sort first
+func msgIndexEpoch(e abi.ChainEpoch) string {
+	return fmt.Sprintf("%016x", math.MaxUint64-uint64(e))
+}
+
+func msgIndexKeys(m *api.IndexedMessage) []dstore.Key {
+	suffix := msgIndexEpoch(m.Epoch) + "/" + m.Cid.String()
+	method := fmt.Sprint(uint64(m.Method))
+	return []dstore.Key{
+		msgIndexToPrefix.ChildString(m.To.String()).ChildString(suffix),
+		msgIndexFromPrefix.ChildString(m.From.String()).ChildString(suffix),
+		msgIndexToMethodPrefix.ChildString(m.To.String()).ChildString(method).ChildString(suffix),
+		msgIndexFromMethodPrefix.ChildString(m.From.String()).ChildString(method).ChildString(suffix),
+	}
+}
+
+func (mi *MsgIndex) headChange(rev, app []*types.TipSet) error {
+	drop := map[dstore.Key]struct{}{}
+	for _, ts := range rev {
+		msgs, err := mi.cs.ExecutedMessages(ts)
+		if err != nil {
+			log.Errorf("message index: loading reverted messages at %d: %+v", ts.Height(), err)
+			continue
+		}
+		for i := range msgs {
+			for _, k := range msgIndexKeys(&msgs[i]) {
+				drop[k] = struct{}{}
+			}
+		}
+	}
+
+	put := map[dstore.Key][]byte{}
+	for _, ts := range app {
+		msgs, err := mi.cs.ExecutedMessages(ts)
+		if err != nil {
+			log.Errorf("message index: loading applied messages at %d: %+v", ts.Height(), err)
+			continue
+		}
+		for i := range msgs {
+			b, err := json.Marshal(&msgs[i])
+			if err != nil {
+				return err
+			}
+			for _, k := range msgIndexKeys(&msgs[i]) {
+				put[k] = b
+				// a message reverted and applied again stays indexed
+				delete(drop, k)
+			}
+		}
+	}
+
+	if len(app) > 0 {
+		put[msgIndexHeadKey] = msgIndexEpochBytes(app[len(app)-1].Height())
+	}
+
+	if len(drop) == 0 && len(put) == 0 {
+		return nil
+	}
+
+	batch, err := mi.ds.Batch()
+	if err != nil {
+		return err
+	}
+	for k := range drop {
+		if err := batch.Delete(k); err != nil {
+			return err
+		}
+	}
+	for k, v := range put {
+		if err := batch.Put(k, v); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(); err != nil {
+		log.Errorf("message index: writing index: %+v", err)
+	}
+	return nil
+}
+
+// msgIndexCursor iterates over the entries under one index prefix
+type msgIndexCursor struct {
+	prefix string
+	res    query.Results
+	cur    query.Result
+	done   bool
+}
+
+func (c *msgIndexCursor) next() error {
+	r, ok := c.res.NextSync()
+	if !ok {
+		c.done = true
+		return nil
+	}
+	if r.Error != nil {
+		return xerrors.Errorf("querying message index: %w", r.Error)
+	}
+	c.cur = r
+	return nil
+}
+
+// suffix returns the part of the current key shared by all the prefixes,
+// which is ordered by epoch
+func (c *msgIndexCursor) suffix() string {
+	return c.cur.Key[len(c.prefix):]
+}
+
+// Search returns the indexed messages matching the filter included in
+// tipsets from fromHeight to toHeight, newest first. When the filter has
+// methods, the entries of each method are merged in epoch order.
+func (mi *MsgIndex) Search(ctx context.Context, filter *api.MessageFilter, fromHeight, toHeight abi.ChainEpoch, limit int) ([]api.IndexedMessage, error) {
+	var addr address.Address
+	var prefix, methodPrefix dstore.Key
+	switch {
+	case filter.To != address.Undef:
+		addr, prefix, methodPrefix = filter.To, msgIndexToPrefix, msgIndexToMethodPrefix
+	case filter.From != address.Undef:
+		addr, prefix, methodPrefix = filter.From, msgIndexFromPrefix, msgIndexFromMethodPrefix
+	default:
+		return nil, xerrors.Errorf("must specify at least To or From in message filter")
+	}
+
+	var prefixes []dstore.Key
+	if len(filter.Methods) == 0 {
+		prefixes = append(prefixes, prefix.ChildString(addr.String()))
+	} else {
+		seen := map[abi.MethodNum]struct{}{}
+		for _, m := range filter.Methods {
+			if _, ok := seen[m]; ok {
+				continue
+			}
+			seen[m] = struct{}{}
+			prefixes = append(prefixes, methodPrefix.ChildString(addr.String()).ChildString(fmt.Sprint(uint64(m))))
+		}
+	}
+
+	cursors := make([]*msgIndexCursor, 0, len(prefixes))
+	defer func() {
+		for _, c := range cursors {
+			_ = c.res.Close()
+		}
+	}()
+	for _, p := range prefixes {
+		res, err := mi.ds.Query(query.Query{
+			Prefix: p.String(),
+			Orders: []query.Order{query.OrderByKey{}},
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("querying message index: %w", err)
+		}
+		c := &msgIndexCursor{prefix: p.String(), res: res}
+		cursors = append(cursors, c)
+		if err := c.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	// epochs are inverted in keys
+	newest, oldest := msgIndexEpoch(toHeight), msgIndexEpoch(fromHeight)
+
+	var out []api.IndexedMessage
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var c *msgIndexCursor
+		for _, cc := range cursors {
+			if !cc.done && (c == nil || cc.suffix() < c.suffix()) {
+				c = cc
+			}
+		}
+		if c == nil {
+			break
+		}
+
+		suffix := c.suffix()
+		if suffix[1:17] > oldest {
+			break
+		}
+		if suffix[1:17] >= newest {
+			var m api.IndexedMessage
+			if err := json.Unmarshal(c.cur.Value, &m); err != nil {
+				return nil, xerrors.Errorf("decoding message index entry %s: %w", c.cur.Key, err)
+			}
+
+			// checks the other address, if set
+			if filter.Matches(&types.Message{To: m.To, From: m.From, Method: m.Method}) {
+				out = append(out, m)
+				if limit > 0 && len(out) >= limit {
+					break
+				}
+			}
+		}
+
+		if err := c.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// ExecutedMessages returns the messages of the parent of child, with the exit
+// codes recorded in child.
+func (cs *ChainStore) ExecutedMessages(child *types.TipSet) ([]api.IndexedMessage, error) {
+	if child.Height() == 0 {
+		return nil, nil
+	}
+
+	parent, err := cs.LoadTipSet(child.Parents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	msgs, err := cs.MessagesForTipset(parent)
+	if err != nil {
+		return nil, xerrors.Errorf("loading messages: %w", err)
+	}
+
+	rcpts, err := blockadt.AsArray(cs.Store(context.TODO()), child.Blocks()[0].ParentMessageReceipts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading receipts: %w", err)
+	}
+
+	out := make([]api.IndexedMessage, 0, len(msgs))
+	for i, cm := range msgs {
+		var r types.MessageReceipt
+		if found, err := rcpts.Get(uint64(i), &r); err != nil {
+			return nil, xerrors.Errorf("loading receipt %d: %w", i, err)
+		} else if !found {
+			return nil, xerrors.Errorf("missing receipt %d", i)
+		}
+
+		m := cm.VMMessage()
+		out = append(out, api.IndexedMessage{
+			Cid:      cm.Cid(),
+			To:       m.To,
+			From:     m.From,
+			Method:   m.Method,
+			Epoch:    parent.Height(),
+			ExitCode: r.ExitCode,
+		})
+	}
+
+	return out, nil
+}
+
+// ScanMessages walks the chain back from ts looking for the executed messages
+// matching the filter, like MsgIndex.Search does without an index.
+func (cs *ChainStore) ScanMessages(ctx context.Context, ts *types.TipSet, filter *api.MessageFilter, fromHeight, toHeight abi.ChainEpoch, limit int) ([]api.IndexedMessage, error) {
+	if filter.To == address.Undef && filter.From == address.Undef {
+		return nil, xerrors.Errorf("must specify at least To or From in message filter")
+	}
+
+	var out []api.IndexedMessage
+	for ts.Height() > 0 && ts.Height() > fromHeight {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		msgs, err := cs.ExecutedMessages(ts)
+		if err != nil {
+			return nil, xerrors.Errorf("loading messages executed at %d: %w", ts.Height(), err)
+		}
+
+		var matched []api.IndexedMessage
+		for _, m := range msgs {
+			if m.Epoch < fromHeight || m.Epoch > toHeight {
+				continue
+			}
+			if filter.Matches(&types.Message{To: m.To, From: m.From, Method: m.Method}) {
+				matched = append(matched, m)
+			}
+		}
+		// same order as the index
+		sort.Slice(matched, func(i, j int) bool {
+			return matched[i].Cid.String() < matched[j].Cid.String()
+		})
+
+		for _, m := range matched {
+			out = append(out, m)
+			if limit > 0 && len(out) >= limit {
+				return out, nil
+			}
+		}
+
+		ts, err = cs.LoadTipSet(ts.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent tipset: %w", err)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package store_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	datastore "github.com/ipfs/go-datastore"
+	syncds "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/gen"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestMsgIndex(t *testing.T) {
+	ctx := context.TODO()
+
+	cg, err := gen.NewGenerator()
+	require.NoError(t, err)
+
+	var tss []*types.TipSet
+	for i := 0; i < 10; i++ {
+		ts, err := cg.NextTipSet()
+		require.NoError(t, err)
+
+		tss = append(tss, ts.TipSet.TipSet())
+	}
+	head := tss[len(tss)-1]
+
+	cs := cg.ChainStore()
+	banker := cg.Banker()
+	filter := &api.MessageFilter{From: banker}
+
+	// the messages from the banker included in tss[from:to], in search order
+	expected := func(from, to int) []cid.Cid {
+		var out []cid.Cid
+		for i := to - 1; i >= from; i-- {
+			msgs, err := cs.MessagesForTipset(tss[i])
+			require.NoError(t, err)
+
+			var cids []cid.Cid
+			for _, m := range msgs {
+				if m.VMMessage().From == banker {
+					cids = append(cids, m.Cid())
+				}
+			}
+			sort.Slice(cids, func(i, j int) bool {
+				return cids[i].String() < cids[j].String()
+			})
+
+			out = append(out, cids...)
+		}
+		return out
+	}
+	cidsOf := func(msgs []api.IndexedMessage) []cid.Cid {
+		var out []cid.Cid
+		for _, m := range msgs {
+			out = append(out, m.Cid)
+		}
+		return out
+	}
+
+	// messages included in the head aren't executed yet
+	scanned, err := cs.ScanMessages(ctx, head, filter, 0, head.Height(), 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, scanned)
+	require.Equal(t, expected(0, 9), cidsOf(scanned))
+
+	scanned, err = cs.ScanMessages(ctx, head, filter, tss[3].Height(), tss[6].Height(), 5)
+	require.NoError(t, err)
+	require.Equal(t, expected(3, 7)[:5], cidsOf(scanned))
+
+	require.NoError(t, cs.SetHead(tss[2]))
+
+	mi, err := store.NewMsgIndex(cs, syncds.MutexWrap(datastore.NewMapDatastore()))
+	require.NoError(t, err)
+	require.Equal(t, tss[2].Height(), mi.Base())
+
+	search := func(from, to abi.ChainEpoch, limit int, methods ...abi.MethodNum) []cid.Cid {
+		msgs, err := mi.Search(ctx, &api.MessageFilter{From: banker, Methods: methods}, from, to, limit)
+		require.NoError(t, err)
+		return cidsOf(msgs)
+	}
+
+	require.NoError(t, cs.SetHead(head))
+	require.Eventually(t, func() bool {
+		return len(search(0, head.Height(), 0)) == len(expected(2, 9))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, expected(2, 9), search(0, head.Height(), 0))
+	require.Equal(t, expected(2, 9)[:3], search(0, head.Height(), 3))
+	require.Equal(t, expected(4, 6), search(tss[4].Height(), tss[5].Height(), 0))
+
+	// the test messages are all sends
+	require.Equal(t, expected(2, 9), search(0, head.Height(), 0, 0, 2))
+	require.Empty(t, search(0, head.Height(), 0, 2))
+
+	// same results as without the index
+	scanned, err = cs.ScanMessages(ctx, head, filter, mi.Base(), head.Height(), 0)
+	require.NoError(t, err)
+	require.Equal(t, search(0, head.Height(), 0), cidsOf(scanned))
+
+	// reverted messages are dropped
+	require.NoError(t, cs.SetHead(tss[5]))
+	require.Eventually(t, func() bool {
+		return len(search(0, head.Height(), 0)) == len(expected(2, 5))
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, expected(2, 5), search(0, head.Height(), 0))
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	dstore "github.com/ipfs/go-datastore"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+var stateIndexPrefix = dstore.NewKey("/chain/stateindex")
+
+// StateIndexEntry records the result of executing the tipset at an epoch, as
+// found in the headers of its child. Entries aren't recomputed, so they must
+// only be used to answer state queries, never to validate blocks.
+type StateIndexEntry struct {
+	TipSet    types.TipSetKey
+	StateRoot cid.Cid
+	Receipts  cid.Cid
+}
+
+func stateIndexKey(h abi.ChainEpoch) dstore.Key {
+	return stateIndexPrefix.ChildString(strconv.FormatInt(int64(h), 10))
+}
+
+// GetStateIndex returns the indexed state of the tipset at the given epoch, or
+// nil if it isn't indexed. The entry may belong to a tipset which has since
+// been reverted, callers must check its TipSet.
+func (cs *ChainStore) GetStateIndex(h abi.ChainEpoch) (*StateIndexEntry, error) {
+	b, err := cs.ds.Get(stateIndexKey(h))
+	if err == dstore.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("loading state index at %d: %w", h, err)
+	}
+
+	var e StateIndexEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, xerrors.Errorf("decoding state index at %d: %w", h, err)
+	}
+	return &e, nil
+}
+
+// indexStates is a ReorgNotifee keeping the state index in sync with the
+// head. Applying a tipset indexes the state of its parent.
+func (cs *ChainStore) indexStates(rev, app []*types.TipSet) error {
+	batch, err := cs.ds.Batch()
+	if err != nil {
+		return err
+	}
+
+	for _, ts := range rev {
+		e, err := cs.GetStateIndex(ts.Height())
+		if err != nil {
+			return err
+		}
+		if e != nil && e.TipSet == ts.Key() {
+			if err := batch.Delete(stateIndexKey(ts.Height())); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ts := range app {
+		if ts.Height() == 0 {
+			continue
+		}
+		if err := cs.putStateIndex(batch, ts); err != nil {
+			return err
+		}
+	}
+
+	return batch.Commit()
+}
+
+// putStateIndex indexes the state of the parent of child.
+func (cs *ChainStore) putStateIndex(batch dstore.Batch, child *types.TipSet) error {
+	parent, err := cs.LoadTipSet(child.Parents())
+	if err != nil {
+		return xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	b, err := json.Marshal(&StateIndexEntry{
+		TipSet:    parent.Key(),
+		StateRoot: child.ParentState(),
+		Receipts:  child.Blocks()[0].ParentMessageReceipts,
+	})
+	if err != nil {
+		return err
+	}
+
+	return batch.Put(stateIndexKey(parent.Height()), b)
+}
+
+// BackfillStateIndex indexes the states of the ancestors of ts down to the
+// given height, which is useful for chains synced or imported before the index
+// existed. It returns the number of tipsets indexed.
+func (cs *ChainStore) BackfillStateIndex(ctx context.Context, ts *types.TipSet, to abi.ChainEpoch) (int, error) {
+	batch, err := cs.ds.Batch()
+	if err != nil {
+		return 0, err
+	}
+
+	var n, pending int
+	for ts.Height() > 0 && ts.Height() > to {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		if err := cs.putStateIndex(batch, ts); err != nil {
+			return n, xerrors.Errorf("indexing state at %d: %w", ts.Height(), err)
+		}
+		n++
+		pending++
+
+		if pending >= 1024 {
+			if err := batch.Commit(); err != nil {
+				return n, err
+			}
+			if batch, err = cs.ds.Batch(); err != nil {
+				return n, err
+			}
+			pending = 0
+		}
+
+		ts, err = cs.LoadTipSet(ts.Parents())
+		if err != nil {
+			return n, xerrors.Errorf("loading parent tipset: %w", err)
+		}
+	}
+
+	return n, batch.Commit()
+}
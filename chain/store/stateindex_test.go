@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	datastore "github.com/ipfs/go-datastore"
+
+	"github.com/EpiK-Protocol/go-epik/chain/gen"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/lib/blockstore"
+)
+
+func TestBackfillStateIndex(t *testing.T) {
+	cg, err := gen.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tss []*types.TipSet
+	for i := 0; i < 20; i++ {
+		ts, err := cg.NextTipSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tss = append(tss, ts.TipSet.TipSet())
+	}
+	last := tss[len(tss)-1]
+
+	buf := new(bytes.Buffer)
+	if err := cg.ChainStore().Export(context.TODO(), last, 0, false, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	nbs := blockstore.NewTemporary()
+	cs := store.NewChainStore(nbs, nbs, datastore.NewMapDatastore(), nil, nil)
+	defer cs.Close() //nolint:errcheck
+
+	if _, err := cs.Import(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := cs.BackfillStateIndex(context.TODO(), last, tss[4].Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(tss)-5 {
+		t.Fatalf("expected %d indexed tipsets, got %d", len(tss)-5, n)
+	}
+
+	// the state of the head isn't known yet, nor is anything below the
+	// requested height
+	for _, h := range []int{3, len(tss) - 1} {
+		e, err := cs.GetStateIndex(tss[h].Height())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e != nil {
+			t.Fatalf("unexpected state index entry at %d", tss[h].Height())
+		}
+	}
+
+	for i := 4; i < len(tss)-1; i++ {
+		e, err := cs.GetStateIndex(tss[i].Height())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e == nil {
+			t.Fatalf("missing state index entry at %d", tss[i].Height())
+		}
+		if e.TipSet != tss[i].Key() {
+			t.Fatalf("wrong tipset indexed at %d", tss[i].Height())
+		}
+		if e.StateRoot != tss[i+1].ParentState() {
+			t.Fatalf("wrong state root indexed at %d", tss[i].Height())
+		}
+	}
+}
@@ -118,6 +118,131 @@ func TestChainExportImport(t *testing.T) {
 	}
 }
 
+func TestChainImportStreamResume(t *testing.T) {
+	cg, err := gen.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last *types.TipSet
+	for i := 0; i < 20; i++ {
+		ts, err := cg.NextTipSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		last = ts.TipSet.TipSet()
+	}
+
+	buf := new(bytes.Buffer)
+	if err := cg.ChainStore().Export(context.TODO(), last, 0, false, buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	nbs := blockstore.NewTemporary()
+	cs := store.NewChainStore(nbs, nbs, datastore.NewMapDatastore(), nil, nil)
+	defer cs.Close() //nolint:errcheck
+
+	// interrupted import
+	_, err = cs.ImportStream(context.TODO(), bytes.NewReader(data[:len(data)/2]), store.ImportOptions{})
+	if err == nil {
+		t.Fatal("expected truncated import to fail")
+	}
+
+	var progress []store.ImportProgress
+	root, err := cs.ImportStream(context.TODO(), bytes.NewReader(data), store.ImportOptions{
+		Progress: func(p store.ImportProgress) {
+			progress = append(progress, p)
+		},
+		Resume:  true,
+		Genesis: cg.Genesis().Cid(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !root.Equals(last) {
+		t.Fatal("imported chain differed from exported chain")
+	}
+	if len(progress) == 0 {
+		t.Fatal("expected progress to be reported")
+	}
+	if p := progress[len(progress)-1]; p.Bytes != uint64(len(data)) || p.Epoch != 0 {
+		t.Fatalf("unexpected final progress: %+v", p)
+	}
+
+	// a different genesis is rejected
+	_, err = cs.ImportStream(context.TODO(), bytes.NewReader(data), store.ImportOptions{
+		Genesis: last.Cids()[0],
+	})
+	if err == nil {
+		t.Fatal("expected genesis mismatch")
+	}
+}
+
+func TestChainExportImportDelta(t *testing.T) {
+	cg, err := gen.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tss []*types.TipSet
+	for i := 0; i < 40; i++ {
+		ts, err := cg.NextTipSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tss = append(tss, ts.TipSet.TipSet())
+	}
+	base, last := tss[19], tss[39]
+
+	buf := new(bytes.Buffer)
+	if err := cg.ChainStore().Export(context.TODO(), base, base.Height(), false, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	nbs := blockstore.NewTemporary()
+	cs := store.NewChainStore(nbs, nbs, datastore.NewMapDatastore(), nil, nil)
+	defer cs.Close() //nolint:errcheck
+
+	if _, err := cs.Import(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	delta := new(bytes.Buffer)
+	if err := cg.ChainStore().ExportDelta(context.TODO(), base, last, delta); err != nil {
+		t.Fatal(err)
+	}
+	if err := cg.ChainStore().ExportDelta(context.TODO(), last, base, new(bytes.Buffer)); err == nil {
+		t.Fatal("expected export with base above target to fail")
+	}
+
+	dbase, dtarget, err := cs.ImportDelta(context.TODO(), delta, store.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dbase.Equals(base) || !dtarget.Equals(last) {
+		t.Fatal("imported delta differed from exported delta")
+	}
+
+	if err := cs.SetHead(last); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := stmgr.NewStateManager(cs)
+	st, err := sm.ParentState(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// touches a bunch of actors
+	if _, err := sm.GetCirculatingSupply(context.TODO(), last.Height(), st); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestChainExportImportFull(t *testing.T) {
 	cg, err := gen.NewGenerator()
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
 )
 
 type MpoolConfig struct {
@@ -13,10 +15,59 @@ type MpoolConfig struct {
 	ReplaceByFeeRatio      float64
 	PruneCooldown          time.Duration
 	GasLimitOverestimation float64
+
+	// PersistPending enables snapshotting the pending messages of remote
+	// senders every PersistInterval and on shutdown, so that they survive
+	// restarts.
+	PersistPending  bool
+	PersistInterval time.Duration
+
+	// Admission policies, applied to messages of remote senders.
+
+	// MaxPendingPerSender caps the number of pending messages of a single
+	// sender, 0 keeps the default limit.
+	MaxPendingPerSender int
+	// MinPremium lists the minimum GasPremium required by message recipient.
+	MinPremium []MpoolPremiumPolicy
+	// DenyList holds the (key) addresses whose messages are rejected.
+	DenyList []address.Address
+}
+
+// MpoolPremiumPolicy requires a minimum GasPremium for messages sent to
+// actors of the given code, or to any actor if ActorCode is undefined. When
+// Methods is empty, the policy applies to all methods.
+type MpoolPremiumPolicy struct {
+	ActorCode  cid.Cid
+	Methods    []abi.MethodNum
+	MinPremium BigInt
+}
+
+// Matches returns whether the policy applies to a message calling method on
+// an actor with the given code.
+func (p *MpoolPremiumPolicy) Matches(code cid.Cid, method abi.MethodNum) bool {
+	if p.ActorCode.Defined() && p.ActorCode != code {
+		return false
+	}
+	if len(p.Methods) == 0 {
+		return true
+	}
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
 }
 
 func (mc *MpoolConfig) Clone() *MpoolConfig {
 	r := new(MpoolConfig)
 	*r = *mc
+	r.PriorityAddrs = append([]address.Address(nil), mc.PriorityAddrs...)
+	r.DenyList = append([]address.Address(nil), mc.DenyList...)
+	r.MinPremium = nil
+	for _, p := range mc.MinPremium {
+		p.Methods = append([]abi.MethodNum(nil), p.Methods...)
+		r.MinPremium = append(r.MinPremium, p)
+	}
 	return r
 }
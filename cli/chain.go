@@ -54,6 +54,7 @@ var chainCmd = &cli.Command{
 		chainGetCmd,
 		chainBisectCmd,
 		chainExportCmd,
+		chainBackfillStateIndexCmd,
 		slashConsensusFault,
 		chainGasPriceCmd,
 		chainInspectUsage,
@@ -1077,6 +1078,10 @@ var chainExportCmd = &cli.Command{
 		&cli.BoolFlag{
 			Name: "skip-old-msgs",
 		},
+		&cli.StringFlag{
+			Name:  "delta-base",
+			Usage: "only export the chain data added since this tipset (cids or @height), the delta can be imported with 'epik daemon --import-delta'",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		api, closer, err := GetFullNodeAPI(cctx)
@@ -1117,9 +1122,32 @@ var chainExportCmd = &cli.Command{
 			return fmt.Errorf("must pass recent stateroots along with skip-old-msgs")
 		}
 
-		stream, err := api.ChainExport(ctx, rsrs, skipold, ts.Key())
-		if err != nil {
-			return err
+		var stream <-chan []byte
+		if cctx.IsSet("delta-base") {
+			if rsrs != 0 || skipold {
+				return fmt.Errorf("recent-stateroots and skip-old-msgs can't be used with delta-base")
+			}
+
+			base, err := ParseTipSetRef(ctx, api, cctx.String("delta-base"))
+			if err != nil {
+				return xerrors.Errorf("parsing delta base: %w", err)
+			}
+			if ts == nil {
+				ts, err = api.ChainHead(ctx)
+				if err != nil {
+					return err
+				}
+			}
+
+			stream, err = api.ChainExportDelta(ctx, base.Key(), ts.Key())
+			if err != nil {
+				return err
+			}
+		} else {
+			stream, err = api.ChainExport(ctx, rsrs, skipold, ts.Key())
+			if err != nil {
+				return err
+			}
 		}
 
 		var last bool
@@ -1140,6 +1168,42 @@ var chainExportCmd = &cli.Command{
 	},
 }
 
+var chainBackfillStateIndexCmd = &cli.Command{
+	Name:  "backfill-state-index",
+	Usage: "index the state roots of past tipsets, so that state queries at them don't recompute the state",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "tipset",
+			Usage: "tipset to start backfilling from (cids or @height), defaults to the head",
+		},
+		&cli.Int64Flag{
+			Name:  "to-height",
+			Usage: "height to backfill down to",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		ts, err := LoadTipSet(ctx, cctx, api)
+		if err != nil {
+			return err
+		}
+
+		n, err := api.ChainStateIndexBackfill(ctx, ts.Key(), abi.ChainEpoch(cctx.Int64("to-height")))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("indexed the state of %d tipsets\n", n)
+		return nil
+	},
+}
+
 var slashConsensusFault = &cli.Command{
 	Name:      "slash-consensus",
 	Usage:     "Report consensus fault",
@@ -6,8 +6,10 @@ import (
 	stdbig "math/big"
 	"sort"
 	"strconv"
+	"strings"
 
 	cid "github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
@@ -17,6 +19,7 @@ import (
 
 	lapi "github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin"
 	"github.com/EpiK-Protocol/go-epik/chain/messagepool"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/node/config"
@@ -31,8 +34,11 @@ var mpoolCmd = &cli.Command{
 		mpoolSub,
 		mpoolStat,
 		mpoolReplaceCmd,
+		mpoolBumpCmd,
+		mpoolGapsCmd,
 		mpoolFindCmd,
 		mpoolConfig,
+		mpoolPolicyCmd,
 		mpoolGasPerfCmd,
 	},
 }
@@ -509,6 +515,98 @@ var mpoolReplaceCmd = &cli.Command{
 	},
 }
 
+var mpoolBumpCmd = &cli.Command{
+	Name:  "bump",
+	Usage: "replace all pending messages of a sender with re-estimated gas values",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "max-fee",
+			Usage: "Spend up to X attoEPK per message, the default max fee if not set",
+		},
+	},
+	ArgsUsage: "<from>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return cli.ShowCommandHelp(cctx, cctx.Command.Name)
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		from, err := address.NewFromString(cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		maxFee := big.Zero()
+		if cctx.IsSet("max-fee") {
+			maxFee, err = types.BigFromString(cctx.String("max-fee"))
+			if err != nil {
+				return fmt.Errorf("parsing max-fee: %w", err)
+			}
+		}
+
+		cids, err := api.MpoolBumpStuck(ctx, from, maxFee)
+		for _, c := range cids {
+			fmt.Println("new message cid: ", c)
+		}
+		return err
+	},
+}
+
+var mpoolGapsCmd = &cli.Command{
+	Name:  "gaps",
+	Usage: "list nonce gaps in the pending messages sent from this node",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "fill",
+			Usage: "fill the gaps with zero-value self-sends",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		gaps, err := api.MpoolCheckGaps(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(gaps) == 0 {
+			fmt.Println("No nonce gaps found")
+			return nil
+		}
+
+		for _, gap := range gaps {
+			fmt.Printf("%s: state nonce %d, missing %v\n", gap.From, gap.StateNonce, gap.Missing)
+
+			if !cctx.Bool("fill") {
+				continue
+			}
+
+			cids, err := api.MpoolFillGap(ctx, gap)
+			for i, c := range cids {
+				fmt.Printf("\tnonce %d filled by %s\n", gap.Missing[i], c)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
 var mpoolFindCmd = &cli.Command{
 	Name:  "find",
 	Usage: "find a message in the mempool",
@@ -719,3 +817,261 @@ var mpoolGasPerfCmd = &cli.Command{
 		return nil
 	},
 }
+
+var mpoolPolicyCmd = &cli.Command{
+	Name:  "policy",
+	Usage: "Manage admission policies for messages of remote senders",
+	Subcommands: []*cli.Command{
+		mpoolPolicyMaxPendingCmd,
+		mpoolPolicyMinPremiumCmd,
+		mpoolPolicyDenyCmd,
+		mpoolPolicyAllowCmd,
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		cfg, err := api.MpoolGetConfig(ReqContext(cctx))
+		if err != nil {
+			return err
+		}
+
+		if cfg.MaxPendingPerSender > 0 {
+			fmt.Printf("Max pending per sender: %d\n", cfg.MaxPendingPerSender)
+		} else {
+			fmt.Printf("Max pending per sender: default\n")
+		}
+
+		fmt.Printf("Min premium policies: %d\n", len(cfg.MinPremium))
+		for _, p := range cfg.MinPremium {
+			actor := "any"
+			if p.ActorCode.Defined() {
+				actor = builtin.ActorNameByCode(p.ActorCode)
+			}
+			methods := "any"
+			if len(p.Methods) > 0 {
+				methods = fmt.Sprint(p.Methods)
+			}
+			fmt.Printf("\tactor: %s, methods: %s, min premium: %s\n", actor, methods, p.MinPremium)
+		}
+
+		fmt.Printf("Denied senders: %d\n", len(cfg.DenyList))
+		for _, a := range cfg.DenyList {
+			fmt.Printf("\t%s\n", a)
+		}
+
+		return nil
+	},
+}
+
+// updateMpoolConfig applies cb to the current mpool config and saves it.
+func updateMpoolConfig(cctx *cli.Context, cb func(cfg *types.MpoolConfig) error) error {
+	api, closer, err := GetFullNodeAPI(cctx)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	ctx := ReqContext(cctx)
+
+	cfg, err := api.MpoolGetConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := cb(cfg); err != nil {
+		return err
+	}
+
+	return api.MpoolSetConfig(ctx, cfg)
+}
+
+var mpoolPolicyMaxPendingCmd = &cli.Command{
+	Name:      "max-pending",
+	Usage:     "Cap the number of pending messages of a single remote sender, 0 restores the default",
+	ArgsUsage: "<count>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return cli.ShowCommandHelp(cctx, cctx.Command.Name)
+		}
+
+		n, err := strconv.Atoi(cctx.Args().First())
+		if err != nil {
+			return xerrors.Errorf("parsing count: %w", err)
+		}
+
+		return updateMpoolConfig(cctx, func(cfg *types.MpoolConfig) error {
+			cfg.MaxPendingPerSender = n
+			return nil
+		})
+	},
+}
+
+var mpoolPolicyMinPremiumCmd = &cli.Command{
+	Name:      "min-premium",
+	Usage:     "Set the minimum GasPremium of messages to an actor type",
+	ArgsUsage: "<premium (attoEPK)>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "actor",
+			Usage: "actor code cid or builtin actor name (e.g. votefund), any actor if not set",
+		},
+		&cli.IntSliceFlag{
+			Name:  "method",
+			Usage: "method number the policy applies to, can be repeated; any method if not set",
+		},
+		&cli.BoolFlag{
+			Name:  "remove",
+			Usage: "remove the policy for the actor and methods instead",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		remove := cctx.Bool("remove")
+		if (remove && cctx.Args().Len() != 0) || (!remove && cctx.Args().Len() != 1) {
+			return cli.ShowCommandHelp(cctx, cctx.Command.Name)
+		}
+
+		code := cid.Undef
+		if cctx.IsSet("actor") {
+			var err error
+			code, err = parseActorCode(cctx.String("actor"))
+			if err != nil {
+				return err
+			}
+		}
+
+		var methods []abi.MethodNum
+		for _, m := range cctx.IntSlice("method") {
+			if m < 0 {
+				return xerrors.Errorf("invalid method number %d", m)
+			}
+			methods = append(methods, abi.MethodNum(m))
+		}
+		sort.Slice(methods, func(i, j int) bool {
+			return methods[i] < methods[j]
+		})
+
+		var premium types.BigInt
+		if !remove {
+			var err error
+			premium, err = types.BigFromString(cctx.Args().First())
+			if err != nil {
+				return xerrors.Errorf("parsing premium: %w", err)
+			}
+		}
+
+		return updateMpoolConfig(cctx, func(cfg *types.MpoolConfig) error {
+			// a policy for the same actor and methods gets replaced
+			var policies []types.MpoolPremiumPolicy
+			for _, p := range cfg.MinPremium {
+				if p.ActorCode == code && fmt.Sprint(p.Methods) == fmt.Sprint(methods) {
+					continue
+				}
+				policies = append(policies, p)
+			}
+			if !remove {
+				policies = append(policies, types.MpoolPremiumPolicy{
+					ActorCode:  code,
+					Methods:    methods,
+					MinPremium: premium,
+				})
+			} else if len(policies) == len(cfg.MinPremium) {
+				return xerrors.Errorf("no matching policy found")
+			}
+
+			cfg.MinPremium = policies
+			return nil
+		})
+	},
+}
+
+var mpoolPolicyDenyCmd = &cli.Command{
+	Name:      "deny",
+	Usage:     "Reject messages from the given senders",
+	ArgsUsage: "<address> ...",
+	Action: func(cctx *cli.Context) error {
+		addrs, err := parseAddrArgs(cctx)
+		if err != nil {
+			return err
+		}
+
+		return updateMpoolConfig(cctx, func(cfg *types.MpoolConfig) error {
+			for _, a := range addrs {
+				var has bool
+				for _, d := range cfg.DenyList {
+					has = has || d == a
+				}
+				if !has {
+					cfg.DenyList = append(cfg.DenyList, a)
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var mpoolPolicyAllowCmd = &cli.Command{
+	Name:      "allow",
+	Usage:     "Remove senders from the deny list",
+	ArgsUsage: "<address> ...",
+	Action: func(cctx *cli.Context) error {
+		addrs, err := parseAddrArgs(cctx)
+		if err != nil {
+			return err
+		}
+
+		return updateMpoolConfig(cctx, func(cfg *types.MpoolConfig) error {
+			var deny []address.Address
+		next:
+			for _, d := range cfg.DenyList {
+				for _, a := range addrs {
+					if d == a {
+						continue next
+					}
+				}
+				deny = append(deny, d)
+			}
+			cfg.DenyList = deny
+			return nil
+		})
+	},
+}
+
+func parseAddrArgs(cctx *cli.Context) ([]address.Address, error) {
+	if cctx.Args().Len() == 0 {
+		return nil, xerrors.Errorf("at least one address required")
+	}
+
+	var out []address.Address
+	for _, s := range cctx.Args().Slice() {
+		a, err := address.NewFromString(s)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing address %s: %w", s, err)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// parseActorCode accepts an actor code cid or the name of a builtin actor,
+// with or without the version prefix.
+func parseActorCode(s string) (cid.Cid, error) {
+	if c, err := cid.Decode(s); err == nil {
+		return c, nil
+	}
+
+	if !strings.Contains(s, "/") {
+		s = "epk/1/" + s
+	}
+	c, err := cid.V1Builder{Codec: cid.Raw, MhType: multihash.IDENTITY}.Sum([]byte(s))
+	if err != nil {
+		return cid.Undef, err
+	}
+	if !builtin.IsBuiltinActor(c) {
+		return cid.Undef, xerrors.Errorf("unknown actor %s", s)
+	}
+	return c, nil
+}
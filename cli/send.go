@@ -3,13 +3,18 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -66,8 +71,19 @@ var sendCmd = &cli.Command{
 			Name:  "force",
 			Usage: "must be specified for the action to take effect if maybe SysErrInsufficientFunds etc",
 		},
+		&cli.StringFlag{
+			Name:  "batch",
+			Usage: "send the messages listed in a file instead: a .json array of {To, Amount, From, Method, ParamsHex} objects or a csv of to,amount[,method,params-hex] lines",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
+		if cctx.IsSet("batch") {
+			if cctx.Args().Len() != 0 {
+				return ShowHelp(cctx, fmt.Errorf("'send --batch' expects no arguments"))
+			}
+			return sendBatch(cctx)
+		}
+
 		if cctx.Args().Len() != 2 {
 			return ShowHelp(cctx, fmt.Errorf("'send' expects two arguments, target and amount"))
 		}
@@ -208,4 +224,144 @@ func decodeTypedParams(ctx context.Context, fapi api.FullNode, to address.Addres
 		return nil, err
 	}
 	return buf.Bytes(), nil
-}
\ No newline at end of file
+}
+
+// batchSendEntry is a message to send in a `send --batch` file.
+type batchSendEntry struct {
+	To        string
+	Amount    string
+	From      string
+	Method    abi.MethodNum
+	ParamsHex string
+}
+
+func readBatchFile(fname string) ([]batchSendEntry, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	var entries []batchSendEntry
+	if strings.HasSuffix(strings.ToLower(fname), ".json") {
+		if err := json.NewDecoder(f).Decode(&entries); err != nil {
+			return nil, xerrors.Errorf("decoding json: %w", err)
+		}
+		return entries, nil
+	}
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, xerrors.Errorf("reading csv: %w", err)
+	}
+	for i, rec := range records {
+		if len(rec) != 2 && len(rec) != 4 {
+			return nil, xerrors.Errorf("line %d: expected 2 or 4 fields, got %d", i+1, len(rec))
+		}
+
+		e := batchSendEntry{To: rec[0], Amount: rec[1]}
+		if len(rec) == 4 {
+			m, err := strconv.ParseUint(rec[2], 10, 64)
+			if err != nil {
+				return nil, xerrors.Errorf("line %d: parsing method: %w", i+1, err)
+			}
+			e.Method = abi.MethodNum(m)
+			e.ParamsHex = rec[3]
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func sendBatch(cctx *cli.Context) error {
+	api, closer, err := GetFullNodeAPI(cctx)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	ctx := ReqContext(cctx)
+
+	entries, err := readBatchFile(cctx.String("batch"))
+	if err != nil {
+		return xerrors.Errorf("reading batch file: %w", err)
+	}
+
+	defFrom := cctx.String("from")
+	if defFrom == "" {
+		defaddr, err := api.WalletDefaultAddress(ctx)
+		if err != nil {
+			return err
+		}
+		defFrom = defaddr.String()
+	}
+
+	gp, err := types.BigFromString(cctx.String("gas-premium"))
+	if err != nil {
+		return err
+	}
+	gfc, err := types.BigFromString(cctx.String("gas-feecap"))
+	if err != nil {
+		return err
+	}
+
+	msgs := make([]*types.Message, 0, len(entries))
+	for i, e := range entries {
+		to, err := address.NewFromString(e.To)
+		if err != nil {
+			return xerrors.Errorf("entry %d: failed to parse target address: %w", i, err)
+		}
+
+		if e.From == "" {
+			e.From = defFrom
+		}
+		from, err := address.NewFromString(e.From)
+		if err != nil {
+			return xerrors.Errorf("entry %d: failed to parse from address: %w", i, err)
+		}
+
+		val, err := types.ParseEPK(e.Amount)
+		if err != nil {
+			return xerrors.Errorf("entry %d: failed to parse amount: %w", i, err)
+		}
+
+		params, err := hex.DecodeString(e.ParamsHex)
+		if err != nil {
+			return xerrors.Errorf("entry %d: failed to decode hex params: %w", i, err)
+		}
+
+		msgs = append(msgs, &types.Message{
+			From:       from,
+			To:         to,
+			Value:      types.BigInt(val),
+			GasPremium: gp,
+			GasFeeCap:  gfc,
+			GasLimit:   cctx.Int64("gas-limit"),
+			Method:     e.Method,
+			Params:     params,
+		})
+	}
+
+	results, err := api.MpoolBatchPushMessage(ctx, msgs, nil)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for i, res := range results {
+		if res.Message == nil {
+			fmt.Printf("%d: error: %s\n", i, res.Error)
+			failed++
+			continue
+		}
+		fmt.Printf("%d: %s (nonce %d)\n", i, res.Message.Cid(), res.Message.Message.Nonce)
+	}
+
+	if failed > 0 {
+		return xerrors.Errorf("%d of %d messages failed", failed, len(results))
+	}
+	return nil
+}
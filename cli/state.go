@@ -49,6 +49,10 @@ var stateCmd = &cli.Command{
 			Name:  "tipset",
 			Usage: "specify tipset to call method on (pass comma separated array of cids, or '@head' or '@{height}')",
 		},
+		&cli.Int64Flag{
+			Name:  "at-height",
+			Usage: "specify the height of the tipset to call method on, same as '--tipset @{height}'",
+		},
 	},
 	Subcommands: []*cli.Command{
 		statePowerCmd,
@@ -189,6 +193,16 @@ func ParseTipSetString(ts string) ([]cid.Cid, error) {
 
 func LoadTipSet(ctx context.Context, cctx *cli.Context, api api.FullNode) (*types.TipSet, error) {
 	tss := cctx.String("tipset")
+	if cctx.IsSet("at-height") {
+		if tss != "" {
+			return nil, xerrors.Errorf("can only specify one of 'tipset' and 'at-height'")
+		}
+		h := cctx.Int64("at-height")
+		if h < 0 {
+			return nil, xerrors.Errorf("invalid height %d", h)
+		}
+		return api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(h), types.EmptyTSK)
+	}
 	if tss == "" {
 		return nil, nil
 	}
@@ -453,7 +467,12 @@ var stateReplayCmd = &cli.Command{
 
 		ctx := ReqContext(cctx)
 
-		res, err := fapi.StateReplay(ctx, types.EmptyTSK, mcid)
+		ts, err := LoadTipSet(ctx, cctx, fapi)
+		if err != nil {
+			return err
+		}
+
+		res, err := fapi.StateReplay(ctx, ts.Key(), mcid)
 		if err != nil {
 			return xerrors.Errorf("replay call failed: %w", err)
 		}
@@ -506,7 +525,12 @@ var stateBlockRewardCmd = &cli.Command{
 			return err
 		}
 
-		r, err := api.StateBlockReward(ctx, bcid, types.EmptyTSK)
+		ts, err := LoadTipSet(ctx, cctx, api)
+		if err != nil {
+			return err
+		}
+
+		r, err := api.StateBlockReward(ctx, bcid, ts.Key())
 		if err != nil {
 			return err
 		}
@@ -595,7 +619,7 @@ var stateListMinersCmd = &cli.Command{
 
 		switch cctx.String("sort-by") {
 		case "num-deals":
-			ndm, err := getDealsCounts(ctx, api)
+			ndm, err := getDealsCounts(ctx, api, ts.Key())
 			if err != nil {
 				return err
 			}
@@ -621,8 +645,8 @@ var stateListMinersCmd = &cli.Command{
 	},
 }
 
-func getDealsCounts(ctx context.Context, lapi api.FullNode) (map[address.Address]int, error) {
-	allDeals, err := lapi.StateMarketDeals(ctx, types.EmptyTSK)
+func getDealsCounts(ctx context.Context, lapi api.FullNode, tsk types.TipSetKey) (map[address.Address]int, error) {
+	allDeals, err := lapi.StateMarketDeals(ctx, tsk)
 	if err != nil {
 		return nil, err
 	}
@@ -853,10 +877,18 @@ var stateListMessagesCmd = &cli.Command{
 			Name:  "from",
 			Usage: "return messages from a given address",
 		},
+		&cli.IntSliceFlag{
+			Name:  "method",
+			Usage: "only return messages calling the given methods",
+		},
 		&cli.Uint64Flag{
 			Name:  "toheight",
 			Usage: "don't look before given block height",
 		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "return at most this many messages, newest first",
+		},
 		&cli.BoolFlag{
 			Name:  "cids",
 			Usage: "print message CIDs instead of messages",
@@ -871,13 +903,13 @@ var stateListMessagesCmd = &cli.Command{
 
 		ctx := ReqContext(cctx)
 
-		var toa, froma address.Address
+		var filter lapi.MessageFilter
 		if tos := cctx.String("to"); tos != "" {
 			a, err := address.NewFromString(tos)
 			if err != nil {
 				return fmt.Errorf("given 'to' address %q was invalid: %w", tos, err)
 			}
-			toa = a
+			filter.To = a
 		}
 
 		if froms := cctx.String("from"); froms != "" {
@@ -885,7 +917,11 @@ var stateListMessagesCmd = &cli.Command{
 			if err != nil {
 				return fmt.Errorf("given 'from' address %q was invalid: %w", froms, err)
 			}
-			froma = a
+			filter.From = a
+		}
+
+		for _, m := range cctx.IntSlice("method") {
+			filter.Methods = append(filter.Methods, abi.MethodNum(m))
 		}
 
 		toh := abi.ChainEpoch(cctx.Uint64("toheight"))
@@ -895,59 +931,94 @@ var stateListMessagesCmd = &cli.Command{
 			return err
 		}
 
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
 		if ts == nil {
-			head, err := api.ChainHead(ctx)
-			if err != nil {
-				return err
-			}
 			ts = head
 		}
 
-		windowSize := abi.ChainEpoch(100)
-
-		cur := ts
-		for cur.Height() > toh {
-			if ctx.Err() != nil {
-				return ctx.Err()
+		limit := cctx.Int("limit")
+		var printed int
+		printMsg := func(c cid.Cid, m *types.Message) error {
+			printed++
+			if cctx.Bool("cids") {
+				fmt.Println(c.String())
+				return nil
 			}
 
-			end := toh
-			if cur.Height()-windowSize > end {
-				end = cur.Height() - windowSize
+			if m == nil {
+				var err error
+				if m, err = api.ChainGetMessage(ctx, c); err != nil {
+					return err
+				}
 			}
-
-			msgs, err := api.StateListMessages(ctx, &lapi.MessageMatch{To: toa, From: froma}, cur.Key(), end)
+			b, err := json.MarshalIndent(m, "", "  ")
 			if err != nil {
 				return err
 			}
+			fmt.Println(string(b))
+			return nil
+		}
 
-			for _, c := range msgs {
-				if cctx.Bool("cids") {
-					fmt.Println(c.String())
-					continue
-				}
+		// the messages in the head tipset aren't executed yet
+		if ts.Key() == head.Key() && ts.Height() >= toh {
+			cids, err := api.StateListMessages(ctx, &lapi.MessageMatch{To: filter.To, From: filter.From}, ts.Key(), ts.Height())
+			if err != nil {
+				return err
+			}
 
+			for _, c := range cids {
 				m, err := api.ChainGetMessage(ctx, c)
 				if err != nil {
 					return err
 				}
-				b, err := json.MarshalIndent(m, "", "  ")
-				if err != nil {
+				if !filter.Matches(m) {
+					continue
+				}
+
+				if err := printMsg(c, m); err != nil {
 					return err
 				}
-				fmt.Println(string(b))
+				if limit > 0 && printed >= limit {
+					return nil
+				}
 			}
+		}
 
-			if end <= 0 {
-				break
+		windowSize := abi.ChainEpoch(100)
+
+		for cur := ts.Height(); cur >= toh && cur > 0; {
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
 
-			next, err := api.ChainGetTipSetByHeight(ctx, end-1, cur.Key())
+			end := toh
+			if cur-windowSize+1 > end {
+				end = cur - windowSize + 1
+			}
+
+			var left int
+			if limit > 0 {
+				left = limit - printed
+			}
+
+			msgs, err := api.StateSearchMessages(ctx, &filter, end, cur, left)
 			if err != nil {
 				return err
 			}
 
-			cur = next
+			for _, im := range msgs {
+				if err := printMsg(im.Cid, nil); err != nil {
+					return err
+				}
+			}
+			if limit > 0 && printed >= limit {
+				break
+			}
+
+			cur = end - 1
 		}
 
 		return nil
@@ -1444,7 +1515,7 @@ var stateWaitMsgCmd = &cli.Command{
 		fmt.Printf("Exit Code: %d\n", mw.Receipt.ExitCode)
 		fmt.Printf("Gas Used: %d\n", mw.Receipt.GasUsed)
 		fmt.Printf("Return: %x\n", mw.Receipt.Return)
-		if err := printReceiptReturn(ctx, api, m, mw.Receipt); err != nil {
+		if err := printReceiptReturn(ctx, api, m, mw.Receipt, mw.TipSet); err != nil {
 			return err
 		}
 
@@ -1452,8 +1523,8 @@ var stateWaitMsgCmd = &cli.Command{
 	},
 }
 
-func printReceiptReturn(ctx context.Context, api api.FullNode, m *types.Message, r types.MessageReceipt) error {
-	act, err := api.StateGetActor(ctx, m.To, types.EmptyTSK)
+func printReceiptReturn(ctx context.Context, api api.FullNode, m *types.Message, r types.MessageReceipt, tsk types.TipSetKey) error {
+	act, err := api.StateGetActor(ctx, m.To, tsk)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ChallengeVerifier checks the response to a challenge (e.g. a captcha)
+// solved by the user in the site frontend before funds are sent.
+type ChallengeVerifier interface {
+	Verify(ctx context.Context, r *http.Request, remoteIP string) error
+}
+
+// noChallenge accepts every request.
+type noChallenge struct{}
+
+func (noChallenge) Verify(context.Context, *http.Request, string) error {
+	return nil
+}
+
+// siteVerifyChallenge verifies captcha responses against a 'siteverify'
+// endpoint, as implemented by hCaptcha and reCAPTCHA.
+type siteVerifyChallenge struct {
+	verifyURL string
+	secret    string
+	// field is the form field the frontend widget stores its response in,
+	// e.g. h-captcha-response or g-recaptcha-response
+	field string
+
+	client *http.Client
+}
+
+func newSiteVerifyChallenge(verifyURL, secret, field string) *siteVerifyChallenge {
+	return &siteVerifyChallenge{
+		verifyURL: verifyURL,
+		secret:    secret,
+		field:     field,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (c *siteVerifyChallenge) Verify(ctx context.Context, r *http.Request, remoteIP string) error {
+	response := r.FormValue(c.field)
+	if response == "" {
+		return xerrors.Errorf("missing challenge response")
+	}
+
+	form := url.Values{
+		"secret":   {c.secret},
+		"response": {response},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("verifying challenge: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	var vr siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&vr); err != nil {
+		return xerrors.Errorf("decoding challenge verification: %w", err)
+	}
+	if !vr.Success {
+		return xerrors.Errorf("challenge failed: %s", strings.Join(vr.ErrorCodes, ", "))
+	}
+
+	return nil
+}
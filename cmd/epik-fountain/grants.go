@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	dsq "github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+var (
+	grantsPrefix  = datastore.NewKey("/all")
+	addrPrefix    = datastore.NewKey("/addr")
+	ipPrefix      = datastore.NewKey("/ip")
+	budgetPrefix  = datastore.NewKey("/budget")
+	budgetDayForm = "2006-01-02"
+)
+
+// Grant is a single transfer made by the fountain.
+type Grant struct {
+	Time    time.Time
+	To      address.Address
+	IP      string
+	Amount  types.BigInt
+	Message cid.Cid
+}
+
+// GrantStore persists the grants made by the fountain, so that per-address
+// and per-IP history limits and the daily budget survive restarts.
+type GrantStore struct {
+	lk sync.Mutex
+	ds datastore.Batching
+
+	// time of the last reserved grant
+	last time.Time
+}
+
+func NewGrantStore(ds datastore.Batching) *GrantStore {
+	return &GrantStore{
+		ds: namespace.Wrap(ds, datastore.NewKey("/fountain/grants")),
+	}
+}
+
+func timeKey(t time.Time) string {
+	// zero padded so that lexicographic order is chronological
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+func budgetKey(t time.Time) datastore.Key {
+	return budgetPrefix.ChildString(t.UTC().Format(budgetDayForm))
+}
+
+// countSince returns the number of entries under prefix recorded at or after since.
+func (gs *GrantStore) countSince(prefix datastore.Key, since time.Time) (int, error) {
+	res, err := gs.ds.Query(dsq.Query{Prefix: prefix.String(), KeysOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	min := timeKey(since)
+	var n int
+	for r := range res.Next() {
+		if r.Error != nil {
+			return 0, r.Error
+		}
+		if datastore.RawKey(r.Key).BaseNamespace() >= min {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// AddressGrants returns the number of grants made to the address since the given time.
+func (gs *GrantStore) AddressGrants(to address.Address, since time.Time) (int, error) {
+	return gs.countSince(addrPrefix.ChildString(to.String()), since)
+}
+
+// IPGrants returns the number of grants requested from the IP since the given time.
+func (gs *GrantStore) IPGrants(ip string, since time.Time) (int, error) {
+	return gs.countSince(ipPrefix.ChildString(ipKey(ip)), since)
+}
+
+func ipKey(ip string) string {
+	// ipv6 addresses contain colons which aren't valid in key namespaces
+	return strings.ReplaceAll(ip, ":", "_")
+}
+
+// Spent returns the amount granted during the UTC day containing t.
+func (gs *GrantStore) Spent(t time.Time) (types.BigInt, error) {
+	gs.lk.Lock()
+	defer gs.lk.Unlock()
+
+	return gs.spent(t)
+}
+
+func (gs *GrantStore) spent(t time.Time) (types.BigInt, error) {
+	b, err := gs.ds.Get(budgetKey(t))
+	if err == datastore.ErrNotFound {
+		return types.NewInt(0), nil
+	}
+	if err != nil {
+		return types.BigInt{}, err
+	}
+
+	return types.BigFromBytes(b), nil
+}
+
+// ErrGrantLimit is returned by Reserve when a grant would exceed a limit.
+var ErrGrantLimit = xerrors.New("grant limit")
+
+// GrantLimits are the limits Reserve checks a grant against, zero values mean
+// no limit.
+type GrantLimits struct {
+	// Since is the start of the window over which grants are counted
+	Since         time.Time
+	MaxPerAddress int
+	MaxPerIP      int
+	DailyBudget   types.BigInt
+}
+
+// Reserve checks that the grant stays within the limits, and if so counts it
+// against them, so that concurrent requests can't all pass the checks before
+// any of them is recorded. The grant time is moved forward when needed to keep
+// the index keys of grants unique.
+func (gs *GrantStore) Reserve(g *Grant, lim GrantLimits) error {
+	gs.lk.Lock()
+	defer gs.lk.Unlock()
+
+	if !g.Time.After(gs.last) {
+		g.Time = gs.last.Add(time.Nanosecond)
+	}
+
+	if lim.MaxPerAddress > 0 {
+		n, err := gs.countSince(addrPrefix.ChildString(g.To.String()), lim.Since)
+		if err != nil {
+			return xerrors.Errorf("counting grants to %s: %w", g.To, err)
+		}
+		if n >= lim.MaxPerAddress {
+			return xerrors.Errorf("wallet history limit: %w", ErrGrantLimit)
+		}
+	}
+	if lim.MaxPerIP > 0 && g.IP != "" {
+		n, err := gs.countSince(ipPrefix.ChildString(ipKey(g.IP)), lim.Since)
+		if err != nil {
+			return xerrors.Errorf("counting grants from %s: %w", g.IP, err)
+		}
+		if n >= lim.MaxPerIP {
+			return xerrors.Errorf("IP history limit: %w", ErrGrantLimit)
+		}
+	}
+
+	spent, err := gs.spent(g.Time)
+	if err != nil {
+		return xerrors.Errorf("getting spent budget: %w", err)
+	}
+
+	next := types.BigAdd(spent, g.Amount)
+	if !lim.DailyBudget.Nil() && !lim.DailyBudget.IsZero() && next.GreaterThan(lim.DailyBudget) {
+		return xerrors.Errorf("daily budget of %s exhausted: %w", types.EPK(lim.DailyBudget), ErrGrantLimit)
+	}
+
+	b, err := next.Bytes()
+	if err != nil {
+		return err
+	}
+
+	batch, err := gs.ds.Batch()
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(budgetKey(g.Time), b); err != nil {
+		return err
+	}
+	if err := gs.putIndex(batch, g); err != nil {
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	gs.last = g.Time
+	return nil
+}
+
+// Release undoes the reservation of a grant which failed.
+func (gs *GrantStore) Release(g *Grant) error {
+	gs.lk.Lock()
+	defer gs.lk.Unlock()
+
+	spent, err := gs.spent(g.Time)
+	if err != nil {
+		return xerrors.Errorf("getting spent budget: %w", err)
+	}
+
+	next := types.BigSub(spent, g.Amount)
+	if next.LessThan(types.NewInt(0)) {
+		next = types.NewInt(0)
+	}
+
+	b, err := next.Bytes()
+	if err != nil {
+		return err
+	}
+
+	tk := timeKey(g.Time)
+
+	batch, err := gs.ds.Batch()
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(budgetKey(g.Time), b); err != nil {
+		return err
+	}
+	if err := batch.Delete(addrPrefix.ChildString(g.To.String()).ChildString(tk)); err != nil {
+		return err
+	}
+	if g.IP != "" {
+		if err := batch.Delete(ipPrefix.ChildString(ipKey(g.IP)).ChildString(tk)); err != nil {
+			return err
+		}
+	}
+
+	return batch.Commit()
+}
+
+func (gs *GrantStore) putIndex(batch datastore.Batch, g *Grant) error {
+	tk := timeKey(g.Time)
+
+	if err := batch.Put(addrPrefix.ChildString(g.To.String()).ChildString(tk), []byte{}); err != nil {
+		return err
+	}
+	if g.IP != "" {
+		if err := batch.Put(ipPrefix.ChildString(ipKey(g.IP)).ChildString(tk), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record persists a grant once its message was pushed.
+func (gs *GrantStore) Record(g *Grant) error {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	tk := timeKey(g.Time)
+
+	batch, err := gs.ds.Batch()
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(grantsPrefix.ChildString(tk+"-"+g.Message.String()), b); err != nil {
+		return err
+	}
+	// already there for reserved grants
+	if err := gs.putIndex(batch, g); err != nil {
+		return err
+	}
+
+	return batch.Commit()
+}
+
+// List returns grants made since the given time, optionally filtered by
+// recipient, oldest first.
+func (gs *GrantStore) List(since time.Time, to address.Address) ([]*Grant, error) {
+	res, err := gs.ds.Query(dsq.Query{Prefix: grantsPrefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	min := timeKey(since)
+	var out []*Grant
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		if datastore.RawKey(r.Key).BaseNamespace() < min {
+			continue
+		}
+
+		var g Grant
+		if err := json.Unmarshal(r.Value, &g); err != nil {
+			return nil, xerrors.Errorf("decoding grant %s: %w", r.Key, err)
+		}
+		if to != address.Undef && g.To != to {
+			continue
+		}
+		out = append(out, &g)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Time.Before(out[j].Time)
+	})
+
+	return out, nil
+}
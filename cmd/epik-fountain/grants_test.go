@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestGrantStore(t *testing.T) {
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	gs := NewGrantStore(ds)
+
+	to1, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	to2, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	now := time.Now()
+	grants := []*Grant{
+		{Time: now.Add(-48 * time.Hour), To: to1, IP: "10.0.0.1", Amount: types.NewInt(10), Message: makeCID("a")},
+		{Time: now.Add(-time.Hour), To: to1, IP: "10.0.0.1", Amount: types.NewInt(10), Message: makeCID("b")},
+		{Time: now.Add(-time.Minute), To: to2, IP: "::1", Amount: types.NewInt(10), Message: makeCID("c")},
+	}
+	for _, g := range grants {
+		require.NoError(t, gs.Record(g))
+	}
+
+	since := now.Add(-24 * time.Hour)
+
+	n, err := gs.AddressGrants(to1, since)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = gs.AddressGrants(to1, now.Add(-72*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = gs.IPGrants("::1", since)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	all, err := gs.List(since, address.Undef)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, makeCID("b"), all[0].Message)
+	assert.Equal(t, makeCID("c"), all[1].Message)
+
+	filtered, err := gs.List(time.Time{}, to1)
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+}
+
+func TestGrantStoreReserve(t *testing.T) {
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	gs := NewGrantStore(ds)
+
+	to1, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	to2, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	lim := GrantLimits{
+		Since:         now.Add(-time.Hour),
+		MaxPerAddress: 2,
+		MaxPerIP:      3,
+		DailyBudget:   types.NewInt(45),
+	}
+	grant := func(to address.Address, ip string) *Grant {
+		return &Grant{Time: now, To: to, IP: ip, Amount: types.NewInt(10)}
+	}
+
+	// reserved grants count against the limits before they are recorded
+	require.NoError(t, gs.Reserve(grant(to1, "10.0.0.1"), lim))
+	require.NoError(t, gs.Reserve(grant(to1, "10.0.0.1"), lim))
+	err = gs.Reserve(grant(to1, "10.0.0.2"), lim)
+	require.True(t, xerrors.Is(err, ErrGrantLimit), err)
+
+	require.NoError(t, gs.Reserve(grant(to2, "10.0.0.1"), lim))
+	err = gs.Reserve(grant(to2, "10.0.0.1"), lim)
+	require.True(t, xerrors.Is(err, ErrGrantLimit), err)
+
+	// releasing a failed grant frees its slots
+	failed := grant(to2, "10.0.0.2")
+	require.NoError(t, gs.Reserve(failed, lim))
+	require.NoError(t, gs.Release(failed))
+
+	n, err := gs.AddressGrants(to2, lim.Since)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	spent, err := gs.Spent(now)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewInt(30), spent)
+
+	// the budget is exhausted, for all addresses and IPs
+	require.NoError(t, gs.Reserve(grant(to2, "10.0.0.3"), lim))
+	err = gs.Reserve(grant(to2, "10.0.0.4"), lim)
+	require.True(t, xerrors.Is(err, ErrGrantLimit), err)
+
+	// the budget is per UTC day
+	next := grant(to2, "10.0.0.4")
+	next.Time = now.Add(24 * time.Hour)
+	require.NoError(t, gs.Reserve(next, GrantLimits{DailyBudget: types.NewInt(10)}))
+
+	// zero limits don't limit
+	require.NoError(t, gs.Reserve(grant(to1, "10.0.0.1"), GrantLimits{}))
+}
+
+func TestGrantStoreReserveConcurrent(t *testing.T) {
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	gs := NewGrantStore(ds)
+
+	to, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	now := time.Now()
+	lim := GrantLimits{Since: now.Add(-time.Hour), MaxPerAddress: 2}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- gs.Reserve(&Grant{Time: now, To: to, Amount: types.NewInt(1)}, lim)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var ok int
+	for err := range errs {
+		if err == nil {
+			ok++
+		}
+	}
+	assert.Equal(t, 2, ok)
+}
+
+func makeCID(s string) cid.Cid {
+	h, err := mh.Sum([]byte(s), mh.SHA2_256, -1)
+	if err != nil {
+		panic(err)
+	}
+	return cid.NewCidV1(cid.Raw, h)
+}
@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"text/tabwriter"
 	"time"
 
 	rice "github.com/GeertJohan/go.rice"
+	levelds "github.com/ipfs/go-ds-leveldb"
 	logging "github.com/ipfs/go-log/v2"
+	"github.com/mitchellh/go-homedir"
+	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
@@ -22,6 +27,8 @@ import (
 
 var log = logging.Logger("main")
 
+const FlagFountainDatastore = "fountain-datastore"
+
 func main() {
 	logging.SetLogLevel("*", "INFO")
 
@@ -29,6 +36,7 @@ func main() {
 
 	local := []*cli.Command{
 		runCmd,
+		listGrantsCmd,
 	}
 
 	app := &cli.App{
@@ -41,6 +49,12 @@ func main() {
 				EnvVars: []string{"EPIK_PATH"},
 				Value:   "~/.epik", // TODO: Consider XDG_DATA_HOME
 			},
+			&cli.StringFlag{
+				Name:    FlagFountainDatastore,
+				Usage:   "directory of the datastore the grants are recorded in",
+				EnvVars: []string{"FOUNTAIN_DATASTORE"},
+				Value:   "~/.epikfountain", // TODO: Consider XDG_DATA_HOME
+			},
 		},
 
 		Commands: local,
@@ -68,6 +82,54 @@ var runCmd = &cli.Command{
 			EnvVars: []string{"EPIK_FOUNTAIN_AMOUNT"},
 			Value:   "50",
 		},
+		&cli.StringFlag{
+			Name:  "daily-budget",
+			Usage: "maximum amount granted per UTC day, 0 for no limit",
+			Value: "0",
+		},
+		&cli.DurationFlag{
+			Name:  "history-window",
+			Usage: "window over which the persisted per-address and per-IP grants are counted",
+			Value: 24 * time.Hour,
+		},
+		&cli.IntFlag{
+			Name:  "max-grants-per-address",
+			Usage: "maximum number of grants to an address within the history window, 0 for no limit",
+			Value: 2,
+		},
+		&cli.IntFlag{
+			Name:  "max-grants-per-ip",
+			Usage: "maximum number of grants requested from an IP within the history window, 0 for no limit",
+			Value: 10,
+		},
+		&cli.StringFlag{
+			Name:  "captcha-verify-url",
+			Usage: "siteverify endpoint checking captcha responses, e.g. https://hcaptcha.com/siteverify",
+		},
+		&cli.StringFlag{
+			Name:    "captcha-secret",
+			EnvVars: []string{"EPIK_FOUNTAIN_CAPTCHA_SECRET"},
+			Usage:   "secret used to verify captcha responses",
+		},
+		&cli.StringFlag{
+			Name:  "captcha-field",
+			Usage: "form field holding the captcha response",
+			Value: "h-captcha-response",
+		},
+		&cli.StringFlag{
+			Name:  "captcha-site-key",
+			Usage: "site key of the captcha widget shown by the frontend",
+		},
+		&cli.StringFlag{
+			Name:  "captcha-script",
+			Usage: "script loading the captcha widget in the frontend",
+			Value: "https://hcaptcha.com/1/api.js",
+		},
+		&cli.StringFlag{
+			Name:  "captcha-class",
+			Usage: "css class of the captcha widget element",
+			Value: "h-captcha",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		sendPerRequest, err := types.ParseEPK(cctx.String("amount"))
@@ -94,6 +156,22 @@ var runCmd = &cli.Command{
 			return xerrors.Errorf("parsing source address (provide correct --from flag!): %w", err)
 		}
 
+		dailyBudget, err := types.ParseEPK(cctx.String("daily-budget"))
+		if err != nil {
+			return xerrors.Errorf("parsing daily budget: %w", err)
+		}
+
+		gs, closeGrants, err := openGrantStore(cctx)
+		if err != nil {
+			return err
+		}
+		defer closeGrants() //nolint:errcheck
+
+		var challenge ChallengeVerifier = noChallenge{}
+		if u := cctx.String("captcha-verify-url"); u != "" {
+			challenge = newSiteVerifyChallenge(u, cctx.String("captcha-secret"), cctx.String("captcha-field"))
+		}
+
 		h := &handler{
 			ctx:            ctx,
 			api:            nodeApi,
@@ -107,10 +185,25 @@ var runCmd = &cli.Command{
 				WalletRate:  15 * time.Minute,
 				WalletBurst: 2,
 			}),
+
+			grants:        gs,
+			dailyBudget:   types.BigInt(dailyBudget),
+			historyWindow: cctx.Duration("history-window"),
+			maxPerAddress: cctx.Int("max-grants-per-address"),
+			maxPerIP:      cctx.Int("max-grants-per-ip"),
+
+			challenge: challenge,
+			challengeInfo: challengeInfo{
+				Enabled: cctx.String("captcha-verify-url") != "",
+				SiteKey: cctx.String("captcha-site-key"),
+				Script:  cctx.String("captcha-script"),
+				Class:   cctx.String("captcha-class"),
+			},
 		}
 
 		http.Handle("/", http.FileServer(rice.MustFindBox("site").HTTPBox()))
 		http.HandleFunc("/send", h.send)
+		http.HandleFunc("/challenge", h.challengeConfig)
 
 		fmt.Printf("Open http://%s\n", cctx.String("front"))
 
@@ -131,6 +224,30 @@ type handler struct {
 	sendPerRequest types.EPK
 
 	limiter *Limiter
+
+	grants        *GrantStore
+	dailyBudget   types.BigInt
+	historyWindow time.Duration
+	maxPerAddress int
+	maxPerIP      int
+
+	challenge     ChallengeVerifier
+	challengeInfo challengeInfo
+}
+
+// challengeInfo tells the site frontend which challenge widget to render
+type challengeInfo struct {
+	Enabled bool
+	SiteKey string
+	Script  string
+	Class   string
+}
+
+func (h *handler) challengeConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.challengeInfo); err != nil {
+		log.Errorf("writing challenge config: %s", err)
+	}
 }
 
 func (h *handler) send(w http.ResponseWriter, r *http.Request) {
@@ -167,21 +284,132 @@ func (h *handler) send(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.challenge.Verify(r.Context(), r, reqIP); err != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden)+": "+err.Error(), http.StatusForbidden)
+		return
+	}
+
 	// General limiter to allow throttling all messages that can make it into the mpool
 	if !h.limiter.Allow() {
 		http.Error(w, http.StatusText(http.StatusTooManyRequests)+": global limit", http.StatusTooManyRequests)
 		return
 	}
 
+	// Limits persisted across restarts
+	now := time.Now()
+	g := &Grant{
+		Time:   now,
+		To:     to,
+		IP:     reqIP,
+		Amount: types.BigInt(h.sendPerRequest),
+	}
+	if err := h.grants.Reserve(g, GrantLimits{
+		Since:         now.Add(-h.historyWindow),
+		MaxPerAddress: h.maxPerAddress,
+		MaxPerIP:      h.maxPerIP,
+		DailyBudget:   h.dailyBudget,
+	}); err != nil {
+		if xerrors.Is(err, ErrGrantLimit) {
+			http.Error(w, http.StatusText(http.StatusTooManyRequests)+": "+err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		log.Errorf("reserving grant to %s: %s", to, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
 	smsg, err := h.api.MpoolPushMessage(h.ctx, &types.Message{
-		Value: types.BigInt(h.sendPerRequest),
+		Value: g.Amount,
 		From:  h.from,
 		To:    to,
 	}, nil)
 	if err != nil {
+		if err := h.grants.Release(g); err != nil {
+			log.Errorf("releasing grant: %s", err)
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	g.Message = smsg.Cid()
+	if err := h.grants.Record(g); err != nil {
+		log.Errorf("recording grant %s: %s", smsg.Cid(), err)
+	}
+
 	_, _ = w.Write([]byte(smsg.Cid().String()))
 }
+
+// openGrantStore opens the datastore the fountain keeps its grants in, leveldb
+// only lets one process open it
+func openGrantStore(cctx *cli.Context) (*GrantStore, func() error, error) {
+	path, err := homedir.Expand(cctx.String(FlagFountainDatastore))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ds, err := levelds.NewDatastore(path, &levelds.Options{
+		Compression: ldbopts.NoCompression,
+		Strict:      ldbopts.StrictAll,
+	})
+	if err != nil {
+		return nil, nil, xerrors.Errorf("opening fountain datastore %s: %w", path, err)
+	}
+
+	return NewGrantStore(ds), ds.Close, nil
+}
+
+var listGrantsCmd = &cli.Command{
+	Name:  "list-grants",
+	Usage: "List grants recorded in the fountain datastore (the fountain must not be running)",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "only list grants made within this duration",
+			Value: 24 * time.Hour,
+		},
+		&cli.StringFlag{
+			Name:  "address",
+			Usage: "only list grants to this address",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		to := address.Undef
+		if cctx.IsSet("address") {
+			a, err := address.NewFromString(cctx.String("address"))
+			if err != nil {
+				return xerrors.Errorf("parsing address: %w", err)
+			}
+			to = a
+		}
+
+		gs, closeGrants, err := openGrantStore(cctx)
+		if err != nil {
+			return err
+		}
+		defer closeGrants() //nolint:errcheck
+
+		now := time.Now()
+		grants, err := gs.List(now.Add(-cctx.Duration("since")), to)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "Time\tTo\tIP\tAmount\tMessage\n")
+		total := types.NewInt(0)
+		for _, g := range grants {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", g.Time.Format(time.RFC3339), g.To, g.IP, types.EPK(g.Amount), g.Message)
+			total = types.BigAdd(total, g.Amount)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		spent, err := gs.Spent(now)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n%d grants, total %s; granted today (UTC): %s\n", len(grants), types.EPK(total), types.EPK(spent))
+		return nil
+	},
+}
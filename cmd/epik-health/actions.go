@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"golang.org/x/xerrors"
+)
+
+// Action is triggered by the health daemon once checks fail for long enough.
+type Action interface {
+	Name() string
+	Trigger(ctx context.Context, r *Report) error
+}
+
+type systemdRestartAction struct {
+	unit string
+}
+
+func (a *systemdRestartAction) Name() string { return "systemd-restart" }
+
+func (a *systemdRestartAction) Trigger(ctx context.Context, r *Report) error {
+	c, err := dbus.New()
+	if err != nil {
+		return xerrors.Errorf("connecting to dbus: %w", err)
+	}
+	defer c.Close()
+
+	statusCh := make(chan string, 1)
+	if _, err := c.TryRestartUnit(a.unit, "fail", statusCh); err != nil {
+		return xerrors.Errorf("restarting %s: %w", a.unit, err)
+	}
+
+	select {
+	case result := <-statusCh:
+		if result != "done" {
+			return xerrors.Errorf("systemd unit %s failed to restart: %s", a.unit, result)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type webhookAction struct {
+	url    string
+	client *http.Client
+}
+
+func (a *webhookAction) Name() string { return "webhook" }
+
+func (a *webhookAction) Trigger(ctx context.Context, r *Report) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("calling webhook: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		return xerrors.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type exitAction struct {
+	code int
+	exit func(int)
+}
+
+func (a *exitAction) Name() string { return "exit" }
+
+func (a *exitAction) Trigger(ctx context.Context, r *Report) error {
+	log.Warnf("Exiting with code %d", a.code)
+	if a.exit == nil {
+		os.Exit(a.code)
+	}
+	a.exit(a.code)
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// healthAPI defines the node API methods the health checks depend on
+// (to make it easy to mock for tests)
+type healthAPI interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+	NetPeers(context.Context) ([]peer.AddrInfo, error)
+	MpoolPending(context.Context, types.TipSetKey) ([]*types.SignedMessage, error)
+	WalletBalance(context.Context, address.Address) (types.BigInt, error)
+	StateMinerFaults(context.Context, address.Address, types.TipSetKey) (bitfield.BitField, error)
+	StateRetrievalPledge(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error)
+}
+
+// Check is a single health check run periodically by the health daemon.
+type Check interface {
+	Name() string
+	// Liveness checks failing mark the node as unhealthy, all other checks
+	// only mark it as not ready.
+	Liveness() bool
+	// Run returns a short description of the observed state, and an error if
+	// the check failed.
+	Run(ctx context.Context) (string, error)
+}
+
+type headLagCheck struct {
+	api    healthAPI
+	maxLag time.Duration
+	now    func() time.Time
+}
+
+func (c *headLagCheck) Name() string   { return "head-lag" }
+func (c *headLagCheck) Liveness() bool { return true }
+
+func (c *headLagCheck) Run(ctx context.Context) (string, error) {
+	head, err := c.api.ChainHead(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	lag := c.now().Sub(time.Unix(int64(head.MinTimestamp()), 0)).Truncate(time.Second)
+	msg := fmt.Sprintf("head %d is %s behind", head.Height(), lag)
+	if lag > c.maxLag {
+		return msg, xerrors.Errorf("head lags %s behind wall clock, more than %s", lag, c.maxLag)
+	}
+	return msg, nil
+}
+
+type peerCountCheck struct {
+	api      healthAPI
+	minPeers int
+}
+
+func (c *peerCountCheck) Name() string   { return "peer-count" }
+func (c *peerCountCheck) Liveness() bool { return false }
+
+func (c *peerCountCheck) Run(ctx context.Context) (string, error) {
+	peers, err := c.api.NetPeers(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("listing peers: %w", err)
+	}
+
+	msg := fmt.Sprintf("%d peers", len(peers))
+	if len(peers) < c.minPeers {
+		return msg, xerrors.Errorf("connected to %d peers, expected at least %d", len(peers), c.minPeers)
+	}
+	return msg, nil
+}
+
+type mpoolSizeCheck struct {
+	api     healthAPI
+	maxSize int
+}
+
+func (c *mpoolSizeCheck) Name() string   { return "mpool-size" }
+func (c *mpoolSizeCheck) Liveness() bool { return false }
+
+func (c *mpoolSizeCheck) Run(ctx context.Context) (string, error) {
+	pending, err := c.api.MpoolPending(ctx, types.EmptyTSK)
+	if err != nil {
+		return "", xerrors.Errorf("listing pending messages: %w", err)
+	}
+
+	msg := fmt.Sprintf("%d pending messages", len(pending))
+	if len(pending) > c.maxSize {
+		return msg, xerrors.Errorf("%d pending messages, expected at most %d", len(pending), c.maxSize)
+	}
+	return msg, nil
+}
+
+type walletBalanceCheck struct {
+	api     healthAPI
+	addr    address.Address
+	minimum types.BigInt
+}
+
+func (c *walletBalanceCheck) Name() string   { return "wallet-balance:" + c.addr.String() }
+func (c *walletBalanceCheck) Liveness() bool { return false }
+
+func (c *walletBalanceCheck) Run(ctx context.Context) (string, error) {
+	bal, err := c.api.WalletBalance(ctx, c.addr)
+	if err != nil {
+		return "", xerrors.Errorf("getting balance of %s: %w", c.addr, err)
+	}
+
+	msg := fmt.Sprintf("balance %s", types.EPK(bal))
+	if bal.LessThan(c.minimum) {
+		return msg, xerrors.Errorf("balance %s below %s", types.EPK(bal), types.EPK(c.minimum))
+	}
+	return msg, nil
+}
+
+type minerFaultsCheck struct {
+	api       healthAPI
+	miner     address.Address
+	maxFaults uint64
+}
+
+func (c *minerFaultsCheck) Name() string   { return "miner-faults:" + c.miner.String() }
+func (c *minerFaultsCheck) Liveness() bool { return false }
+
+func (c *minerFaultsCheck) Run(ctx context.Context) (string, error) {
+	faults, err := c.api.StateMinerFaults(ctx, c.miner, types.EmptyTSK)
+	if err != nil {
+		return "", xerrors.Errorf("getting faults of %s: %w", c.miner, err)
+	}
+
+	n, err := faults.Count()
+	if err != nil {
+		return "", xerrors.Errorf("counting faults: %w", err)
+	}
+
+	msg := fmt.Sprintf("%d faulty sectors", n)
+	if n > c.maxFaults {
+		return msg, xerrors.Errorf("%d faulty sectors, expected at most %d", n, c.maxFaults)
+	}
+	return msg, nil
+}
+
+type retrievalPledgeCheck struct {
+	api     healthAPI
+	addr    address.Address
+	minimum types.BigInt
+}
+
+func (c *retrievalPledgeCheck) Name() string   { return "retrieval-pledge:" + c.addr.String() }
+func (c *retrievalPledgeCheck) Liveness() bool { return false }
+
+func (c *retrievalPledgeCheck) Run(ctx context.Context) (string, error) {
+	st, err := c.api.StateRetrievalPledge(ctx, c.addr, types.EmptyTSK)
+	if err != nil {
+		return "", xerrors.Errorf("getting retrieval pledge of %s: %w", c.addr, err)
+	}
+
+	avail := types.BigSub(st.Balance, st.Locked)
+	msg := fmt.Sprintf("available %s, expended today %s", types.EPK(avail), types.EPK(st.DayExpend))
+	if avail.LessThan(c.minimum) {
+		return msg, xerrors.Errorf("available retrieval pledge %s below %s", types.EPK(avail), types.EPK(c.minimum))
+	}
+	return msg, nil
+}
+
+// parseAddrAmount parses '<address>=<amount in EPK>' check arguments.
+func parseAddrAmount(s string) (address.Address, types.BigInt, error) {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 {
+		return address.Undef, types.BigInt{}, xerrors.Errorf("malformed %q, expected <address>=<amount>", s)
+	}
+
+	addr, err := address.NewFromString(kv[0])
+	if err != nil {
+		return address.Undef, types.BigInt{}, xerrors.Errorf("parsing address %q: %w", kv[0], err)
+	}
+
+	amt, err := types.ParseEPK(kv[1])
+	if err != nil {
+		return address.Undef, types.BigInt{}, xerrors.Errorf("parsing amount %q: %w", kv[1], err)
+	}
+
+	return addr, types.BigInt(amt), nil
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/build"
+	lcli "github.com/EpiK-Protocol/go-epik/cli"
+)
+
+type CheckStatus struct {
+	Name     string
+	Liveness bool
+	OK       bool
+	Message  string `json:",omitempty"`
+	Error    string `json:",omitempty"`
+	Took     time.Duration
+}
+
+type Report struct {
+	Healthy bool
+	Ready   bool
+	Time    time.Time
+	Checks  []CheckStatus
+}
+
+const (
+	triggerUnhealthy = "unhealthy"
+	triggerNotReady  = "not-ready"
+)
+
+type healthDaemon struct {
+	checks       []Check
+	actions      []Action
+	checkTimeout time.Duration
+
+	// threshold is the number of consecutive failed rounds before actions
+	// are triggered
+	threshold int
+	trigger   string
+
+	lk      sync.RWMutex
+	report  *Report
+	failing int
+}
+
+func (d *healthDaemon) runChecks(ctx context.Context) *Report {
+	r := &Report{
+		Healthy: true,
+		Ready:   true,
+		Time:    time.Now(),
+	}
+
+	for _, c := range d.checks {
+		cctx, cancel := context.WithTimeout(ctx, d.checkTimeout)
+		start := time.Now()
+		msg, err := c.Run(cctx)
+		cancel()
+
+		st := CheckStatus{
+			Name:     c.Name(),
+			Liveness: c.Liveness(),
+			OK:       err == nil,
+			Message:  msg,
+			Took:     time.Since(start),
+		}
+		if err != nil {
+			st.Error = err.Error()
+			log.Warnw("health check failed", "check", c.Name(), "error", err)
+
+			r.Ready = false
+			if c.Liveness() {
+				r.Healthy = false
+			}
+		}
+
+		r.Checks = append(r.Checks, st)
+	}
+
+	return r
+}
+
+// round runs all checks once, and triggers the actions if checks have been
+// failing for threshold consecutive rounds.
+func (d *healthDaemon) round(ctx context.Context) {
+	r := d.runChecks(ctx)
+
+	failed := !r.Healthy || (d.trigger == triggerNotReady && !r.Ready)
+
+	d.lk.Lock()
+	d.report = r
+	if failed {
+		d.failing++
+	} else {
+		d.failing = 0
+	}
+	fire := failed && d.failing >= d.threshold
+	if fire {
+		d.failing = 0
+	}
+	d.lk.Unlock()
+
+	if !fire {
+		return
+	}
+
+	for _, a := range d.actions {
+		log.Warnw("triggering action", "action", a.Name())
+		if err := a.Trigger(ctx, r); err != nil {
+			log.Errorw("action failed", "action", a.Name(), "error", err)
+		}
+	}
+}
+
+func (d *healthDaemon) run(ctx context.Context, interval time.Duration) {
+	d.round(ctx)
+
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			d.round(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *healthDaemon) handler(ready bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d.lk.RLock()
+		report := d.report
+		d.lk.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if report == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"Error": "checks didn't run yet"})
+			return
+		}
+
+		ok := report.Healthy
+		if ready {
+			ok = report.Ready
+		}
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Errorf("writing report: %s", err)
+		}
+	}
+}
+
+func (d *healthDaemon) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", d.handler(false))
+	mux.HandleFunc("/readyz", d.handler(true))
+	return mux
+}
+
+func buildChecks(cctx *cli.Context, a healthAPI) ([]Check, error) {
+	var checks []Check
+
+	if lag := cctx.Duration("max-head-lag"); lag > 0 {
+		checks = append(checks, &headLagCheck{api: a, maxLag: lag, now: time.Now})
+	}
+	if n := cctx.Int("min-peers"); n > 0 {
+		checks = append(checks, &peerCountCheck{api: a, minPeers: n})
+	}
+	if n := cctx.Int("max-mpool-size"); n > 0 {
+		checks = append(checks, &mpoolSizeCheck{api: a, maxSize: n})
+	}
+	for _, s := range cctx.StringSlice("min-wallet-balance") {
+		addr, amt, err := parseAddrAmount(s)
+		if err != nil {
+			return nil, xerrors.Errorf("min-wallet-balance: %w", err)
+		}
+		checks = append(checks, &walletBalanceCheck{api: a, addr: addr, minimum: amt})
+	}
+	for _, s := range cctx.StringSlice("miner") {
+		maddr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing miner address: %w", err)
+		}
+		checks = append(checks, &minerFaultsCheck{api: a, miner: maddr, maxFaults: cctx.Uint64("max-faults")})
+	}
+	for _, s := range cctx.StringSlice("min-retrieval-pledge") {
+		addr, amt, err := parseAddrAmount(s)
+		if err != nil {
+			return nil, xerrors.Errorf("min-retrieval-pledge: %w", err)
+		}
+		checks = append(checks, &retrievalPledgeCheck{api: a, addr: addr, minimum: amt})
+	}
+
+	return checks, nil
+}
+
+func buildActions(cctx *cli.Context) ([]Action, error) {
+	var actions []Action
+
+	for _, name := range cctx.StringSlice("action") {
+		switch name {
+		case "systemd-restart":
+			actions = append(actions, &systemdRestartAction{unit: cctx.String("systemd-unit")})
+		case "webhook":
+			if cctx.String("webhook-url") == "" {
+				return nil, xerrors.Errorf("webhook action requires --webhook-url")
+			}
+			actions = append(actions, &webhookAction{url: cctx.String("webhook-url"), client: &http.Client{Timeout: 30 * time.Second}})
+		case "exit":
+			actions = append(actions, &exitAction{code: cctx.Int("exit-code")})
+		default:
+			return nil, xerrors.Errorf("unknown action %q", name)
+		}
+	}
+
+	return actions, nil
+}
+
+var runCmd = &cli.Command{
+	Name:  "run",
+	Usage: "Run health checks periodically and serve their status over HTTP",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "listen",
+			Value: "127.0.0.1:2351",
+			Usage: "address serving the /healthz and /readyz endpoints",
+		},
+		&cli.DurationFlag{
+			Name:  "interval",
+			Value: time.Duration(build.BlockDelaySecs) * time.Second,
+			Usage: "interval between check rounds",
+		},
+		&cli.DurationFlag{
+			Name:  "check-timeout",
+			Value: 30 * time.Second,
+			Usage: "timeout of a single check",
+		},
+		&cli.DurationFlag{
+			Name:  "max-head-lag",
+			Value: 5 * time.Duration(build.BlockDelaySecs) * time.Second,
+			Usage: "maximum delay of the chain head behind wall clock, 0 disables the check",
+		},
+		&cli.IntFlag{
+			Name:  "min-peers",
+			Value: 1,
+			Usage: "minimum number of connected peers, 0 disables the check",
+		},
+		&cli.IntFlag{
+			Name:  "max-mpool-size",
+			Usage: "maximum number of pending messages, 0 disables the check",
+		},
+		&cli.StringSliceFlag{
+			Name:  "min-wallet-balance",
+			Usage: "minimum balance of a wallet as <address>=<amount>, may be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:  "miner",
+			Usage: "miner whose window PoSt faults are checked, may be repeated",
+		},
+		&cli.Uint64Flag{
+			Name:  "max-faults",
+			Usage: "maximum number of faulty sectors of checked miners",
+		},
+		&cli.StringSliceFlag{
+			Name:  "min-retrieval-pledge",
+			Usage: "minimum available retrieval pledge of an address as <address>=<amount>, may be repeated",
+		},
+		&cli.IntFlag{
+			Name:  "threshold",
+			Value: 3,
+			Usage: "number of consecutive failed rounds before triggering actions",
+		},
+		&cli.StringFlag{
+			Name:  "trigger",
+			Value: triggerUnhealthy,
+			Usage: "trigger actions when the node is 'unhealthy' (liveness checks fail) or 'not-ready' (any check fails)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "action",
+			Usage: "action to trigger: systemd-restart, webhook or exit, may be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "systemd-unit",
+			Value: "epik-daemon.service",
+			Usage: "systemd unit name restarted by the systemd-restart action",
+		},
+		&cli.StringFlag{
+			Name:  "webhook-url",
+			Usage: "url the report is POSTed to by the webhook action",
+		},
+		&cli.IntFlag{
+			Name:  "exit-code",
+			Value: 1,
+			Usage: "exit code used by the exit action",
+		},
+		&cli.IntFlag{
+			Name:  "api-timeout",
+			Value: int(build.BlockDelaySecs),
+			Usage: "timeout between API retries",
+		},
+		&cli.IntFlag{
+			Name:  "api-retries",
+			Value: 8,
+			Usage: "number of API retry attempts",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		trigger := cctx.String("trigger")
+		if trigger != triggerUnhealthy && trigger != triggerNotReady {
+			return xerrors.Errorf("unknown trigger %q", trigger)
+		}
+		if cctx.Int("threshold") < 1 {
+			return xerrors.Errorf("threshold must be at least 1, got %d", cctx.Int("threshold"))
+		}
+
+		api, closer, err := getFullNodeAPI(cctx, cctx.Int("api-retries"), time.Duration(cctx.Int("api-timeout"))*time.Second)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		checks, err := buildChecks(cctx, api)
+		if err != nil {
+			return err
+		}
+		actions, err := buildActions(cctx)
+		if err != nil {
+			return err
+		}
+
+		d := &healthDaemon{
+			checks:       checks,
+			actions:      actions,
+			checkTimeout: cctx.Duration("check-timeout"),
+			threshold:    cctx.Int("threshold"),
+			trigger:      trigger,
+		}
+
+		srv := &http.Server{Handler: d.mux()}
+		go func() {
+			<-ctx.Done()
+			if err := srv.Shutdown(context.TODO()); err != nil {
+				log.Errorf("shutting down health server failed: %s", err)
+			}
+		}()
+
+		nl, err := net.Listen("tcp", cctx.String("listen"))
+		if err != nil {
+			return err
+		}
+		log.Infof("Serving health status on %s", nl.Addr())
+
+		go d.run(ctx, cctx.Duration("interval"))
+
+		if err := srv.Serve(nl); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	},
+}
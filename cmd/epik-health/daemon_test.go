@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
+)
+
+type mockHealthAPI struct {
+	head     *types.TipSet
+	peers    int
+	pending  int
+	balance  types.BigInt
+	faults   []uint64
+	pledge   *api.RetrievalState
+	headErr  error
+	peersErr error
+}
+
+func (m *mockHealthAPI) ChainHead(context.Context) (*types.TipSet, error) {
+	return m.head, m.headErr
+}
+
+func (m *mockHealthAPI) NetPeers(context.Context) ([]peer.AddrInfo, error) {
+	return make([]peer.AddrInfo, m.peers), m.peersErr
+}
+
+func (m *mockHealthAPI) MpoolPending(context.Context, types.TipSetKey) ([]*types.SignedMessage, error) {
+	return make([]*types.SignedMessage, m.pending), nil
+}
+
+func (m *mockHealthAPI) WalletBalance(context.Context, address.Address) (types.BigInt, error) {
+	return m.balance, nil
+}
+
+func (m *mockHealthAPI) StateMinerFaults(context.Context, address.Address, types.TipSetKey) (bitfield.BitField, error) {
+	return bitfield.NewFromSet(m.faults), nil
+}
+
+func (m *mockHealthAPI) StateRetrievalPledge(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error) {
+	return m.pledge, nil
+}
+
+type countAction struct {
+	n int
+}
+
+func (a *countAction) Name() string { return "count" }
+
+func (a *countAction) Trigger(ctx context.Context, r *Report) error {
+	a.n++
+	return nil
+}
+
+func mkHead(t time.Time) *types.TipSet {
+	blk := mock.MkBlock(nil, 1, 1)
+	blk.Timestamp = uint64(t.Unix())
+	return mock.TipSet(blk)
+}
+
+func TestChecks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	addr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	m := &mockHealthAPI{
+		head:    mkHead(now.Add(-time.Minute)),
+		peers:   3,
+		pending: 10,
+		balance: types.NewInt(100),
+		faults:  []uint64{1, 5},
+		pledge: &api.RetrievalState{
+			Balance:   types.NewInt(100),
+			Locked:    types.NewInt(60),
+			DayExpend: types.NewInt(10),
+		},
+	}
+
+	type tc struct {
+		check Check
+		ok    bool
+	}
+	for _, c := range []tc{
+		{&headLagCheck{api: m, maxLag: 2 * time.Minute, now: time.Now}, true},
+		{&headLagCheck{api: m, maxLag: 30 * time.Second, now: time.Now}, false},
+		{&peerCountCheck{api: m, minPeers: 3}, true},
+		{&peerCountCheck{api: m, minPeers: 4}, false},
+		{&mpoolSizeCheck{api: m, maxSize: 10}, true},
+		{&mpoolSizeCheck{api: m, maxSize: 9}, false},
+		{&walletBalanceCheck{api: m, addr: addr, minimum: types.NewInt(100)}, true},
+		{&walletBalanceCheck{api: m, addr: addr, minimum: types.NewInt(101)}, false},
+		{&minerFaultsCheck{api: m, miner: addr, maxFaults: 2}, true},
+		{&minerFaultsCheck{api: m, miner: addr, maxFaults: 1}, false},
+		{&retrievalPledgeCheck{api: m, addr: addr, minimum: types.NewInt(40)}, true},
+		{&retrievalPledgeCheck{api: m, addr: addr, minimum: types.NewInt(41)}, false},
+	} {
+		_, err := c.check.Run(ctx)
+		require.Equal(t, c.ok, err == nil, "%s: %v", c.check.Name(), err)
+	}
+}
+
+func TestHealthDaemon(t *testing.T) {
+	ctx := context.Background()
+
+	m := &mockHealthAPI{
+		head:  mkHead(time.Now()),
+		peers: 0,
+	}
+	act := &countAction{}
+	d := &healthDaemon{
+		checks: []Check{
+			&headLagCheck{api: m, maxLag: time.Minute, now: time.Now},
+			&peerCountCheck{api: m, minPeers: 1},
+		},
+		actions:      []Action{act},
+		checkTimeout: time.Second,
+		threshold:    2,
+		trigger:      triggerUnhealthy,
+	}
+
+	srv := httptest.NewServer(d.mux())
+	defer srv.Close()
+
+	getReport := func(path string) (int, *Report) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+
+		var r Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&r))
+		return resp.StatusCode, &r
+	}
+
+	// no report yet
+	code, _ := getReport("/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	// no peers: healthy but not ready
+	d.round(ctx)
+	code, r := getReport("/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, r.Healthy)
+	code, r = getReport("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.False(t, r.Ready)
+	require.Len(t, r.Checks, 2)
+	require.False(t, r.Checks[1].OK)
+	require.Equal(t, 0, act.n)
+
+	// stalled head: unhealthy, actions trigger after threshold rounds
+	m.head = mkHead(time.Now().Add(-time.Hour))
+	d.round(ctx)
+	require.Equal(t, 0, act.n)
+	d.round(ctx)
+	require.Equal(t, 1, act.n)
+
+	code, r = getReport("/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.False(t, r.Healthy)
+
+	// recovered
+	m.head = mkHead(time.Now())
+	m.peers = 5
+	d.round(ctx)
+	d.round(ctx)
+	require.Equal(t, 1, act.n)
+	code, _ = getReport("/readyz")
+	require.Equal(t, http.StatusOK, code)
+
+	// healthy rounds never trigger actions
+	d.threshold = 0
+	d.round(ctx)
+	require.Equal(t, 1, act.n)
+}
@@ -31,6 +31,7 @@ func main() {
 
 	local := []*cli.Command{
 		watchHeadCmd,
+		runCmd,
 	}
 
 	app := &cli.App{
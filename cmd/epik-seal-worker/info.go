@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
@@ -57,6 +58,9 @@ var infoCmd = &cli.Command{
 		}
 
 		fmt.Printf("Hostname: %s\n", info.Hostname)
+		if len(info.Tags) > 0 {
+			fmt.Printf("Tags: %s\n", strings.Join(info.Tags, ", "))
+		}
 		fmt.Printf("CPUs: %d; GPUs: %v\n", info.Resources.CPUs, info.Resources.GPUs)
 		fmt.Printf("RAM: %s; Swap: %s\n", types.SizeStr(types.NewInt(info.Resources.MemPhysical)), types.SizeStr(types.NewInt(info.Resources.MemSwap)))
 		fmt.Printf("Reserved memory: %s\n", types.SizeStr(types.NewInt(info.Resources.MemReserved)))
@@ -67,6 +71,22 @@ var infoCmd = &cli.Command{
 		}
 		fmt.Println()
 
+		for _, t := range ttList(tt) {
+			r, ok := info.TaskResources[t]
+			if !ok {
+				continue
+			}
+			fmt.Printf("%s resources: min mem %s, max mem %s, base mem %s, threads %d", t.Short(),
+				types.SizeStr(types.NewInt(r.MinMemory)), types.SizeStr(types.NewInt(r.MaxMemory)), types.SizeStr(types.NewInt(r.BaseMinMemory)), r.MaxParallelism)
+			if r.CanGPU != nil {
+				fmt.Printf(", gpu %t", *r.CanGPU)
+			}
+			if r.MaxConcurrent > 0 {
+				fmt.Printf(", max parallel %d", r.MaxConcurrent)
+			}
+			fmt.Println(" (0 = default)")
+		}
+
 		fmt.Println()
 
 		paths, err := api.Paths(ctx)
@@ -146,6 +146,23 @@ var runCmd = &cli.Command{
 			Usage: "enable commit (32G sectors: all cores or GPUs, 128GiB Memory + 64GiB swap)",
 			Value: true,
 		},
+		&cli.BoolFlag{
+			Name:  "windowpost",
+			Usage: "enable window PoSt computation, for the sectors stored in the local paths of the worker (all cores or GPUs)",
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name:  "resources",
+			Usage: "toml file overriding the resources the scheduler assumes tasks need on this worker, keyed by task type (e.g. [PC1] MaxParallel = 2); <TASK>_MIN_MEMORY, _MAX_MEMORY, _BASE_MIN_MEMORY, _THREADS, _GPU and _MAX_PARALLEL env vars take precedence",
+		},
+		&cli.StringSliceFlag{
+			Name:  "tag",
+			Usage: "tag the worker for the scheduler affinity rules, either a label or a key=value pair (e.g. rack=a), can be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:  "max-parallel",
+			Usage: "maximum number of tasks of a type running at once on this worker, e.g. PC1=2",
+		},
 		&cli.IntFlag{
 			Name:  "parallel-fetch-limit",
 			Usage: "maximum fetch operations to run in parallel",
@@ -226,7 +243,7 @@ var runCmd = &cli.Command{
 			return err
 		}
 
-		if cctx.Bool("commit") {
+		if cctx.Bool("commit") || cctx.Bool("windowpost") {
 			if err := paramfetch.GetParams(ctx, build.ParametersJSON(), uint64(ssize)); err != nil {
 				return xerrors.Errorf("get params: %w", err)
 			}
@@ -251,11 +268,19 @@ var runCmd = &cli.Command{
 		if cctx.Bool("commit") {
 			taskTypes = append(taskTypes, sealtasks.TTCommit2)
 		}
+		if cctx.Bool("windowpost") {
+			taskTypes = append(taskTypes, sealtasks.TTGenerateWindowPoSt)
+		}
 
 		if len(taskTypes) == 0 {
 			return xerrors.Errorf("no task types specified")
 		}
 
+		taskResources, err := loadTaskResources(cctx, taskTypes)
+		if err != nil {
+			return err
+		}
+
 		// Open repo
 
 		repoPath := cctx.String(FlagWorkerRepo)
@@ -380,8 +405,10 @@ var runCmd = &cli.Command{
 
 		workerApi := &worker{
 			LocalWorker: sectorstorage.NewLocalWorker(sectorstorage.WorkerConfig{
-				TaskTypes: taskTypes,
-				NoSwap:    cctx.Bool("no-swap"),
+				TaskTypes:     taskTypes,
+				NoSwap:        cctx.Bool("no-swap"),
+				TaskResources: taskResources,
+				Tags:          cctx.StringSlice("tag"),
 			}, remote, localStore, nodeApi, nodeApi, wsts),
 			localStore: localStore,
 			ls:         lr,
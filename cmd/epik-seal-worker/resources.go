@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/docker/go-units"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// resourceOverride is how the resource overrides of a task type are written
+// in the resources file, memory sizes being strings like "64GiB".
+type resourceOverride struct {
+	MinMemory     string
+	MaxMemory     string
+	BaseMinMemory string
+	Threads       int
+	GPU           *bool
+	MaxParallel   int
+}
+
+// loadTaskResources builds the resource overrides for the given task types
+// from, in increasing precedence, the --resources file, <TASK>_* environment
+// variables (e.g. PC1_MAX_PARALLEL) and the --max-parallel flag.
+func loadTaskResources(cctx *cli.Context, taskTypes []sealtasks.TaskType) (map[sealtasks.TaskType]storiface.TaskResources, error) {
+	byShort := map[string]sealtasks.TaskType{}
+	for _, tt := range taskTypes {
+		byShort[tt.Short()] = tt
+	}
+
+	overrides := map[string]*resourceOverride{}
+	if path := cctx.String("resources"); path != "" {
+		if _, err := toml.DecodeFile(path, &overrides); err != nil {
+			return nil, xerrors.Errorf("reading resources file: %w", err)
+		}
+		for short := range overrides {
+			if _, ok := byShort[short]; !ok {
+				return nil, xerrors.Errorf("resources file: unknown or disabled task type %s", short)
+			}
+		}
+	}
+
+	for short := range byShort {
+		o := overrides[short]
+		if o == nil {
+			o = &resourceOverride{}
+		}
+		if err := o.fromEnv(short); err != nil {
+			return nil, err
+		}
+		if *o != (resourceOverride{}) {
+			overrides[short] = o
+		}
+	}
+
+	for _, s := range cctx.StringSlice("max-parallel") {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			return nil, xerrors.Errorf("--max-parallel: expected TASK=N, got %q", s)
+		}
+		if _, ok := byShort[kv[0]]; !ok {
+			return nil, xerrors.Errorf("--max-parallel: unknown or disabled task type %s", kv[0])
+		}
+		n, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return nil, xerrors.Errorf("--max-parallel %s: %w", kv[0], err)
+		}
+		if overrides[kv[0]] == nil {
+			overrides[kv[0]] = &resourceOverride{}
+		}
+		overrides[kv[0]].MaxParallel = n
+	}
+
+	out := map[sealtasks.TaskType]storiface.TaskResources{}
+	for short, o := range overrides {
+		res, err := o.taskResources()
+		if err != nil {
+			return nil, xerrors.Errorf("%s resources: %w", short, err)
+		}
+		out[byShort[short]] = res
+	}
+	return out, nil
+}
+
+func (o *resourceOverride) fromEnv(short string) error {
+	for name, dst := range map[string]*string{
+		"MIN_MEMORY":      &o.MinMemory,
+		"MAX_MEMORY":      &o.MaxMemory,
+		"BASE_MIN_MEMORY": &o.BaseMinMemory,
+	} {
+		if v, ok := os.LookupEnv(short + "_" + name); ok {
+			*dst = v
+		}
+	}
+
+	for name, dst := range map[string]*int{
+		"THREADS":      &o.Threads,
+		"MAX_PARALLEL": &o.MaxParallel,
+	} {
+		if v, ok := os.LookupEnv(short + "_" + name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return xerrors.Errorf("parsing %s_%s: %w", short, name, err)
+			}
+			*dst = n
+		}
+	}
+
+	if v, ok := os.LookupEnv(short + "_GPU"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return xerrors.Errorf("parsing %s_GPU: %w", short, err)
+		}
+		o.GPU = &b
+	}
+
+	return nil
+}
+
+func (o *resourceOverride) taskResources() (storiface.TaskResources, error) {
+	res := storiface.TaskResources{
+		MaxParallelism: o.Threads,
+		CanGPU:         o.GPU,
+		MaxConcurrent:  o.MaxParallel,
+	}
+
+	for _, m := range []struct {
+		v   string
+		dst *uint64
+	}{
+		{o.MinMemory, &res.MinMemory},
+		{o.MaxMemory, &res.MaxMemory},
+		{o.BaseMinMemory, &res.BaseMinMemory},
+	} {
+		if m.v == "" {
+			continue
+		}
+		n, err := units.RAMInBytes(m.v)
+		if err != nil {
+			return storiface.TaskResources{}, xerrors.Errorf("parsing memory size %q: %w", m.v, err)
+		}
+		*m.dst = uint64(n)
+	}
+
+	if res.MaxParallelism < -1 {
+		return storiface.TaskResources{}, xerrors.Errorf("invalid thread count %d", res.MaxParallelism)
+	}
+	if res.MaxConcurrent < 0 {
+		return storiface.TaskResources{}, xerrors.Errorf("invalid parallel task limit %d", res.MaxConcurrent)
+	}
+
+	return res, nil
+}
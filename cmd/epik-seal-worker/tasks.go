@@ -27,6 +27,8 @@ var allowSetting = map[sealtasks.TaskType]struct{}{
 	sealtasks.TTPreCommit2: {},
 	sealtasks.TTCommit2:    {},
 	sealtasks.TTUnseal:     {},
+
+	sealtasks.TTGenerateWindowPoSt: {},
 }
 
 var settableStr = func() string {
@@ -3,14 +3,17 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"text/tabwriter"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
 	"github.com/EpiK-Protocol/go-epik/api/apibstore"
+	"github.com/EpiK-Protocol/go-epik/build"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
 	"github.com/EpiK-Protocol/go-epik/chain/store"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
@@ -29,6 +32,7 @@ var provingCmd = &cli.Command{
 		provingDeadlineInfoCmd,
 		provingFaultsCmd,
 		provingCheckProvableCmd,
+		provingComputeCmd,
 	},
 }
 
@@ -480,3 +484,120 @@ var provingCheckProvableCmd = &cli.Command{
 		return tw.Flush()
 	},
 }
+
+var provingComputeCmd = &cli.Command{
+	Name:  "compute",
+	Usage: "Compute the window PoSt of a deadline without submitting it",
+	Description: `Runs the sector checks and the proof generation done when the deadline opens,
+at the current chain head, and reports how long each partition took and which
+sectors couldn't be proven. Nothing is sent to the chain.
+
+Proving a deadline with many partitions can take a long time and uses the
+same resources as the window PoSt of the miner, avoid running it close to a
+deadline of the miner.`,
+	Flags: []cli.Flag{
+		&cli.Uint64Flag{
+			Name:     "deadline",
+			Usage:    "index of the deadline to compute the proof for",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := lcli.GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		sapi, scloser, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer scloser()
+
+		ctx := lcli.ReqContext(cctx)
+
+		addr, err := sapi.ActorAddress(ctx)
+		if err != nil {
+			return err
+		}
+
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+
+		di, err := api.StateMinerProvingDeadline(ctx, addr, head.Key())
+		if err != nil {
+			return err
+		}
+
+		dlIdx := cctx.Uint64("deadline")
+		fmt.Printf("Computing window PoSt for deadline %d at height %d...\n", dlIdx, head.Height())
+
+		res, err := sapi.ComputeWindowPoSt(ctx, dlIdx, head.Key())
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "partition\tsectors\tbad\tskipped\tcheck\tprove\tstatus")
+
+		type badSector struct {
+			partition uint64
+			sector    abi.SectorNumber
+			reason    string
+		}
+
+		var bad []badSector
+		for _, p := range res.Partitions {
+			status := color.GreenString("ok")
+			if p.Error != "" {
+				status = color.RedString("error: %s", p.Error)
+			} else if p.Sectors == 0 {
+				status = "nothing to prove"
+			}
+
+			_, _ = fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s\t%s\t%s\n", p.Index, p.Sectors, len(p.Bad), len(p.Skipped),
+				p.CheckTime.Truncate(time.Millisecond), p.ProveTime.Truncate(time.Millisecond), status)
+
+			for s, reason := range p.Bad {
+				bad = append(bad, badSector{p.Index, s, reason})
+			}
+			for _, s := range p.Skipped {
+				bad = append(bad, badSector{p.Index, s, "skipped by the prover"})
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if len(bad) > 0 {
+			sort.Slice(bad, func(i, j int) bool {
+				if bad[i].partition != bad[j].partition {
+					return bad[i].partition < bad[j].partition
+				}
+				return bad[i].sector < bad[j].sector
+			})
+
+			fmt.Println()
+			tw = tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "partition\tsector\treason")
+			for _, b := range bad {
+				_, _ = fmt.Fprintf(tw, "%d\t%d\t%s\n", b.partition, b.sector, color.RedString("%s", b.reason))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+
+		window := time.Duration(di.WPoStChallengeWindow) * time.Duration(build.BlockDelaySecs) * time.Second
+		elapsed := color.GreenString("%s", res.Elapsed.Truncate(time.Millisecond))
+		if res.Elapsed > window*3/4 {
+			elapsed = color.RedString("%s", res.Elapsed.Truncate(time.Millisecond))
+		}
+		fmt.Printf("\nTotal: %s (challenge window: %s)\n", elapsed, window)
+
+		return nil
+	},
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -15,6 +16,8 @@ import (
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/go-state-types/abi"
+
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 
 	"github.com/EpiK-Protocol/go-epik/chain/types"
@@ -29,6 +32,8 @@ var sealingCmd = &cli.Command{
 		sealingWorkersCmd,
 		sealingSchedDiagCmd,
 		sealingAbortCmd,
+		sealingQueueCmd,
+		sealingSetPriorityCmd,
 	},
 }
 
@@ -82,6 +87,9 @@ var sealingWorkersCmd = &cli.Command{
 			}
 
 			fmt.Printf("Worker %s, host %s%s\n", stat.id, color.MagentaString(stat.Info.Hostname), disabled)
+			if len(stat.Info.Tags) > 0 {
+				fmt.Printf("\tTags: %s\n", strings.Join(stat.Info.Tags, ", "))
+			}
 
 			var barCols = uint64(64)
 			cpuBars := int(stat.CpuUse * barCols / stat.Info.Resources.CPUs)
@@ -305,3 +313,76 @@ var sealingAbortCmd = &cli.Command{
 		return nodeApi.SealingAbort(ctx, job.ID)
 	},
 }
+
+var sealingQueueCmd = &cli.Command{
+	Name:  "queue",
+	Usage: "list tasks waiting in the scheduler queue, in scheduling order",
+	Action: func(cctx *cli.Context) error {
+		nodeApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		queue, err := nodeApi.SealingSchedQueue(ctx)
+		if err != nil {
+			return xerrors.Errorf("getting scheduler queue: %w", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "Sector\tTask\tPriority\tWaiting\tEst. Wait\n")
+
+		for _, e := range queue {
+			waiting := "n/a"
+			if !e.Queued.IsZero() {
+				waiting = time.Now().Sub(e.Queued).Truncate(time.Second).String()
+			}
+			est := "unknown"
+			if e.EstWait > 0 {
+				est = e.EstWait.Truncate(time.Second).String()
+			}
+
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\n",
+				e.Sector.Number,
+				e.Task.Short(),
+				e.Priority,
+				waiting,
+				est)
+		}
+
+		return tw.Flush()
+	},
+}
+
+var sealingSetPriorityCmd = &cli.Command{
+	Name:      "set-priority",
+	Usage:     "Set the scheduling priority of the tasks of a sector, larger values being scheduled first",
+	ArgsUsage: "[sectorNum] [priority]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 2 {
+			return xerrors.Errorf("expected 2 arguments")
+		}
+
+		id, err := strconv.ParseUint(cctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return xerrors.Errorf("could not parse sector number: %w", err)
+		}
+
+		prio, err := strconv.Atoi(cctx.Args().Get(1))
+		if err != nil {
+			return xerrors.Errorf("could not parse priority: %w", err)
+		}
+
+		nodeApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		return nodeApi.SealingSchedSetPriority(ctx, abi.SectorNumber(id), prio)
+	},
+}
@@ -110,6 +110,22 @@ var sectorsStatusCmd = &cli.Command{
 		}
 
 		if cctx.Bool("log") {
+			if len(status.StateRetries) > 0 {
+				fmt.Printf("--------\nRetries:\n")
+
+				states := make([]api.SectorState, 0, len(status.StateRetries))
+				for st := range status.StateRetries {
+					states = append(states, st)
+				}
+				sort.Slice(states, func(i, j int) bool {
+					return states[i] < states[j]
+				})
+
+				for _, st := range states {
+					fmt.Printf("%s:\t%d\n", st, status.StateRetries[st])
+				}
+			}
+
 			fmt.Printf("--------\nEvent Log:\n")
 
 			for i, l := range status.Log {
@@ -10,8 +10,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/fatih/color"
 	"github.com/google/uuid"
 	"github.com/mitchellh/go-homedir"
@@ -45,6 +47,9 @@ stored while moving through the sealing pipeline (references as 'seal').`,
 		storageListCmd,
 		storageFindCmd,
 		storageCleanupCmd,
+		storageMigrateCmd,
+		storageSetCmd,
+		storageScrubCmd,
 	},
 }
 
@@ -264,6 +269,14 @@ var storageListCmd = &cli.Command{
 			} else {
 				fmt.Print(color.HiYellowString("Use: ReadOnly"))
 			}
+			if si.MaxStorage > 0 {
+				fmt.Printf("\tQuota: %s/%s\n",
+					types.SizeStr(types.NewInt(uint64(st.Used))),
+					types.SizeStr(types.NewInt(si.MaxStorage)))
+			}
+			if si.Draining {
+				fmt.Printf("\t%s\n", color.HiYellowString("Draining"))
+			}
 
 			if localPath, ok := local[s.ID]; ok {
 				fmt.Printf("\tLocal: %s\n", color.GreenString(localPath))
@@ -676,3 +689,285 @@ func cleanupRemovedSectorData(ctx context.Context, api api.StorageMiner, napi ap
 
 	return nil
 }
+
+var storageMigrateCmd = &cli.Command{
+	Name:  "migrate",
+	Usage: "move sector files from one local storage path to another",
+	Description: `Moves the files of all the sectors stored in the --from path to the --to path,
+e.g. to retire a disk. Both paths must be attached to the miner. Files are
+copied and verified first, the sector index is updated and only then are the
+source files deleted.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "ID of the storage path to move sectors from",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "ID of the storage path to move sectors to",
+			Required: true,
+		},
+		&cli.IntFlag{
+			Name:  "parallel",
+			Usage: "number of sectors to move at once",
+			Value: 1,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		from, to := stores.ID(cctx.String("from")), stores.ID(cctx.String("to"))
+		parallel := cctx.Int("parallel")
+		if parallel < 1 {
+			return xerrors.Errorf("--parallel must be at least 1")
+		}
+
+		maddr, err := nodeApi.ActorAddress(ctx)
+		if err != nil {
+			return err
+		}
+		mid, err := address.IDFromAddress(maddr)
+		if err != nil {
+			return err
+		}
+
+		list, err := nodeApi.StorageList(ctx)
+		if err != nil {
+			return xerrors.Errorf("listing storage: %w", err)
+		}
+		decls, ok := list[from]
+		if !ok {
+			return xerrors.Errorf("storage path %s not found", from)
+		}
+		if _, ok := list[to]; !ok {
+			return xerrors.Errorf("storage path %s not found", to)
+		}
+
+		seen := map[abi.SectorNumber]struct{}{}
+		var sectors []abi.SectorNumber
+		for _, decl := range decls {
+			if decl.Miner != abi.ActorID(mid) {
+				continue
+			}
+			if _, ok := seen[decl.Number]; ok {
+				continue
+			}
+			seen[decl.Number] = struct{}{}
+			sectors = append(sectors, decl.Number)
+		}
+		sort.Slice(sectors, func(i, j int) bool {
+			return sectors[i] < sectors[j]
+		})
+
+		fmt.Printf("moving %d sectors from %s to %s\n", len(sectors), from, to)
+
+		var (
+			lk     sync.Mutex
+			done   int
+			failed []abi.SectorNumber
+		)
+
+		todo := make(chan abi.SectorNumber)
+		var wg sync.WaitGroup
+		for i := 0; i < parallel; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for sn := range todo {
+					err := nodeApi.StorageMoveSector(ctx, sn, from, to)
+
+					lk.Lock()
+					done++
+					if err != nil {
+						failed = append(failed, sn)
+						fmt.Printf("[%d/%d] sector %d: %s\n", done, len(sectors), sn, color.RedString("%s", err))
+					} else {
+						fmt.Printf("[%d/%d] sector %d: moved\n", done, len(sectors), sn)
+					}
+					lk.Unlock()
+				}
+			}()
+		}
+
+	loop:
+		for _, sn := range sectors {
+			select {
+			case todo <- sn:
+			case <-ctx.Done():
+				break loop
+			}
+		}
+		close(todo)
+		wg.Wait()
+
+		if len(failed) > 0 {
+			return xerrors.Errorf("failed to move %d sectors: %v", len(failed), failed)
+		}
+
+		return ctx.Err()
+	},
+}
+
+var storageSetCmd = &cli.Command{
+	Name:      "set",
+	Usage:     "change the settings of a local storage path",
+	ArgsUsage: "[path id]",
+	Description: `Updates the sectorstore.json of a storage path attached to the miner, and
+applies the change without restarting the miner.
+
+A path with --max-storage set won't get new sectors once the sector data stored
+in it would exceed the quota. A path with --drain set won't get new sectors at
+all, while the sectors already stored there can still be read, e.g. when
+moving them away with 'storage migrate'.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "max-storage",
+			Usage: "limit the space used by sector data in the path, e.g. 10TiB; 0 for no limit",
+		},
+		&cli.BoolFlag{
+			Name:  "drain",
+			Usage: "stop allocating new sectors in the path; use --drain=false to resume",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		if cctx.Args().Len() != 1 {
+			return xerrors.Errorf("must specify storage path id")
+		}
+		id := stores.ID(cctx.Args().First())
+
+		var upd stores.StorageMetaUpdate
+		if cctx.IsSet("max-storage") {
+			max, err := units.RAMInBytes(cctx.String("max-storage"))
+			if err != nil {
+				return xerrors.Errorf("parsing max-storage: %w", err)
+			}
+			if max < 0 {
+				return xerrors.Errorf("max-storage can't be negative")
+			}
+			ms := uint64(max)
+			upd.MaxStorage = &ms
+		}
+		if cctx.IsSet("drain") {
+			drain := cctx.Bool("drain")
+			upd.Draining = &drain
+		}
+
+		if upd.MaxStorage == nil && upd.Draining == nil {
+			return xerrors.Errorf("nothing to set, specify --max-storage and/or --drain")
+		}
+
+		return nodeApi.StorageSetMeta(ctx, id, upd)
+	},
+}
+
+var storageScrubCmd = &cli.Command{
+	Name:  "scrub",
+	Usage: "show the state of the background sector integrity check",
+	Description: `The miner periodically generates a vanilla proof for each of its active
+sectors to find damaged sector files before they fail a window PoSt. Sectors
+which failed their latest check are listed with the deadline they will likely
+fault in, unless their files are repaired or replaced before then.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "color"},
+	},
+	Action: func(cctx *cli.Context) error {
+		color.NoColor = !cctx.Bool("color")
+
+		nodeApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		st, err := nodeApi.StorageScrubStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !st.Enabled {
+			fmt.Println("Scrubber disabled, see Scrub.Enable in the miner config")
+			return nil
+		}
+
+		fmt.Printf("Completed passes: %d\n", st.Passes)
+		if !st.LastPassEnd.IsZero() {
+			fmt.Printf("Last pass ended: %s\n", st.LastPassEnd.Format(time.Stamp))
+		}
+		if st.Running {
+			fmt.Printf("Current pass: %d/%d sectors, started %s\n", st.Checked, st.Total, st.PassStart.Format(time.Stamp))
+		}
+
+		ids := make([]string, 0, len(st.Paths))
+		for id := range st.Paths {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		fmt.Println()
+		tw := tablewriter.New(
+			tablewriter.Col("Storage"),
+			tablewriter.Col("Checked"),
+			tablewriter.Col("Failed"),
+			tablewriter.Col("Last Check"),
+		)
+		for _, id := range ids {
+			ps := st.Paths[id]
+			name := id
+			if name == "" {
+				name = "<missing>"
+			}
+			failed := fmt.Sprint(ps.Failed)
+			if ps.Failed > 0 {
+				failed = color.RedString("%d", ps.Failed)
+			}
+			tw.Write(map[string]interface{}{
+				"Storage":    name,
+				"Checked":    ps.Checked,
+				"Failed":     failed,
+				"Last Check": ps.LastCheck.Format(time.Stamp),
+			})
+		}
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		if len(st.Failed) == 0 {
+			return nil
+		}
+
+		fmt.Printf("\n%s\n", color.RedString("Sectors likely to fault:"))
+		tw = tablewriter.New(
+			tablewriter.Col("Sector"),
+			tablewriter.Col("Deadline"),
+			tablewriter.Col("Opens"),
+			tablewriter.Col("Storage"),
+			tablewriter.NewLineCol("Error"),
+		)
+		for _, res := range st.Failed {
+			tw.Write(map[string]interface{}{
+				"Sector":   res.Sector.Number,
+				"Deadline": res.Deadline,
+				"Opens":    res.DeadlineOpen,
+				"Storage":  res.Storage,
+				"Error":    res.Error,
+			})
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
@@ -3,7 +3,7 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
@@ -16,7 +16,9 @@ import (
 	"strings"
 
 	paramfetch "github.com/filecoin-project/go-paramfetch"
+	"github.com/ipfs/go-cid"
 	metricsprom "github.com/ipfs/go-metrics-prometheus"
+	"github.com/ipld/go-car"
 	"github.com/mitchellh/go-homedir"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/urfave/cli/v2"
@@ -36,6 +38,7 @@ import (
 	lcli "github.com/EpiK-Protocol/go-epik/cli"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/ffiwrapper"
 	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/lib/blockstore"
 	"github.com/EpiK-Protocol/go-epik/lib/peermgr"
 	"github.com/EpiK-Protocol/go-epik/lib/ulimit"
 	"github.com/EpiK-Protocol/go-epik/metrics"
@@ -110,6 +113,18 @@ var DaemonCmd = &cli.Command{
 			Name:  "import-snapshot",
 			Usage: "import chain state from a given chain export file or url",
 		},
+		&cli.StringSliceFlag{
+			Name:  "import-delta",
+			Usage: "apply chain deltas exported with 'epik chain export --delta-base' from given files or urls, in order, after any chain or snapshot import",
+		},
+		&cli.IntFlag{
+			Name:  "import-verify-tipsets",
+			Usage: "when importing a snapshot, recompute the state of this many tipsets below its head and check it matches",
+		},
+		&cli.BoolFlag{
+			Name:  "import-resume",
+			Usage: "resume an interrupted import of the same chain file",
+		},
 		&cli.BoolFlag{
 			Name:  "halt-after-import",
 			Usage: "halt the process after importing chain from file",
@@ -226,18 +241,32 @@ var DaemonCmd = &cli.Command{
 
 		chainfile := cctx.String("import-chain")
 		snapshot := cctx.String("import-snapshot")
-		if chainfile != "" || snapshot != "" {
+		deltas := cctx.StringSlice("import-delta")
+		if chainfile != "" || snapshot != "" || len(deltas) > 0 {
 			if chainfile != "" && snapshot != "" {
 				return fmt.Errorf("cannot specify both 'import-snapshot' and 'import-chain'")
 			}
-			var issnapshot bool
+			opts := importOptions{
+				verifyTipsets: cctx.Int("import-verify-tipsets"),
+				resume:        cctx.Bool("import-resume"),
+				genesis:       genBytes,
+			}
 			if chainfile == "" {
 				chainfile = snapshot
-				issnapshot = true
+				opts.snapshot = true
 			}
 
-			if err := ImportChain(r, chainfile, issnapshot); err != nil {
-				return err
+			if chainfile != "" {
+				if err := ImportChain(r, chainfile, opts); err != nil {
+					return err
+				}
+			}
+			for _, delta := range deltas {
+				dopts := opts
+				dopts.snapshot, dopts.delta, dopts.resume = false, true, false
+				if err := ImportChain(r, delta, dopts); err != nil {
+					return xerrors.Errorf("applying delta %s: %w", delta, err)
+				}
 			}
 			if cctx.Bool("halt-after-import") {
 				fmt.Println("Chain import complete, halting as requested...")
@@ -373,7 +402,18 @@ func importKey(ctx context.Context, api api.FullNode, f string) error {
 	return nil
 }
 
-func ImportChain(r repo.Repo, fname string, snapshot bool) (err error) {
+type importOptions struct {
+	snapshot bool
+	// apply a delta on top of the chain in the repo
+	delta bool
+	// number of tipsets below the snapshot head to verify the state of
+	verifyTipsets int
+	resume        bool
+	// genesis car file of the network, the imported chain must match it
+	genesis []byte
+}
+
+func ImportChain(r repo.Repo, fname string, opts importOptions) (err error) {
 	var rd io.Reader
 	var l int64
 	if strings.HasPrefix(fname, "http://") || strings.HasPrefix(fname, "https://") {
@@ -434,19 +474,42 @@ func ImportChain(r repo.Repo, fname string, snapshot bool) (err error) {
 	cst := store.NewChainStore(bs, bs, mds, vm.Syscalls(ffiwrapper.ProofVerifier), j)
 	defer cst.Close() //nolint:errcheck
 
-	log.Infof("importing chain from %s...", fname)
+	var genesisCid cid.Cid
+	if len(opts.genesis) > 0 {
+		genesisCid, err = loadGenesisCid(opts.genesis)
+		if err != nil {
+			return err
+		}
+	}
 
-	bufr := bufio.NewReaderSize(rd, 1<<20)
+	log.Infof("importing chain from %s...", fname)
 
 	bar := pb.New64(l)
-	br := bar.NewProxyReader(bufr)
 	bar.ShowTimeLeft = true
 	bar.ShowPercent = true
 	bar.ShowSpeed = true
 	bar.Units = pb.U_BYTES
 
+	iopts := store.ImportOptions{
+		Progress: func(p store.ImportProgress) {
+			bar.Set64(int64(p.Bytes))
+			bar.Postfix(fmt.Sprintf(" %d blocks, epoch %d", p.Blocks, p.Epoch))
+		},
+		Resume:  opts.resume,
+		Genesis: genesisCid,
+	}
+
 	bar.Start()
-	ts, err := cst.Import(br)
+	var ts *types.TipSet
+	if opts.delta {
+		var base *types.TipSet
+		base, ts, err = cst.ImportDelta(context.TODO(), rd, iopts)
+		if err == nil {
+			log.Infof("applied delta from height %d to %d", base.Height(), ts.Height())
+		}
+	} else {
+		ts, err = cst.ImportStream(context.TODO(), rd, iopts)
+	}
 	bar.Finish()
 
 	if err != nil {
@@ -469,11 +532,26 @@ func ImportChain(r repo.Repo, fname string, snapshot bool) (err error) {
 
 	stm := stmgr.NewStateManager(cst)
 
-	if !snapshot {
+	if !opts.snapshot && !opts.delta {
 		log.Infof("validating imported chain...")
 		if err := stm.ValidateChain(context.TODO(), ts); err != nil {
 			return xerrors.Errorf("chain validation failed: %w", err)
 		}
+	} else if opts.verifyTipsets > 0 {
+		log.Infof("validating state of the last %d tipsets...", opts.verifyTipsets)
+		if err := stm.ValidateRecentChain(context.TODO(), ts, opts.verifyTipsets); err != nil {
+			return xerrors.Errorf("snapshot validation failed: %w", err)
+		}
+	}
+
+	if opts.delta {
+		if err := cst.Load(); err != nil {
+			log.Warnf("loading chain head: %s", err)
+		}
+		if head := cst.GetHeaviestTipSet(); head != nil && head.Height() >= ts.Height() {
+			log.Infof("keeping current head at height %d, which isn't below the delta", head.Height())
+			return nil
+		}
 	}
 
 	log.Infof("accepting %s as new head", ts.Cids())
@@ -483,3 +561,15 @@ func ImportChain(r repo.Repo, fname string, snapshot bool) (err error) {
 
 	return nil
 }
+
+// loadGenesisCid returns the CID of the root block of a genesis car file.
+func loadGenesisCid(genBytes []byte) (cid.Cid, error) {
+	h, err := car.LoadCar(blockstore.NewTemporary(), bytes.NewReader(genBytes))
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("loading genesis car file failed: %w", err)
+	}
+	if len(h.Roots) != 1 {
+		return cid.Undef, xerrors.New("expected genesis file to have one root")
+	}
+	return h.Roots[0], nil
+}
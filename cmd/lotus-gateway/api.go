@@ -7,7 +7,10 @@ import (
 
 	"github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expert"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/lib/sigs"
 	_ "github.com/EpiK-Protocol/go-epik/lib/sigs/bls"
@@ -72,22 +75,30 @@ type gatewayDepsAPI interface {
 	StateSectorGetInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorOnChainInfo, error)
 	// StateVerifiedClientStatus(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*abi.StoragePower, error)
 	StateVMCirculatingSupplyInternal(context.Context, types.TipSetKey) (api.CirculatingSupply, error)
+	StateListExperts(context.Context, types.TipSetKey) ([]address.Address, error)
+	StateExpertInfo(context.Context, address.Address, types.TipSetKey) (*expert.ExpertInfo, error)
+	StateVoteTally(context.Context, types.TipSetKey) (*vote.Tally, error)
+	StateVoterInfo(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error)
+	StateKnowledgeInfo(context.Context, types.TipSetKey) (*knowledge.Info, error)
+	StateRetrievalPledge(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error)
 }
 
 type GatewayAPI struct {
 	api                    gatewayDepsAPI
 	lookbackCap            time.Duration
 	stateWaitLookbackLimit abi.ChainEpoch
+	cache                  *ResponseCache
 }
 
-// NewGatewayAPI creates a new GatewayAPI with the default lookback cap
-func NewGatewayAPI(api gatewayDepsAPI) *GatewayAPI {
-	return newGatewayAPI(api, LookbackCap, StateWaitLookbackLimit)
+// NewGatewayAPI creates a new GatewayAPI with the default lookback cap. If
+// cache is nil, all calls are forwarded to the node.
+func NewGatewayAPI(api gatewayDepsAPI, cache *ResponseCache) *GatewayAPI {
+	return newGatewayAPI(api, LookbackCap, StateWaitLookbackLimit, cache)
 }
 
 // used by the tests
-func newGatewayAPI(api gatewayDepsAPI, lookbackCap time.Duration, stateWaitLookbackLimit abi.ChainEpoch) *GatewayAPI {
-	return &GatewayAPI{api: api, lookbackCap: lookbackCap, stateWaitLookbackLimit: stateWaitLookbackLimit}
+func newGatewayAPI(api gatewayDepsAPI, lookbackCap time.Duration, stateWaitLookbackLimit abi.ChainEpoch, cache *ResponseCache) *GatewayAPI {
+	return &GatewayAPI{api: api, lookbackCap: lookbackCap, stateWaitLookbackLimit: stateWaitLookbackLimit, cache: cache}
 }
 
 func (a *GatewayAPI) checkTipsetKey(ctx context.Context, tsk types.TipSetKey) error {
@@ -135,7 +146,16 @@ func (a *GatewayAPI) Version(ctx context.Context) (api.Version, error) {
 }
 
 func (a *GatewayAPI) ChainGetBlockMessages(ctx context.Context, c cid.Cid) (*api.BlockMessages, error) {
-	return a.api.ChainGetBlockMessages(ctx, c)
+	if v, ok := a.cache.get(ctx, "ChainGetBlockMessages", c.KeyString()); ok {
+		return v.(*api.BlockMessages), nil
+	}
+
+	bm, err := a.api.ChainGetBlockMessages(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.add("ChainGetBlockMessages", c.KeyString(), bm)
+	return bm, nil
 }
 
 func (a *GatewayAPI) ChainHasObj(ctx context.Context, c cid.Cid) (bool, error) {
@@ -149,7 +169,16 @@ func (a *GatewayAPI) ChainHead(ctx context.Context) (*types.TipSet, error) {
 }
 
 func (a *GatewayAPI) ChainGetMessage(ctx context.Context, mc cid.Cid) (*types.Message, error) {
-	return a.api.ChainGetMessage(ctx, mc)
+	if v, ok := a.cache.get(ctx, "ChainGetMessage", mc.KeyString()); ok {
+		return v.(*types.Message), nil
+	}
+
+	msg, err := a.api.ChainGetMessage(ctx, mc)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.add("ChainGetMessage", mc.KeyString(), msg)
+	return msg, nil
 }
 
 func (a *GatewayAPI) ChainGetTipSet(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
@@ -186,7 +215,17 @@ func (a *GatewayAPI) ChainGetTipSetByHeight(ctx context.Context, h abi.ChainEpoc
 }
 
 func (a *GatewayAPI) ChainGetNode(ctx context.Context, p string) (*api.IpldObject, error) {
-	return a.api.ChainGetNode(ctx, p)
+	// paths are always rooted at a cid, so the resolved node never changes
+	if v, ok := a.cache.get(ctx, "ChainGetNode", p); ok {
+		return v.(*api.IpldObject), nil
+	}
+
+	nd, err := a.api.ChainGetNode(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.add("ChainGetNode", p, nd)
+	return nd, nil
 }
 
 func (a *GatewayAPI) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange, error) {
@@ -194,7 +233,16 @@ func (a *GatewayAPI) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange,
 }
 
 func (a *GatewayAPI) ChainReadObj(ctx context.Context, c cid.Cid) ([]byte, error) {
-	return a.api.ChainReadObj(ctx, c)
+	if v, ok := a.cache.get(ctx, "ChainReadObj", c.KeyString()); ok {
+		return v.([]byte), nil
+	}
+
+	obj, err := a.api.ChainReadObj(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.addObject("ChainReadObj", c.KeyString(), obj)
+	return obj, nil
 }
 
 func (a *GatewayAPI) GasEstimateMessageGas(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec, tsk types.TipSetKey) (*types.Message, error) {
@@ -254,11 +302,29 @@ func (a *GatewayAPI) StateGetActor(ctx context.Context, actor address.Address, t
 }
 
 func (a *GatewayAPI) StateGetReceipt(ctx context.Context, c cid.Cid, tsk types.TipSetKey) (*types.MessageReceipt, error) {
-	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+	if tsk.IsEmpty() {
+		return a.api.StateGetReceipt(ctx, c, tsk)
+	}
+
+	ts, err := a.api.ChainGetTipSet(ctx, tsk)
+	if err != nil {
 		return nil, err
 	}
+	if err := a.checkTipset(ts); err != nil {
+		return nil, err
+	}
+
+	key := c.KeyString() + tsk.String()
+	if v, ok := a.cache.get(ctx, "StateGetReceipt", key); ok {
+		return v.(*types.MessageReceipt), nil
+	}
 
-	return a.api.StateGetReceipt(ctx, c, tsk)
+	rct, err := a.api.StateGetReceipt(ctx, c, tsk)
+	if err != nil || rct == nil {
+		return rct, err
+	}
+	a.cache.addAt("StateGetReceipt", key, ts, rct)
+	return rct, nil
 }
 
 func (a *GatewayAPI) StateListMiners(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
@@ -403,6 +469,48 @@ func (a *GatewayAPI) StateVMCirculatingSupplyInternal(ctx context.Context, tsk t
 	return a.api.StateVMCirculatingSupplyInternal(ctx, tsk)
 }
 
+func (a *GatewayAPI) StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateListExperts(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateExpertInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*expert.ExpertInfo, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateExpertInfo(ctx, addr, tsk)
+}
+
+func (a *GatewayAPI) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateVoteTally(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateVoterInfo(ctx, addr, tsk)
+}
+
+func (a *GatewayAPI) StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateKnowledgeInfo(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateRetrievalPledge(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalState, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateRetrievalPledge(ctx, addr, tsk)
+}
+
 func (a *GatewayAPI) WalletVerify(ctx context.Context, k address.Address, msg []byte, sig *crypto.Signature) (bool, error) {
 	return sigs.Verify(sig, k, msg) == nil, nil
 }
@@ -91,7 +91,7 @@ func TestGatewayAPIChainGetTipSetByHeight(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &mockGatewayDepsAPI{}
-			a := NewGatewayAPI(mock)
+			a := NewGatewayAPI(mock, nil)
 
 			// Create tipsets from genesis up to tskh and return the highest
 			ts := mock.createTipSets(tt.args.tskh, tt.args.genesisTS)
@@ -107,6 +107,32 @@ func TestGatewayAPIChainGetTipSetByHeight(t *testing.T) {
 	}
 }
 
+func TestGatewayAPIEpiKStateLookback(t *testing.T) {
+	ctx := context.Background()
+
+	lookbackTimestamp := uint64(time.Now().Unix()) - uint64(LookbackCap.Seconds())
+
+	mock := &mockGatewayDepsAPI{}
+	a := NewGatewayAPI(mock, nil)
+
+	// Tipset height is 5, genesis is 10 epochs before LookbackCap, so the
+	// tipset is too old to be queried through the gateway.
+	ts := mock.createTipSets(abi.ChainEpoch(5), lookbackTimestamp-build.BlockDelaySecs*10)
+
+	_, err := a.StateListExperts(ctx, ts.Key())
+	require.Error(t, err)
+	_, err = a.StateExpertInfo(ctx, address.Undef, ts.Key())
+	require.Error(t, err)
+	_, err = a.StateVoteTally(ctx, ts.Key())
+	require.Error(t, err)
+	_, err = a.StateVoterInfo(ctx, address.Undef, ts.Key())
+	require.Error(t, err)
+	_, err = a.StateKnowledgeInfo(ctx, ts.Key())
+	require.Error(t, err)
+	_, err = a.StateRetrievalPledge(ctx, address.Undef, ts.Key())
+	require.Error(t, err)
+}
+
 type mockGatewayDepsAPI struct {
 	lk      sync.RWMutex
 	tipsets []*types.TipSet
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/metrics"
+)
+
+const (
+	DefaultCacheSize          = 1 << 16
+	DefaultCacheMaxObjectSize = 1 << 20
+)
+
+type cacheKey struct {
+	method string
+	key    string
+}
+
+type cacheEntry struct {
+	val interface{}
+
+	// anchor is the tipset the entry was computed against, or an empty key
+	// for content-addressed entries which never become invalid.
+	anchor types.TipSetKey
+}
+
+// ResponseCache is a size-bounded cache of gateway responses which can't
+// change anymore: content-addressed objects, and state lookups against
+// tipsets which are final. Entries anchored to a tipset are dropped if that
+// tipset is ever reverted.
+type ResponseCache struct {
+	lk sync.Mutex
+
+	entries *lru.Cache
+	anchors map[types.TipSetKey]map[cacheKey]struct{}
+	head    abi.ChainEpoch
+
+	finality      abi.ChainEpoch
+	maxObjectSize int
+}
+
+func NewResponseCache(size int, maxObjectSize int) (*ResponseCache, error) {
+	c := &ResponseCache{
+		anchors:       make(map[types.TipSetKey]map[cacheKey]struct{}),
+		finality:      build.Finality,
+		maxObjectSize: maxObjectSize,
+	}
+
+	entries, err := lru.NewWithEvict(size, c.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	c.entries = entries
+
+	return c, nil
+}
+
+// onEvict is called by the lru with c.lk held
+func (c *ResponseCache) onEvict(k interface{}, v interface{}) {
+	e := v.(*cacheEntry)
+	if e.anchor.IsEmpty() {
+		return
+	}
+
+	keys := c.anchors[e.anchor]
+	delete(keys, k.(cacheKey))
+	if len(keys) == 0 {
+		delete(c.anchors, e.anchor)
+	}
+}
+
+func (c *ResponseCache) get(ctx context.Context, method string, key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.lk.Lock()
+	v, ok := c.entries.Get(cacheKey{method: method, key: key})
+	c.lk.Unlock()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Endpoint, method))
+	if !ok {
+		stats.Record(ctx, metrics.GatewayCacheMiss.M(1))
+		return nil, false
+	}
+	stats.Record(ctx, metrics.GatewayCacheHit.M(1))
+
+	return v.(*cacheEntry).val, true
+}
+
+// add caches an immutable, content-addressed response.
+func (c *ResponseCache) add(method string, key string, val interface{}) {
+	if c == nil {
+		return
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	c.entries.Add(cacheKey{method: method, key: key}, &cacheEntry{val: val})
+}
+
+// addObject caches a raw object unless it is larger than the configured limit.
+func (c *ResponseCache) addObject(method string, key string, obj []byte) {
+	if c == nil || len(obj) > c.maxObjectSize {
+		return
+	}
+
+	c.add(method, key, obj)
+}
+
+// addAt caches a response computed against the given tipset, provided that
+// the tipset is final relative to the last head seen by the cache.
+func (c *ResponseCache) addAt(method string, key string, ts *types.TipSet, val interface{}) {
+	if c == nil || ts == nil {
+		return
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if c.head == 0 || ts.Height() > c.head-c.finality {
+		return
+	}
+
+	ck := cacheKey{method: method, key: key}
+	c.entries.Add(ck, &cacheEntry{val: val, anchor: ts.Key()})
+
+	keys, ok := c.anchors[ts.Key()]
+	if !ok {
+		keys = make(map[cacheKey]struct{})
+		c.anchors[ts.Key()] = keys
+	}
+	keys[ck] = struct{}{}
+}
+
+func (c *ResponseCache) headChange(changes []*api.HeadChange) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	for _, hc := range changes {
+		switch hc.Type {
+		case store.HCRevert:
+			// remove everything anchored at the reverted tipset. Shouldn't happen
+			// as only final tipsets are cached, but don't trust that blindly.
+			for ck := range c.anchors[hc.Val.Key()] {
+				c.entries.Remove(ck)
+			}
+			delete(c.anchors, hc.Val.Key())
+			c.head = hc.Val.Height() - 1
+		default:
+			c.head = hc.Val.Height()
+		}
+	}
+}
+
+// Run follows chain head changes until ctx is done or the notification
+// channel is closed.
+func (c *ResponseCache) Run(ctx context.Context, notifs <-chan []*api.HeadChange) {
+	for {
+		select {
+		case changes, ok := <-notifs:
+			if !ok {
+				log.Warn("chain notify channel closed, response cache stops tracking head")
+				return
+			}
+			c.headChange(changes)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
+)
+
+func TestResponseCache(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := NewResponseCache(2, 4)
+	require.NoError(t, err)
+
+	// content-addressed entries
+	c.add("ChainGetMessage", "a", 1)
+	v, ok := c.get(ctx, "ChainGetMessage", "a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	_, ok = c.get(ctx, "ChainReadObj", "a")
+	require.False(t, ok)
+
+	// objects above the size limit aren't cached
+	c.addObject("ChainReadObj", "big", []byte("12345"))
+	_, ok = c.get(ctx, "ChainReadObj", "big")
+	require.False(t, ok)
+
+	// the cache is size bounded
+	c.add("ChainGetMessage", "b", 2)
+	c.add("ChainGetMessage", "c", 3)
+	_, ok = c.get(ctx, "ChainGetMessage", "a")
+	require.False(t, ok)
+}
+
+func TestResponseCacheFinality(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := NewResponseCache(16, 16)
+	require.NoError(t, err)
+	c.finality = 3
+
+	var tipsets []*types.TipSet
+	var ts *types.TipSet
+	for i := 0; i < 6; i++ {
+		ts = mock.TipSet(mock.MkBlock(ts, 1, 1))
+		tipsets = append(tipsets, ts)
+	}
+
+	// no head seen yet, nothing is final
+	c.addAt("StateGetReceipt", "r1", tipsets[1], 1)
+	_, ok := c.get(ctx, "StateGetReceipt", "r1")
+	require.False(t, ok)
+
+	c.headChange([]*api.HeadChange{{Type: store.HCCurrent, Val: tipsets[5]}})
+
+	// tipsets within finality of the head aren't cached
+	c.addAt("StateGetReceipt", "r4", tipsets[4], 4)
+	_, ok = c.get(ctx, "StateGetReceipt", "r4")
+	require.False(t, ok)
+
+	c.addAt("StateGetReceipt", "r1", tipsets[1], 1)
+	v, ok := c.get(ctx, "StateGetReceipt", "r1")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	// reverting the anchor tipset drops the entry
+	c.headChange([]*api.HeadChange{{Type: store.HCRevert, Val: tipsets[1]}})
+	_, ok = c.get(ctx, "StateGetReceipt", "r1")
+	require.False(t, ok)
+	require.Empty(t, c.anchors)
+}
@@ -244,7 +244,7 @@ func startNodes(
 				fullNode := nodes[0]
 
 				// Create a gateway server in front of the full node
-				gapiImpl := newGatewayAPI(fullNode, lookbackCap, stateWaitLookbackLimit)
+				gapiImpl := newGatewayAPI(fullNode, lookbackCap, stateWaitLookbackLimit, nil)
 				_, addr, err := builder.CreateRPCServer(gapiImpl)
 				require.NoError(t, err)
 
@@ -5,9 +5,13 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"time"
 
+	"contrib.go.opencensus.io/exporter/prometheus"
 	"github.com/filecoin-project/go-jsonrpc"
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
 
 	"github.com/EpiK-Protocol/go-epik/build"
 	lcli "github.com/EpiK-Protocol/go-epik/cli"
@@ -65,6 +69,48 @@ var runCmd = &cli.Command{
 			Name:  "api-max-req-size",
 			Usage: "maximum API request size accepted by the JSON RPC server",
 		},
+		&cli.DurationFlag{
+			Name:  "ip-rate",
+			Usage: "interval at which a remote IP is granted one call token, 0 disables per-IP limits",
+			Value: 100 * time.Millisecond,
+		},
+		&cli.IntFlag{
+			Name:  "ip-burst",
+			Usage: "maximum number of call tokens a remote IP can accumulate",
+			Value: 100,
+		},
+		&cli.DurationFlag{
+			Name:  "token-rate",
+			Usage: "interval at which an auth token is granted one call token, 0 disables per-token limits",
+			Value: 10 * time.Millisecond,
+		},
+		&cli.IntFlag{
+			Name:  "token-burst",
+			Usage: "maximum number of call tokens an auth token can accumulate",
+			Value: 500,
+		},
+		&cli.StringSliceFlag{
+			Name:  "method-cost",
+			Usage: "override the number of call tokens charged for a method, e.g. StateWaitMsg=50",
+		},
+		&cli.IntFlag{
+			Name:  "cache-size",
+			Usage: "number of immutable responses kept in the response cache, 0 disables caching",
+			Value: DefaultCacheSize,
+		},
+		&cli.IntFlag{
+			Name:  "cache-max-object-size",
+			Usage: "maximum size in bytes of a raw object kept in the response cache",
+			Value: DefaultCacheMaxObjectSize,
+		},
+		&cli.StringFlag{
+			Name:  "real-ip-header",
+			Usage: "header carrying the client IP when running behind a reverse proxy, e.g. X-Forwarded-For; requires --trusted-proxy",
+		},
+		&cli.StringSliceFlag{
+			Name:  "trusted-proxy",
+			Usage: "address or CIDR range of a reverse proxy allowed to set the client IP header, can be repeated",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		log.Info("Starting epik gateway")
@@ -75,7 +121,7 @@ var runCmd = &cli.Command{
 
 		// Register all metric views
 		if err := view.Register(
-			metrics.DefaultViews...,
+			append(metrics.DefaultViews, metrics.GatewayViews...)...,
 		); err != nil {
 			log.Fatalf("Cannot register the view: %v", err)
 		}
@@ -95,10 +141,74 @@ var runCmd = &cli.Command{
 		if maxRequestSize := cctx.Int("api-max-req-size"); maxRequestSize != 0 {
 			serverOptions = append(serverOptions, jsonrpc.WithMaxRequestSize(int64(maxRequestSize)))
 		}
+		costs, err := ParseMethodCosts(cctx.StringSlice("method-cost"))
+		if err != nil {
+			return err
+		}
+		limiter := NewRateLimiter(RateLimiterConfig{
+			IPRate:      cctx.Duration("ip-rate"),
+			IPBurst:     cctx.Int("ip-burst"),
+			TokenRate:   cctx.Duration("token-rate"),
+			TokenBurst:  cctx.Int("token-burst"),
+			IdleTimeout: 10 * time.Minute,
+			MethodCosts: costs,
+		})
+		go limiter.Run(ctx)
+
+		var cache *ResponseCache
+		if size := cctx.Int("cache-size"); size > 0 {
+			cache, err = NewResponseCache(size, cctx.Int("cache-max-object-size"))
+			if err != nil {
+				return err
+			}
+
+			notifs, err := api.ChainNotify(ctx)
+			if err != nil {
+				return err
+			}
+			go cache.Run(ctx, notifs)
+		}
+
 		rpcServer := jsonrpc.NewServer(serverOptions...)
-		rpcServer.Register("EpiK", metrics.MetricedGatewayAPI(NewGatewayAPI(api)))
+		rpcServer.Register("EpiK", metrics.MetricedGatewayAPI(RateLimitedGatewayAPI(NewGatewayAPI(api, cache), limiter)))
+
+		trusted, err := ParseTrustedProxies(cctx.StringSlice("trusted-proxy"))
+		if err != nil {
+			return err
+		}
+		if cctx.String("real-ip-header") != "" && len(trusted) == 0 {
+			return xerrors.Errorf("--real-ip-header requires at least one --trusted-proxy")
+		}
+
+		tokens, err := NewTokenVerifier(api.AuthVerify, 1024, time.Minute)
+		if err != nil {
+			return err
+		}
+
+		mux.Handle("/rpc/v0", &clientInfoHandler{
+			ipHeader: cctx.String("real-ip-header"),
+			trusted:  trusted,
+			tokens:   tokens,
+			next:     rpcServer,
+		})
+
+		// Prometheus globals are exposed as interfaces, but the prometheus
+		// OpenCensus exporter expects a concrete *Registry. The concrete type of
+		// the globals are actually *Registry, so we downcast them, staying
+		// defensive in case things change under the hood.
+		registry, ok := promclient.DefaultRegisterer.(*promclient.Registry)
+		if !ok {
+			log.Warnf("failed to export default prometheus registry; some metrics will be unavailable; unexpected type: %T", promclient.DefaultRegisterer)
+		}
+		exporter, err := prometheus.NewExporter(prometheus.Options{
+			Registry:  registry,
+			Namespace: "epik_gateway",
+		})
+		if err != nil {
+			return err
+		}
+		mux.Handle("/debug/metrics", exporter)
 
-		mux.Handle("/rpc/v0", rpcServer)
 		mux.PathPrefix("/").Handler(http.DefaultServeMux)
 
 		/*ah := &auth.Handler{
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	lru "github.com/hashicorp/golang-lru"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"golang.org/x/time/rate"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/api/apistruct"
+	"github.com/EpiK-Protocol/go-epik/metrics"
+)
+
+var ErrRateLimited = fmt.Errorf("rate limit exceeded")
+
+// RateLimitedErrorCode is the JSON-RPC error code of calls rejected by the
+// rate limiter, so that clients can tell throttling apart from errors
+// returned by the node.
+const RateLimitedErrorCode = -32005
+
+// ipBuckets is the number of distinct Client metric tags unauthenticated
+// clients are spread over.
+const ipBuckets = 256
+
+// DefaultMethodCosts are the number of tokens charged for a call to the given
+// method. Methods which aren't listed cost a single token.
+var DefaultMethodCosts = map[string]int{
+	"ChainGetNode":           5,
+	"ChainGetTipSetByHeight": 2,
+	"ChainNotify":            10,
+	"GasEstimateMessageGas":  3,
+	"MpoolPush":              2,
+	"StateListExperts":       5,
+	"StateListMiners":        5,
+	"StateWaitMsg":           20,
+}
+
+type RateLimiterConfig struct {
+	IPRate  time.Duration
+	IPBurst int
+
+	TokenRate  time.Duration
+	TokenBurst int
+
+	// IdleTimeout is how long a client limiter is kept around after the last
+	// call made by that client.
+	IdleTimeout time.Duration
+
+	MethodCosts map[string]int
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter applies token-bucket limits to gateway calls. Calls carrying a
+// verified auth token are charged against the bucket of that token, all other
+// calls are charged against the bucket of the remote IP.
+type RateLimiter struct {
+	ips    map[string]*clientLimiter
+	tokens map[string]*clientLimiter
+	mu     *sync.Mutex
+
+	config RateLimiterConfig
+}
+
+func NewRateLimiter(c RateLimiterConfig) *RateLimiter {
+	if c.MethodCosts == nil {
+		c.MethodCosts = DefaultMethodCosts
+	}
+
+	return &RateLimiter{
+		ips:    make(map[string]*clientLimiter),
+		tokens: make(map[string]*clientLimiter),
+		mu:     &sync.Mutex{},
+
+		config: c,
+	}
+}
+
+func (l *RateLimiter) cost(method string) int {
+	if c, ok := l.config.MethodCosts[method]; ok {
+		return c
+	}
+	return 1
+}
+
+func (l *RateLimiter) getLimiter(set map[string]*clientLimiter, key string, every time.Duration, burst int) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cl, exists := set[key]
+	if !exists {
+		cl = &clientLimiter{limiter: rate.NewLimiter(rate.Every(every), burst)}
+		set[key] = cl
+	}
+	cl.lastSeen = time.Now()
+
+	return cl.limiter
+}
+
+// Take charges the cost of the given method to the client found in ctx, and
+// returns ErrRateLimited if the client ran out of tokens.
+func (l *RateLimiter) Take(ctx context.Context, method string) error {
+	ci := clientFromContext(ctx)
+
+	var lim *rate.Limiter
+	switch {
+	case ci.token != "":
+		if l.config.TokenRate == 0 {
+			return nil
+		}
+		lim = l.getLimiter(l.tokens, ci.token, l.config.TokenRate, l.config.TokenBurst)
+	case ci.ip != "":
+		if l.config.IPRate == 0 {
+			return nil
+		}
+		lim = l.getLimiter(l.ips, ci.ip, l.config.IPRate, l.config.IPBurst)
+	default:
+		return nil
+	}
+
+	cost := l.cost(method)
+	r := lim.ReserveN(time.Now(), cost)
+	if !r.OK() {
+		return fmt.Errorf("%w: method %s costs %d, more than the allowed burst %d", ErrRateLimited, method, cost, lim.Burst())
+	}
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+		return fmt.Errorf("%w: method %s, retry in %s", ErrRateLimited, method, d.Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// GC drops limiters of clients which haven't made any calls for IdleTimeout.
+func (l *RateLimiter) GC() {
+	if l.config.IdleTimeout == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := time.Now().Add(-l.config.IdleTimeout)
+	for _, set := range []map[string]*clientLimiter{l.ips, l.tokens} {
+		for k, cl := range set {
+			if cl.lastSeen.Before(cutoff) {
+				delete(set, k)
+			}
+		}
+	}
+}
+
+// Run periodically garbage collects idle client limiters until ctx is done.
+func (l *RateLimiter) Run(ctx context.Context) {
+	if l.config.IdleTimeout == 0 {
+		return
+	}
+
+	tick := time.NewTicker(l.config.IdleTimeout)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			l.GC()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ParseMethodCosts parses 'Method=cost' pairs on top of DefaultMethodCosts.
+func ParseMethodCosts(pairs []string) (map[string]int, error) {
+	out := make(map[string]int, len(DefaultMethodCosts)+len(pairs))
+	for m, c := range DefaultMethodCosts {
+		out[m] = c
+	}
+
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed method cost %q, expected Method=cost", p)
+		}
+		c, err := strconv.Atoi(kv[1])
+		if err != nil || c < 0 {
+			return nil, fmt.Errorf("malformed cost for method %s: %q", kv[0], kv[1])
+		}
+		out[kv[0]] = c
+	}
+
+	return out, nil
+}
+
+type clientInfo struct {
+	ip string
+	// token is only set once the auth token of the request was verified by
+	// the full node
+	token string
+}
+
+// id returns an identifier of the client suitable for metrics. Auth tokens
+// are hashed so they don't leak through the metrics endpoint, and client IPs
+// are hashed into one of ipBuckets buckets to keep the cardinality of the
+// metrics bounded.
+func (ci clientInfo) id() string {
+	if ci.token != "" {
+		h := sha256.Sum256([]byte(ci.token))
+		return "token:" + hex.EncodeToString(h[:4])
+	}
+	if ci.ip != "" {
+		h := sha256.Sum256([]byte(ci.ip))
+		return fmt.Sprintf("ip:%02x", int(h[0])%ipBuckets)
+	}
+	return "anonymous"
+}
+
+type clientInfoKey struct{}
+
+func clientFromContext(ctx context.Context) clientInfo {
+	ci, _ := ctx.Value(clientInfoKey{}).(clientInfo)
+	return ci
+}
+
+type verifiedToken struct {
+	ok      bool
+	expires time.Time
+}
+
+// TokenVerifier checks auth tokens with the full node, caching the results so
+// that requests don't each cost a round trip to the node.
+type TokenVerifier struct {
+	verify func(ctx context.Context, token string) ([]auth.Permission, error)
+	ttl    time.Duration
+
+	cache *lru.Cache
+}
+
+func NewTokenVerifier(verify func(ctx context.Context, token string) ([]auth.Permission, error), size int, ttl time.Duration) (*TokenVerifier, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenVerifier{
+		verify: verify,
+		ttl:    ttl,
+		cache:  cache,
+	}, nil
+}
+
+// Verify returns whether the token was issued by the full node. Tokens which
+// can't be verified, e.g. because the node is unreachable, are rejected.
+func (v *TokenVerifier) Verify(ctx context.Context, token string) bool {
+	if e, ok := v.cache.Get(token); ok {
+		vt := e.(verifiedToken)
+		if time.Now().Before(vt.expires) {
+			return vt.ok
+		}
+	}
+
+	perms, err := v.verify(ctx, token)
+	if err != nil {
+		log.Debugw("rejecting auth token", "error", err)
+	}
+
+	ok := err == nil && len(perms) > 0
+	v.cache.Add(token, verifiedToken{ok: ok, expires: time.Now().Add(v.ttl)})
+	return ok
+}
+
+// TrustedProxies are the reverse proxies allowed to set the client IP header.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a list of IP addresses and CIDR ranges.
+func ParseTrustedProxies(addrs []string) (TrustedProxies, error) {
+	out := make(TrustedProxies, 0, len(addrs))
+	for _, a := range addrs {
+		if !strings.Contains(a, "/") {
+			ip := net.ParseIP(a)
+			if ip == nil {
+				return nil, fmt.Errorf("malformed trusted proxy address %q", a)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			out = append(out, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, fmt.Errorf("malformed trusted proxy range %q: %w", a, err)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func (tp TrustedProxies) contains(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range tp {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientInfoHandler records the remote IP and the verified auth token of each
+// request in the request context, so they are available to the API methods.
+type clientInfoHandler struct {
+	// ipHeader, when set, is the header (e.g. X-Forwarded-For) used to find
+	// the client IP when running behind a reverse proxy. It is only read from
+	// requests coming from one of the trusted proxies.
+	ipHeader string
+	trusted  TrustedProxies
+
+	tokens *TokenVerifier
+
+	next http.Handler
+}
+
+// clientIP returns the remote address of the request, or when it comes from a
+// trusted proxy, the rightmost address in the IP header which wasn't added by
+// another trusted proxy. Entries left of it are set by the client and can't
+// be relied on.
+func (h *clientInfoHandler) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if h.ipHeader == "" || !h.trusted.contains(ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values(h.ipHeader) {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+
+		ip = hop
+		if !h.trusted.contains(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (h *clientInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ci := clientInfo{
+		ip: h.clientIP(r),
+	}
+
+	// unverified tokens are ignored, the call is charged to the client IP
+	if token := r.Header.Get("Authorization"); token != "" && h.tokens != nil {
+		token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+		if h.tokens.Verify(r.Context(), token) {
+			ci.token = token
+		}
+	}
+
+	ctx := context.WithValue(r.Context(), clientInfoKey{}, ci)
+
+	// websocket connections are hijacked by the rpc server, their rate limit
+	// errors are only recognizable by the ErrRateLimited message
+	if strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		h.next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+
+	rw := &rpcErrorWriter{ResponseWriter: w}
+	h.next.ServeHTTP(rw, r.WithContext(ctx))
+	rw.flush()
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	ID      int64           `json:"id"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcErrorWriter buffers the response of a JSON-RPC call over plain HTTP and
+// rewrites the code of rate limit errors to RateLimitedErrorCode, as the rpc
+// server reports all errors returned by API methods with the same code.
+type rpcErrorWriter struct {
+	http.ResponseWriter
+
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *rpcErrorWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *rpcErrorWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *rpcErrorWriter) flush() {
+	body := w.buf.Bytes()
+
+	var resp rpcResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Error != nil && strings.HasPrefix(resp.Error.Message, ErrRateLimited.Error()) {
+		resp.Error.Code = RateLimitedErrorCode
+		if b, err := json.Marshal(&resp); err == nil {
+			body = append(b, '\n')
+			w.status = http.StatusTooManyRequests
+		}
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if _, err := w.ResponseWriter.Write(body); err != nil {
+		log.Debugw("writing rpc response", "error", err)
+	}
+}
+
+// RateLimitedGatewayAPI wraps the gateway api, accounting every call per
+// method and client, and rejecting calls of clients exceeding their limits.
+func RateLimitedGatewayAPI(a api.GatewayAPI, l *RateLimiter) api.GatewayAPI {
+	var out apistruct.GatewayStruct
+
+	rint := reflect.ValueOf(&out.Internal).Elem()
+	ra := reflect.ValueOf(a)
+
+	for f := 0; f < rint.NumField(); f++ {
+		field := rint.Type().Field(f)
+		fn := ra.MethodByName(field.Name)
+
+		rint.Field(f).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) (results []reflect.Value) {
+			ctx := args[0].Interface().(context.Context)
+			ctx, _ = tag.New(ctx,
+				tag.Upsert(metrics.Endpoint, field.Name),
+				tag.Upsert(metrics.Client, clientFromContext(ctx).id()),
+			)
+			stats.Record(ctx, metrics.GatewayCalls.M(1))
+
+			if err := l.Take(ctx, field.Name); err != nil {
+				stats.Record(ctx, metrics.GatewayRateLimited.M(1))
+				log.Debugw("rate limited", "method", field.Name, "client", clientFromContext(ctx).id())
+
+				results = make([]reflect.Value, field.Type.NumOut())
+				for i := 0; i < len(results)-1; i++ {
+					results[i] = reflect.Zero(field.Type.Out(i))
+				}
+				results[len(results)-1] = reflect.ValueOf(&err).Elem()
+				return results
+			}
+
+			return fn.Call(args)
+		}))
+	}
+
+	return &out
+}
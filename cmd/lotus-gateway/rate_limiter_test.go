@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		IPRate:     time.Hour,
+		IPBurst:    3,
+		TokenRate:  time.Hour,
+		TokenBurst: 10,
+		MethodCosts: map[string]int{
+			"StateWaitMsg": 3,
+			"ChainNotify":  20,
+		},
+	})
+
+	ipCtx := context.WithValue(context.Background(), clientInfoKey{}, clientInfo{ip: "10.0.0.1"})
+	otherIPCtx := context.WithValue(context.Background(), clientInfoKey{}, clientInfo{ip: "10.0.0.2"})
+	tokenCtx := context.WithValue(context.Background(), clientInfoKey{}, clientInfo{ip: "10.0.0.1", token: "secret"})
+
+	// per-method costs are charged against the IP bucket
+	require.NoError(t, limiter.Take(ipCtx, "ChainHead"))
+	err := limiter.Take(ipCtx, "StateWaitMsg")
+	require.True(t, errors.Is(err, ErrRateLimited))
+	require.NoError(t, limiter.Take(ipCtx, "ChainHead"))
+	require.NoError(t, limiter.Take(ipCtx, "ChainHead"))
+	require.Error(t, limiter.Take(ipCtx, "ChainHead"))
+
+	// other clients have their own buckets
+	require.NoError(t, limiter.Take(otherIPCtx, "StateWaitMsg"))
+
+	// calls with a token are charged against the token bucket
+	for i := 0; i < 10; i++ {
+		require.NoError(t, limiter.Take(tokenCtx, "ChainHead"))
+	}
+	require.Error(t, limiter.Take(tokenCtx, "ChainHead"))
+
+	// methods costing more than the burst can never be called
+	require.Error(t, limiter.Take(otherIPCtx, "ChainNotify"))
+
+	// calls without client info aren't limited
+	require.NoError(t, limiter.Take(context.Background(), "ChainNotify"))
+}
+
+func TestRateLimiterGC(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		IPRate:      time.Second,
+		IPBurst:     1,
+		IdleTimeout: time.Millisecond,
+	})
+
+	ctx := context.WithValue(context.Background(), clientInfoKey{}, clientInfo{ip: "10.0.0.1"})
+	require.NoError(t, limiter.Take(ctx, "ChainHead"))
+	require.Len(t, limiter.ips, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	limiter.GC()
+	require.Len(t, limiter.ips, 0)
+}
+
+func TestParseMethodCosts(t *testing.T) {
+	costs, err := ParseMethodCosts([]string{"StateWaitMsg=50", "ChainHead=0"})
+	require.NoError(t, err)
+	require.Equal(t, 50, costs["StateWaitMsg"])
+	require.Equal(t, 0, costs["ChainHead"])
+	require.Equal(t, DefaultMethodCosts["ChainGetNode"], costs["ChainGetNode"])
+
+	_, err = ParseMethodCosts([]string{"StateWaitMsg"})
+	require.Error(t, err)
+	_, err = ParseMethodCosts([]string{"StateWaitMsg=-1"})
+	require.Error(t, err)
+}
+
+func TestClientInfoHandler(t *testing.T) {
+	verifyCalls := 0
+	tokens, err := NewTokenVerifier(func(ctx context.Context, token string) ([]auth.Permission, error) {
+		verifyCalls++
+		if token != "valid" {
+			return nil, xerrors.New("invalid token")
+		}
+		return []auth.Permission{"read"}, nil
+	}, 16, time.Minute)
+	require.NoError(t, err)
+
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16"})
+	require.NoError(t, err)
+
+	var got clientInfo
+	h := &clientInfoHandler{
+		ipHeader: "X-Forwarded-For",
+		trusted:  trusted,
+		tokens:   tokens,
+		next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = clientFromContext(r.Context())
+		}),
+	}
+
+	serve := func(remote, xff, token string) clientInfo {
+		r := httptest.NewRequest("POST", "/rpc/v0", strings.NewReader(""))
+		r.RemoteAddr = remote
+		if xff != "" {
+			r.Header.Set("X-Forwarded-For", xff)
+		}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		return got
+	}
+
+	// unverified tokens are charged to the client IP
+	ci := serve("1.2.3.4:1234", "", "random")
+	require.Equal(t, clientInfo{ip: "1.2.3.4"}, ci)
+	require.Regexp(t, "^ip:[0-9a-f]{2}$", ci.id())
+	require.NotContains(t, ci.id(), "1.2.3.4")
+
+	ci = serve("1.2.3.4:1234", "", "valid")
+	require.Equal(t, clientInfo{ip: "1.2.3.4", token: "valid"}, ci)
+	require.NotContains(t, ci.id(), "valid")
+
+	// verification results are cached
+	serve("1.2.3.4:1234", "", "valid")
+	serve("1.2.3.4:1234", "", "random")
+	require.Equal(t, 2, verifyCalls)
+
+	// the IP header is ignored unless set by a trusted proxy
+	require.Equal(t, "1.2.3.4", serve("1.2.3.4:1234", "5.6.7.8", "").ip)
+
+	// the client can prepend anything, the rightmost untrusted hop is used
+	require.Equal(t, "5.6.7.8", serve("10.0.0.1:1234", "9.9.9.9, 5.6.7.8", "").ip)
+	require.Equal(t, "5.6.7.8", serve("10.0.0.1:1234", "9.9.9.9, 5.6.7.8, 192.168.1.1", "").ip)
+
+	// malformed hops stop the walk at the last trusted address
+	require.Equal(t, "10.0.0.1", serve("10.0.0.1:1234", "5.6.7.8, junk", "").ip)
+}
+
+func TestRateLimitedErrorCode(t *testing.T) {
+	h := &clientInfoHandler{
+		next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			msg := r.URL.Query().Get("msg")
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"error":{"code":1,"message":%q}}`+"\n", msg)
+		}),
+	}
+
+	serve := func(msg string) (int, rpcResponse) {
+		r := httptest.NewRequest("POST", "/rpc/v0?msg="+url.QueryEscape(msg), strings.NewReader(""))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		var resp rpcResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	status, resp := serve(fmt.Errorf("%w: method ChainHead, retry in 1s", ErrRateLimited).Error())
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Equal(t, RateLimitedErrorCode, resp.Error.Code)
+	require.Equal(t, int64(1), resp.ID)
+
+	// errors returned by the node are left alone
+	status, resp = serve("actor not found")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 1, resp.Error.Code)
+	require.Equal(t, "actor not found", resp.Error.Message)
+}
@@ -0,0 +1,164 @@
+package sectorstorage
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// AffinityRule restricts the workers tasks can be scheduled on, based on the
+// tags of the workers. Tags are either plain labels (e.g. "fast-nvme") or
+// key=value pairs (e.g. "rack=a").
+type AffinityRule struct {
+	// Short names of the task types the rule applies to, e.g. "PC1"
+	Tasks []string
+
+	// RequireTags only allows workers carrying all of these tags
+	RequireTags []string
+
+	// SameTag makes all the tasks of a sector covered by the rule run on
+	// workers with the same value of this tag key, e.g. "rack". The first
+	// task of the sector placed on a worker with the tag pins the value, from
+	// then on workers without the tag aren't used for the sector either. Tasks
+	// placed on workers without the tag before that don't pin anything.
+	//
+	// Placements are only kept in memory until the sector is finalized or
+	// removed, after a restart the next task of the sector pins a new value.
+	SameTag string
+}
+
+func tagValue(info storiface.WorkerInfo, key string) (string, bool) {
+	for _, tag := range info.Tags {
+		kv := strings.SplitN(tag, "=", 2)
+		if kv[0] != key {
+			continue
+		}
+		if len(kv) == 1 {
+			return "", true
+		}
+		return kv[1], true
+	}
+	return "", false
+}
+
+func hasTag(info storiface.WorkerInfo, tag string) bool {
+	for _, t := range info.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+type affinityRule struct {
+	tasks       map[sealtasks.TaskType]struct{}
+	requireTags []string
+	sameTag     string
+}
+
+// affinityTracker applies the affinity rules, remembering where the tasks of
+// each sector covered by a SameTag rule were placed.
+type affinityTracker struct {
+	rules []affinityRule
+
+	lk sync.Mutex
+	// sector -> rule index -> tag value of the worker the first task ran on
+	placed map[abi.SectorID]map[int]string
+}
+
+func newAffinityTracker(rules []AffinityRule) (*affinityTracker, error) {
+	at := &affinityTracker{
+		placed: map[abi.SectorID]map[int]string{},
+	}
+
+	for i, r := range rules {
+		ar := affinityRule{
+			tasks:       map[sealtasks.TaskType]struct{}{},
+			requireTags: r.RequireTags,
+			sameTag:     r.SameTag,
+		}
+		for _, s := range r.Tasks {
+			tt, ok := sealtasks.ParseShort(s)
+			if !ok {
+				return nil, xerrors.Errorf("affinity rule %d: unknown task type %q", i, s)
+			}
+			ar.tasks[tt] = struct{}{}
+		}
+		at.rules = append(at.rules, ar)
+	}
+
+	return at, nil
+}
+
+// ok returns whether the rules allow running the task on the worker.
+func (at *affinityTracker) ok(tt sealtasks.TaskType, sector abi.SectorID, info storiface.WorkerInfo) bool {
+	at.lk.Lock()
+	defer at.lk.Unlock()
+
+	for ri, r := range at.rules {
+		if _, ok := r.tasks[tt]; !ok {
+			continue
+		}
+
+		for _, tag := range r.requireTags {
+			if !hasTag(info, tag) {
+				return false
+			}
+		}
+
+		if r.sameTag != "" {
+			want, placed := at.placed[sector][ri]
+			if !placed {
+				continue
+			}
+			if v, ok := tagValue(info, r.sameTag); !ok || v != want {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// assigned records that the task was assigned to the worker.
+func (at *affinityTracker) assigned(tt sealtasks.TaskType, sector abi.SectorID, info storiface.WorkerInfo) {
+	at.lk.Lock()
+	defer at.lk.Unlock()
+
+	if tt == sealtasks.TTFinalize {
+		// the sector is sealed, later tasks can run anywhere
+		delete(at.placed, sector)
+	}
+
+	for ri, r := range at.rules {
+		if _, ok := r.tasks[tt]; !ok || r.sameTag == "" {
+			continue
+		}
+		if _, placed := at.placed[sector][ri]; placed {
+			continue
+		}
+
+		v, ok := tagValue(info, r.sameTag)
+		if !ok {
+			continue
+		}
+		if at.placed[sector] == nil {
+			at.placed[sector] = map[int]string{}
+		}
+		at.placed[sector][ri] = v
+	}
+}
+
+// forget drops the placements of a removed sector.
+func (at *affinityTracker) forget(sector abi.SectorID) {
+	at.lk.Lock()
+	defer at.lk.Unlock()
+
+	delete(at.placed, sector)
+}
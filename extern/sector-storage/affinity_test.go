@@ -0,0 +1,56 @@
+package sectorstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+func TestAffinityRules(t *testing.T) {
+	at, err := newAffinityTracker([]AffinityRule{
+		{Tasks: []string{"PC1", "PC2"}, SameTag: "rack"},
+		{Tasks: []string{"UNS"}, RequireTags: []string{"fast-nvme"}},
+	})
+	require.NoError(t, err)
+
+	rackA := storiface.WorkerInfo{Tags: []string{"rack=a"}}
+	rackB := storiface.WorkerInfo{Tags: []string{"rack=b", "fast-nvme"}}
+	untagged := storiface.WorkerInfo{}
+
+	s1 := abi.SectorID{Miner: 1000, Number: 1}
+	s2 := abi.SectorID{Miner: 1000, Number: 2}
+
+	// nothing placed yet
+	require.True(t, at.ok(sealtasks.TTPreCommit1, s1, rackA))
+	require.True(t, at.ok(sealtasks.TTPreCommit1, s1, rackB))
+
+	at.assigned(sealtasks.TTPreCommit1, s1, rackA)
+	require.True(t, at.ok(sealtasks.TTPreCommit2, s1, rackA))
+	require.False(t, at.ok(sealtasks.TTPreCommit2, s1, rackB))
+	require.False(t, at.ok(sealtasks.TTPreCommit2, s1, untagged))
+	require.True(t, at.ok(sealtasks.TTPreCommit2, s2, rackB))
+
+	// tasks outside the rule aren't constrained
+	require.True(t, at.ok(sealtasks.TTCommit2, s1, rackB))
+
+	// finalizing forgets the placement
+	at.assigned(sealtasks.TTFinalize, s1, untagged)
+	require.True(t, at.ok(sealtasks.TTPreCommit2, s1, rackB))
+
+	// removing the sector forgets the placement too
+	at.assigned(sealtasks.TTPreCommit1, s2, rackA)
+	require.False(t, at.ok(sealtasks.TTPreCommit2, s2, rackB))
+	at.forget(s2)
+	require.True(t, at.ok(sealtasks.TTPreCommit2, s2, rackB))
+
+	require.True(t, at.ok(sealtasks.TTUnseal, s1, rackB))
+	require.False(t, at.ok(sealtasks.TTUnseal, s1, rackA))
+
+	_, err = newAffinityTracker([]AffinityRule{{Tasks: []string{"PC3"}}})
+	require.Error(t, err)
+}
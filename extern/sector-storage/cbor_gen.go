@@ -199,7 +199,7 @@ func (t *WorkState) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{166}); err != nil {
+	if _, err := w.Write([]byte{167}); err != nil {
 		return err
 	}
 
@@ -327,6 +327,22 @@ func (t *WorkState) MarshalCBOR(w io.Writer) error {
 			return err
 		}
 	}
+
+	// t.Sector (abi.SectorID) (struct)
+	if len("Sector") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Sector\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Sector"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Sector")); err != nil {
+		return err
+	}
+
+	if err := t.Sector.MarshalCBOR(w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -442,6 +458,16 @@ func (t *WorkState) UnmarshalCBOR(r io.Reader) error {
 
 				t.StartTime = int64(extraI)
 			}
+			// t.Sector (abi.SectorID) (struct)
+		case "Sector":
+
+			{
+
+				if err := t.Sector.UnmarshalCBOR(br); err != nil {
+					return xerrors.Errorf("unmarshaling t.Sector: %w", err)
+				}
+
+			}
 
 		default:
 			return fmt.Errorf("unknown struct field %d: '%s'", i, name)
@@ -572,3 +598,131 @@ func (t *WorkID) UnmarshalCBOR(r io.Reader) error {
 
 	return nil
 }
+func (t *SectorPriority) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Sector (abi.SectorID) (struct)
+	if len("Sector") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Sector\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Sector"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Sector")); err != nil {
+		return err
+	}
+
+	if err := t.Sector.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Priority (int64) (int64)
+	if len("Priority") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Priority\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Priority"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Priority")); err != nil {
+		return err
+	}
+
+	if t.Priority >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Priority)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.Priority-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *SectorPriority) UnmarshalCBOR(r io.Reader) error {
+	*t = SectorPriority{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("SectorPriority: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Sector (abi.SectorID) (struct)
+		case "Sector":
+
+			{
+
+				if err := t.Sector.UnmarshalCBOR(br); err != nil {
+					return xerrors.Errorf("unmarshaling t.Sector: %w", err)
+				}
+
+			}
+			// t.Priority (int64) (int64)
+		case "Priority":
+			{
+				maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Priority = int64(extraI)
+			}
+
+		default:
+			return fmt.Errorf("unknown struct field %d: '%s'", i, name)
+		}
+	}
+
+	return nil
+}
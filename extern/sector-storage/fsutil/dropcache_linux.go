@@ -0,0 +1,13 @@
+package fsutil
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// DropCache evicts the clean cached pages of the file, so that they are read
+// back from the disk. Dirty pages must be synced first.
+func DropCache(file *os.File) error {
+	return unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
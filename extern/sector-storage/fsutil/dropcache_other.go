@@ -0,0 +1,13 @@
+// +build !linux
+
+package fsutil
+
+import (
+	"os"
+)
+
+// DropCache is a no-op where the page cache can't be dropped per file, reads
+// of the file may still be served from memory.
+func DropCache(file *os.File) error {
+	return nil
+}
@@ -4,4 +4,8 @@ type FsStat struct {
 	Capacity  int64
 	Available int64 // Available to use for sector storage
 	Reserved  int64
+
+	// Set when the storage path has a quota
+	Max  int64 // MaxStorage of the path
+	Used int64 // Used by the path, counted against Max
 }
@@ -72,6 +72,7 @@ type Manager struct {
 	sched *scheduler
 
 	storage.Prover
+	postLk sync.Mutex // serializes window PoSt computed in the miner process
 
 	workLk sync.Mutex
 	work   *statestore.StateStore
@@ -82,6 +83,8 @@ type Manager struct {
 
 	results map[WorkID]result
 	waitRes map[WorkID]chan struct{}
+
+	scrub *scrubber
 }
 
 type result struct {
@@ -98,14 +101,21 @@ type SealerConfig struct {
 	AllowPreCommit2 bool
 	AllowCommit     bool
 	AllowUnseal     bool
+
+	// Tags of the local worker
+	LocalWorkerTags []string
+
+	// Affinity rules restricting which workers tasks are scheduled on
+	Affinity []AffinityRule
 }
 
 type StorageAuth http.Header
 
 type WorkerStateStore *statestore.StateStore
 type ManagerStateStore *statestore.StateStore
+type PriorityStateStore *statestore.StateStore
 
-func New(ctx context.Context, ls stores.LocalStorage, si stores.SectorIndex, sc SealerConfig, urls URLs, sa StorageAuth, wss WorkerStateStore, mss ManagerStateStore) (*Manager, error) {
+func New(ctx context.Context, ls stores.LocalStorage, si stores.SectorIndex, sc SealerConfig, urls URLs, sa StorageAuth, wss WorkerStateStore, mss ManagerStateStore, pss PriorityStateStore) (*Manager, error) {
 	lstor, err := stores.NewLocal(ctx, ls, si, urls)
 	if err != nil {
 		return nil, err
@@ -118,6 +128,11 @@ func New(ctx context.Context, ls stores.LocalStorage, si stores.SectorIndex, sc
 
 	stor := stores.NewRemote(lstor, si, http.Header(sa), sc.ParallelFetchLimit)
 
+	sched, err := newScheduler(pss)
+	if err != nil {
+		return nil, err
+	}
+
 	m := &Manager{
 		ls:         ls,
 		storage:    stor,
@@ -125,7 +140,7 @@ func New(ctx context.Context, ls stores.LocalStorage, si stores.SectorIndex, sc
 		remoteHnd:  &stores.FetchHandler{Local: lstor},
 		index:      si,
 
-		sched: newScheduler(),
+		sched: sched,
 
 		Prover: prover,
 
@@ -134,6 +149,13 @@ func New(ctx context.Context, ls stores.LocalStorage, si stores.SectorIndex, sc
 		callRes:    map[storiface.CallID]chan result{},
 		results:    map[WorkID]result{},
 		waitRes:    map[WorkID]chan struct{}{},
+
+		scrub: newScrubber(),
+	}
+
+	m.sched.affinity, err = newAffinityTracker(sc.Affinity)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing affinity rules: %w", err)
 	}
 
 	m.setupWorkTracker()
@@ -161,6 +183,7 @@ func New(ctx context.Context, ls stores.LocalStorage, si stores.SectorIndex, sc
 
 	err = m.AddWorker(ctx, NewLocalWorker(WorkerConfig{
 		TaskTypes: localTasks,
+		Tags:      sc.LocalWorkerTags,
 	}, stor, lstor, si, m, wss))
 	if err != nil {
 		return nil, xerrors.Errorf("adding local worker: %w", err)
@@ -187,6 +210,17 @@ func (m *Manager) AddLocalStorage(ctx context.Context, path string) error {
 	return nil
 }
 
+// MoveSectorStorage moves the files of a sector between two local storage
+// paths.
+func (m *Manager) MoveSectorStorage(ctx context.Context, sid abi.SectorID, from, to stores.ID) error {
+	return m.localStore.MoveSector(ctx, sid, from, to)
+}
+
+// SetStorageMeta updates the settings of a local storage path.
+func (m *Manager) SetStorageMeta(ctx context.Context, id stores.ID, upd stores.StorageMetaUpdate) error {
+	return m.localStore.SetPathMeta(ctx, id, upd)
+}
+
 func (m *Manager) AddWorker(ctx context.Context, w Worker) error {
 	return m.sched.runWorker(ctx, w)
 }
@@ -590,6 +624,10 @@ func (m *Manager) FinalizeSector(ctx context.Context, sector storage.SectorRef,
 		return xerrors.Errorf("moving sector to storage: %w", err)
 	}
 
+	if err := m.sched.clearPriority(sector.ID); err != nil {
+		log.Warnw("clearing sector priority", "sector", sector.ID, "error", err)
+	}
+
 	return nil
 }
 
@@ -617,6 +655,10 @@ func (m *Manager) Remove(ctx context.Context, sector storage.SectorRef) error {
 	if rerr := m.storage.Remove(ctx, sector.ID, storiface.FTUnsealed, true); rerr != nil {
 		err = multierror.Append(err, xerrors.Errorf("removing sector (unsealed): %w", rerr))
 	}
+	if rerr := m.sched.clearPriority(sector.ID); rerr != nil {
+		err = multierror.Append(err, rerr)
+	}
+	m.sched.affinity.forget(sector.ID)
 
 	return err
 }
@@ -665,6 +707,10 @@ func (m *Manager) ReturnFetch(ctx context.Context, callID storiface.CallID, err
 	return m.returnResult(callID, nil, err)
 }
 
+func (m *Manager) ReturnGenerateWindowPoSt(ctx context.Context, callID storiface.CallID, res storiface.WindowPoStResult, err *storiface.CallError) error {
+	return m.returnResult(callID, res, err)
+}
+
 func (m *Manager) StorageLocal(ctx context.Context) (map[stores.ID]string, error) {
 	l, err := m.localStore.Local(ctx)
 	if err != nil {
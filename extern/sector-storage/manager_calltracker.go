@@ -11,6 +11,9 @@ import (
 
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-storage/storage"
+
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 )
@@ -44,6 +47,8 @@ type WorkState struct {
 
 	WorkerHostname string // hostname of last worker handling this job
 	StartTime      int64  // unix seconds
+
+	Sector abi.SectorID
 }
 
 func newWorkID(method sealtasks.TaskType, params ...interface{}) (WorkID, error) {
@@ -101,8 +106,8 @@ func (m *Manager) setupWorkTracker() {
 }
 
 // returns wait=true when the task is already tracked/running
-func (m *Manager) getWork(ctx context.Context, method sealtasks.TaskType, params ...interface{}) (wid WorkID, wait bool, cancel func(), err error) {
-	wid, err = newWorkID(method, params)
+func (m *Manager) getWork(ctx context.Context, method sealtasks.TaskType, sector storage.SectorRef, params ...interface{}) (wid WorkID, wait bool, cancel func(), err error) {
+	wid, err = newWorkID(method, append([]interface{}{sector}, params...))
 	if err != nil {
 		return WorkID{}, false, nil, xerrors.Errorf("creating WorkID: %w", err)
 	}
@@ -119,6 +124,7 @@ func (m *Manager) getWork(ctx context.Context, method sealtasks.TaskType, params
 		err := m.work.Begin(wid, &WorkState{
 			ID:     wid,
 			Status: wsStarted,
+			Sector: sector.ID,
 		})
 		if err != nil {
 			return WorkID{}, false, nil, xerrors.Errorf("failed to track task start: %w", err)
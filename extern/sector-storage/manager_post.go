@@ -0,0 +1,110 @@
+package sectorstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
+	"github.com/filecoin-project/specs-storage/storage"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// WindowPoStWorkerTimeout is the longest a worker is given to compute a window
+// PoSt before the proof is computed locally instead. When the proving context
+// has a deadline, the worker gets at most WindowPoStWorkerShare of the time
+// left, so that there is still time to prove locally.
+var WindowPoStWorkerTimeout = 15 * time.Minute
+var WindowPoStWorkerShare = 0.5
+
+// postSector returns the sector a window PoSt is scheduled and tracked as,
+// the scheduler and the call trackers work on single sectors
+func postSector(minerID abi.ActorID, sectorInfo []proof2.SectorInfo) storage.SectorRef {
+	return storage.SectorRef{
+		ID: abi.SectorID{
+			Miner:  minerID,
+			Number: sectorInfo[0].SectorNumber,
+		},
+		ProofType: sectorInfo[0].SealProof,
+	}
+}
+
+// GenerateWindowPoSt computes the proof on a worker with the window PoSt task
+// enabled, which can read all the sectors from its own paths. The proof is
+// computed in the miner process when there is no such worker, or when the
+// worker failed for reasons other than unprovable sectors.
+func (m *Manager) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) ([]proof2.PoStProof, []abi.SectorID, error) {
+	if len(sectorInfo) > 0 {
+		proofs, skipped, done, err := m.generateWindowPoStRemote(ctx, minerID, sectorInfo, randomness)
+		if done {
+			return proofs, skipped, err
+		}
+		if err != nil {
+			log.Warnw("window PoSt on worker failed, proving locally", "sectors", len(sectorInfo), "error", err)
+		}
+	}
+
+	// proofs use all the cores / GPUs, running more than one at a time only
+	// slows all of them down
+	m.postLk.Lock()
+	defer m.postLk.Unlock()
+
+	return m.Prover.GenerateWindowPoSt(ctx, minerID, sectorInfo, randomness)
+}
+
+// generateWindowPoStRemote returns false when the proof should be computed
+// locally instead
+func (m *Manager) generateWindowPoStRemote(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) ([]proof2.PoStProof, []abi.SectorID, bool, error) {
+	sector := postSector(minerID, sectorInfo)
+
+	ids := make([]abi.SectorID, len(sectorInfo))
+	for i, si := range sectorInfo {
+		ids[i] = abi.SectorID{Miner: minerID, Number: si.SectorNumber}
+	}
+
+	need, err := findPoStFiles(ctx, m.index, sector.ProofType, ids)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	selector := newPoStSelector(need)
+	if !m.sched.canSchedule(sector, sealtasks.TTGenerateWindowPoSt, func(whnd *workerHandle) bool {
+		return selector.okCached(sealtasks.TTGenerateWindowPoSt, sector.ProofType, whnd)
+	}) {
+		return nil, nil, false, nil
+	}
+
+	// a busy or disconnected worker must not use up the time needed to prove
+	// locally
+	timeout := WindowPoStWorkerTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Duration(float64(time.Until(deadline)) * WindowPoStWorkerShare); left < timeout {
+			timeout = left
+		}
+	}
+	rctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var res storiface.WindowPoStResult
+	err = m.sched.Schedule(rctx, sector, sealtasks.TTGenerateWindowPoSt, selector, schedNop, func(ctx context.Context, w Worker) error {
+		r, err := m.waitSimpleCall(ctx)(w.GenerateWindowPoSt(ctx, minerID, sectorInfo, randomness))
+		if r != nil {
+			res = r.(storiface.WindowPoStResult)
+		}
+		return err
+	})
+	if err == nil {
+		return res.Proofs, nil, true, nil
+	}
+
+	// the caller retries without the skipped sectors, and there's no point in
+	// proving locally after the deadline is gone; when only the worker timed
+	// out, the proof is computed locally
+	if len(res.Skipped) > 0 || ctx.Err() != nil {
+		return nil, res.Skipped, true, err
+	}
+
+	return nil, nil, false, err
+}
@@ -21,10 +21,12 @@ import (
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-statestore"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 	"github.com/filecoin-project/specs-storage/storage"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/ffiwrapper"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/fsutil"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/mock"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/stores"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
@@ -100,6 +102,9 @@ func newTestMgr(ctx context.Context, t *testing.T, ds datastore.Datastore) (*Man
 
 	stor := stores.NewRemote(lstor, si, nil, 6000)
 
+	sched, err := newScheduler(statestore.New(datastore.NewMapDatastore()))
+	require.NoError(t, err)
+
 	m := &Manager{
 		ls:         st,
 		storage:    stor,
@@ -107,7 +112,7 @@ func newTestMgr(ctx context.Context, t *testing.T, ds datastore.Datastore) (*Man
 		remoteHnd:  &stores.FetchHandler{Local: lstor},
 		index:      si,
 
-		sched: newScheduler(),
+		sched: sched,
 
 		Prover: prover,
 
@@ -116,6 +121,8 @@ func newTestMgr(ctx context.Context, t *testing.T, ds datastore.Datastore) (*Man
 		callRes:    map[storiface.CallID]chan result{},
 		results:    map[WorkID]result{},
 		waitRes:    map[WorkID]chan struct{}{},
+
+		scrub: newScrubber(),
 	}
 
 	m.setupWorkTracker()
@@ -453,3 +460,90 @@ func TestReenableWorker(t *testing.T) {
 	i, _ = m.sched.Info(ctx)
 	require.Len(t, i.(SchedDiagInfo).OpenWindows, 2)
 }
+
+func TestWindowPoStOnWorker(t *testing.T) {
+	logging.SetAllLoggers(logging.LevelDebug)
+
+	ctx := context.Background()
+	m, lstor, _, idx, cleanup := newTestMgr(ctx, t, datastore.NewMapDatastore())
+	defer cleanup()
+
+	s1 := abi.SectorID{Miner: 1000, Number: 1}
+	s2 := abi.SectorID{Miner: 1000, Number: 2}
+
+	tw := newTestWorker(WorkerConfig{
+		TaskTypes: []sealtasks.TaskType{sealtasks.TTGenerateWindowPoSt},
+	}, lstor, m)
+	tw.mockSeal = mock.NewMockSectorMgr([]abi.SectorID{s1})
+
+	err := m.AddWorker(ctx, tw)
+	require.NoError(t, err)
+
+	paths, err := lstor.Local(ctx)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+
+	for _, sid := range []abi.SectorID{s1, s2} {
+		require.NoError(t, idx.StorageDeclareSector(ctx, paths[0].ID, sid, storiface.FTSealed, true))
+		require.NoError(t, idx.StorageDeclareSector(ctx, paths[0].ID, sid, storiface.FTCache, true))
+	}
+
+	sinfo := func(sid abi.SectorID) proof2.SectorInfo {
+		return proof2.SectorInfo{
+			SealProof:    abi.RegisteredSealProof_StackedDrg2KiBV1,
+			SectorNumber: sid.Number,
+		}
+	}
+	rand := abi.PoStRandomness{9, 9, 9, 9}
+
+	proofs, skipped, err := m.GenerateWindowPoSt(ctx, 1000, []proof2.SectorInfo{sinfo(s1)}, rand)
+	require.NoError(t, err)
+	require.Len(t, proofs, 1)
+	require.Empty(t, skipped)
+	require.Equal(t, 1, tw.posts)
+
+	// sectors the worker can't prove are reported back to the caller
+	_, skipped, err = m.GenerateWindowPoSt(ctx, 1000, []proof2.SectorInfo{sinfo(s1), sinfo(s2)}, rand)
+	require.Error(t, err)
+	require.Equal(t, []abi.SectorID{s2}, skipped)
+	require.Equal(t, 2, tw.posts)
+}
+
+func TestWindowPoStWorkerTimeout(t *testing.T) {
+	logging.SetAllLoggers(logging.LevelDebug)
+
+	defer func(timeout time.Duration) {
+		WindowPoStWorkerTimeout = timeout
+	}(WindowPoStWorkerTimeout)
+	WindowPoStWorkerTimeout = 100 * time.Millisecond
+
+	ctx := context.Background()
+	m, lstor, _, idx, cleanup := newTestMgr(ctx, t, datastore.NewMapDatastore())
+	defer cleanup()
+
+	s1 := abi.SectorID{Miner: 1000, Number: 1}
+	m.Prover = mock.NewMockSectorMgr([]abi.SectorID{s1})
+
+	tw := newTestWorker(WorkerConfig{
+		TaskTypes: []sealtasks.TaskType{sealtasks.TTGenerateWindowPoSt},
+	}, lstor, m)
+	tw.stallPoSt = true
+
+	require.NoError(t, m.AddWorker(ctx, tw))
+
+	paths, err := lstor.Local(ctx)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.NoError(t, idx.StorageDeclareSector(ctx, paths[0].ID, s1, storiface.FTSealed, true))
+	require.NoError(t, idx.StorageDeclareSector(ctx, paths[0].ID, s1, storiface.FTCache, true))
+
+	// the worker never returns, the proof is computed locally
+	proofs, skipped, err := m.GenerateWindowPoSt(ctx, 1000, []proof2.SectorInfo{{
+		SealProof:    abi.RegisteredSealProof_StackedDrg2KiBV1,
+		SectorNumber: s1.Number,
+	}}, abi.PoStRandomness{9, 9, 9, 9})
+	require.NoError(t, err)
+	require.Len(t, proofs, 1)
+	require.Empty(t, skipped)
+	require.Equal(t, 1, tw.posts)
+}
@@ -1,6 +1,10 @@
 package sectorstorage
 
-import "sort"
+import (
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
 
 type requestQueue []*workerRequest
 
@@ -48,3 +52,13 @@ func (q *requestQueue) Remove(i int) *workerRequest {
 	sort.Sort(q)
 	return item
 }
+
+// reprioritize sets the priority of the queued requests for a sector.
+func (q *requestQueue) reprioritize(sector abi.SectorID, priority int) {
+	for _, req := range *q {
+		if req.sector.ID == sector {
+			req.priority = priority
+		}
+	}
+	sort.Sort(q)
+}
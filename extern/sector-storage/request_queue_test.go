@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-storage/storage"
+
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
 )
 
@@ -60,3 +65,24 @@ func TestRequestQueue(t *testing.T) {
 		t.Error("expected precommit1, got", pt.taskType)
 	}
 }
+
+func TestRequestQueueReprioritize(t *testing.T) {
+	rq := &requestQueue{}
+
+	sector := func(n abi.SectorNumber) storage.SectorRef {
+		return storage.SectorRef{ID: abi.SectorID{Miner: 1000, Number: n}}
+	}
+
+	rq.Push(&workerRequest{taskType: sealtasks.TTPreCommit1, sector: sector(1)})
+	rq.Push(&workerRequest{taskType: sealtasks.TTPreCommit1, sector: sector(2)})
+	rq.Push(&workerRequest{taskType: sealtasks.TTPreCommit1, sector: sector(3)})
+
+	require.Equal(t, abi.SectorNumber(1), (*rq)[0].sector.ID.Number)
+
+	rq.reprioritize(abi.SectorID{Miner: 1000, Number: 3}, 10)
+
+	require.Equal(t, abi.SectorNumber(3), (*rq)[0].sector.ID.Number)
+	require.Equal(t, 10, (*rq)[0].priority)
+	require.Equal(t, 0, (*rq)[0].index)
+	require.Equal(t, abi.SectorNumber(1), (*rq)[1].sector.ID.Number)
+}
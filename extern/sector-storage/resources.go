@@ -4,6 +4,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 )
 
 type Resources struct {
@@ -14,6 +15,8 @@ type Resources struct {
 	CanGPU         bool
 
 	BaseMinMemory uint64 // What Must be in RAM for decent perf (shared between threads)
+
+	MaxConcurrent int // Tasks of the type allowed to run at once on a worker, 0 = no limit
 }
 
 /*
@@ -262,6 +265,53 @@ var ResourceTable = map[sealtasks.TaskType]map[abi.RegisteredSealProof]Resources
 			BaseMinMemory: 8 << 20,
 		},
 	},
+	sealtasks.TTGenerateWindowPoSt: {
+		abi.RegisteredSealProof_StackedDrg64GiBV1: Resources{
+			MaxMemory: 120 << 30,
+			MinMemory: 60 << 30,
+
+			MaxParallelism: -1,
+			CanGPU:         true,
+
+			BaseMinMemory: 64 << 30, // params
+		},
+		abi.RegisteredSealProof_StackedDrg32GiBV1: Resources{
+			MaxMemory: 96 << 30,
+			MinMemory: 30 << 30,
+
+			MaxParallelism: -1,
+			CanGPU:         true,
+
+			BaseMinMemory: 32 << 30, // params
+		},
+		abi.RegisteredSealProof_StackedDrg512MiBV1: Resources{
+			MaxMemory: 3 << 29, // 1.5G
+			MinMemory: 1 << 30,
+
+			MaxParallelism: -1,
+			CanGPU:         true,
+
+			BaseMinMemory: 10 << 30,
+		},
+		abi.RegisteredSealProof_StackedDrg2KiBV1: Resources{
+			MaxMemory: 2 << 10,
+			MinMemory: 2 << 10,
+
+			MaxParallelism: -1,
+			CanGPU:         true,
+
+			BaseMinMemory: 2 << 10,
+		},
+		abi.RegisteredSealProof_StackedDrg8MiBV1: Resources{
+			MaxMemory: 8 << 20,
+			MinMemory: 8 << 20,
+
+			MaxParallelism: -1,
+			CanGPU:         true,
+
+			BaseMinMemory: 8 << 20,
+		},
+	},
 	sealtasks.TTFetch: {
 		abi.RegisteredSealProof_StackedDrg64GiBV1: Resources{
 			MaxMemory: 1 << 20,
@@ -324,3 +374,36 @@ func init() {
 		m[abi.RegisteredSealProof_StackedDrg64GiBV1_1] = m[abi.RegisteredSealProof_StackedDrg64GiBV1]
 	}
 }
+
+// TaskResources returns the resources a task needs on a worker, applying the
+// overrides advertised by the worker to the ResourceTable.
+func TaskResources(info storiface.WorkerInfo, tt sealtasks.TaskType, spt abi.RegisteredSealProof) Resources {
+	res := ResourceTable[tt][spt]
+
+	o, ok := info.TaskResources[tt]
+	if !ok {
+		return res
+	}
+
+	if o.MinMemory != 0 {
+		res.MinMemory = o.MinMemory
+	}
+	if o.MaxMemory != 0 {
+		res.MaxMemory = o.MaxMemory
+	}
+	if res.MaxMemory < res.MinMemory {
+		res.MaxMemory = res.MinMemory
+	}
+	if o.BaseMinMemory != 0 {
+		res.BaseMinMemory = o.BaseMinMemory
+	}
+	if o.MaxParallelism != 0 {
+		res.MaxParallelism = o.MaxParallelism
+	}
+	if o.CanGPU != nil {
+		res.CanGPU = *o.CanGPU
+	}
+	res.MaxConcurrent = o.MaxConcurrent
+
+	return res
+}
@@ -11,9 +11,11 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-statestore"
 	"github.com/filecoin-project/specs-storage/storage"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/stores"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 )
 
@@ -59,12 +61,18 @@ type scheduler struct {
 	windowRequests chan *schedWindowRequest
 	workerChange   chan struct{} // worker added / changed/freed resources
 	workerDisable  chan workerDisableReq
+	prioChange     chan abi.SectorID
+
+	prioLk    sync.Mutex
+	prio      map[abi.SectorID]int // sector priority overrides
+	prioStore *statestore.StateStore
 
 	// owned by the sh.runSched goroutine
 	schedQueue  *requestQueue
 	openWindows []*schedWindowRequest
 
 	workTracker *workTracker
+	affinity    *affinityTracker
 
 	info chan func(interface{})
 
@@ -78,6 +86,11 @@ type workerHandle struct {
 
 	info storiface.WorkerInfo
 
+	// task types and storage paths of the worker, refreshed on heartbeats;
+	// guarded by workersLk
+	tasks map[sealtasks.TaskType]struct{}
+	paths []stores.StoragePath
+
 	preparing *activeResources
 	active    *activeResources
 
@@ -116,6 +129,7 @@ type activeResources struct {
 	memUsedMax uint64
 	gpuUsed    bool
 	cpuUse     uint64
+	tasks      map[sealtasks.TaskType]int
 
 	cond *sync.Cond
 }
@@ -142,27 +156,45 @@ type workerResponse struct {
 	err error
 }
 
-func newScheduler() *scheduler {
-	return &scheduler{
+func newScheduler(prio *statestore.StateStore) (*scheduler, error) {
+	var overrides []SectorPriority
+	if err := prio.List(&overrides); err != nil {
+		return nil, xerrors.Errorf("loading sector priorities: %w", err)
+	}
+
+	sh := &scheduler{
 		workers: map[WorkerID]*workerHandle{},
 
 		schedule:       make(chan *workerRequest),
 		windowRequests: make(chan *schedWindowRequest, 20),
 		workerChange:   make(chan struct{}, 20),
 		workerDisable:  make(chan workerDisableReq),
+		prioChange:     make(chan abi.SectorID),
+
+		prio:      map[abi.SectorID]int{},
+		prioStore: prio,
 
 		schedQueue: &requestQueue{},
 
 		workTracker: &workTracker{
-			done:    map[storiface.CallID]struct{}{},
-			running: map[storiface.CallID]trackedWork{},
+			done:      map[storiface.CallID]struct{}{},
+			running:   map[storiface.CallID]trackedWork{},
+			durations: map[sealtasks.TaskType]time.Duration{},
 		},
 
+		affinity: &affinityTracker{placed: map[abi.SectorID]map[int]string{}},
+
 		info: make(chan func(interface{})),
 
 		closing: make(chan struct{}),
 		closed:  make(chan struct{}),
 	}
+
+	for _, o := range overrides {
+		sh.prio[o.Sector] = int(o.Priority)
+	}
+
+	return sh, nil
 }
 
 func (sh *scheduler) Schedule(ctx context.Context, sector storage.SectorRef, taskType sealtasks.TaskType, sel WorkerSelector, prepare WorkerAction, work WorkerAction) error {
@@ -172,7 +204,7 @@ func (sh *scheduler) Schedule(ctx context.Context, sector storage.SectorRef, tas
 	case sh.schedule <- &workerRequest{
 		sector:   sector,
 		taskType: taskType,
-		priority: getPriority(ctx),
+		priority: sh.sectorPriority(ctx, sector.ID),
 		sel:      sel,
 
 		prepare: prepare,
@@ -199,6 +231,26 @@ func (sh *scheduler) Schedule(ctx context.Context, sector storage.SectorRef, tas
 	}
 }
 
+// canSchedule returns whether any of the connected workers accepts the task,
+// for callers which can't wait for a suitable worker to show up. Workers are
+// checked against their cached state only, see workerHandle.tasks.
+func (sh *scheduler) canSchedule(sector storage.SectorRef, taskType sealtasks.TaskType, ok func(whnd *workerHandle) bool) bool {
+	sh.workersLk.RLock()
+	defer sh.workersLk.RUnlock()
+
+	for _, worker := range sh.workers {
+		if !worker.enabled || !sh.affinity.ok(taskType, sector.ID, worker.info) {
+			continue
+		}
+
+		if ok(worker) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (r *workerRequest) respond(err error) {
 	select {
 	case r.ret <- workerResponse{err: err}:
@@ -211,6 +263,7 @@ type SchedDiagRequestInfo struct {
 	Sector   abi.SectorID
 	TaskType sealtasks.TaskType
 	Priority int
+	Start    time.Time
 }
 
 type SchedDiagInfo struct {
@@ -244,6 +297,11 @@ func (sh *scheduler) runSched() {
 		case req := <-sh.windowRequests:
 			sh.openWindows = append(sh.openWindows, req)
 			doSched = true
+		case sector := <-sh.prioChange:
+			if p, ok := sh.priorityOverride(sector); ok {
+				sh.schedQueue.reprioritize(sector, p)
+			}
+			doSched = true
 		case ireq := <-sh.info:
 			ireq(sh.diag())
 
@@ -315,6 +373,7 @@ func (sh *scheduler) diag() SchedDiagInfo {
 			Sector:   task.sector.ID,
 			TaskType: task.taskType,
 			Priority: task.priority,
+			Start:    task.start,
 		})
 	}
 
@@ -376,7 +435,6 @@ func (sh *scheduler) trySched() {
 			}()
 
 			task := (*sh.schedQueue)[sqi]
-			needRes := ResourceTable[task.taskType][task.sector.ProofType]
 
 			task.indexHeap = sqi
 			for wnd, windowRequest := range sh.openWindows {
@@ -392,8 +450,14 @@ func (sh *scheduler) trySched() {
 					continue
 				}
 
+				if !sh.affinity.ok(task.taskType, task.sector.ID, worker.info) {
+					continue
+				}
+
+				needRes := TaskResources(worker.info, task.taskType, task.sector.ProofType)
+
 				// TODO: allow bigger windows
-				if !windows[wnd].allocated.canHandleRequest(needRes, windowRequest.worker, "schedAcceptable", worker.info.Resources) {
+				if !windows[wnd].allocated.canHandleRequest(needRes, task.taskType, windowRequest.worker, "schedAcceptable", worker.info.Resources) {
 					continue
 				}
 
@@ -455,23 +519,25 @@ func (sh *scheduler) trySched() {
 
 	for sqi := 0; sqi < queuneLen; sqi++ {
 		task := (*sh.schedQueue)[sqi]
-		needRes := ResourceTable[task.taskType][task.sector.ProofType]
 
 		selectedWindow := -1
 		for _, wnd := range acceptableWindows[task.indexHeap] {
 			wid := sh.openWindows[wnd].worker
-			wr := sh.workers[wid].info.Resources
+			info := sh.workers[wid].info
+			wr := info.Resources
+			needRes := TaskResources(info, task.taskType, task.sector.ProofType)
 
 			log.Debugf("SCHED try assign sqi:%d sector %d to window %d", sqi, task.sector.ID.Number, wnd)
 
 			// TODO: allow bigger windows
-			if !windows[wnd].allocated.canHandleRequest(needRes, wid, "schedAssign", wr) {
+			if !windows[wnd].allocated.canHandleRequest(needRes, task.taskType, wid, "schedAssign", wr) {
 				continue
 			}
 
 			log.Debugf("SCHED ASSIGNED sqi:%d sector %d task %s to window %d", sqi, task.sector.ID.Number, task.taskType, wnd)
 
-			windows[wnd].allocated.add(wr, needRes)
+			windows[wnd].allocated.add(wr, task.taskType, needRes)
+			sh.affinity.assigned(task.taskType, task.sector.ID, info)
 			// TODO: We probably want to re-sort acceptableWindows here based on new
 			//  workerHandle.utilization + windows[wnd].allocated.utilization (workerHandle.utilization is used in all
 			//  task selectors, but not in the same way, so need to figure out how to do that in a non-O(n^2 way), and
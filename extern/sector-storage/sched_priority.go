@@ -0,0 +1,145 @@
+package sectorstorage
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// SectorPriority is a persisted sector priority override.
+type SectorPriority struct {
+	Sector   abi.SectorID
+	Priority int64
+}
+
+// sectorPriority returns the priority of a task for the given sector, which
+// is the sector priority override when one was set and the context priority
+// otherwise.
+func (sh *scheduler) sectorPriority(ctx context.Context, sector abi.SectorID) int {
+	if p, ok := sh.priorityOverride(sector); ok {
+		return p
+	}
+	return getPriority(ctx)
+}
+
+func (sh *scheduler) priorityOverride(sector abi.SectorID) (int, bool) {
+	sh.prioLk.Lock()
+	defer sh.prioLk.Unlock()
+
+	p, ok := sh.prio[sector]
+	return p, ok
+}
+
+// setPriority overrides the priority of the tasks of a sector, including the
+// ones already queued. The override is persisted, and kept until the sector is
+// finalized or removed.
+func (sh *scheduler) setPriority(ctx context.Context, sector abi.SectorID, priority int) error {
+	sh.prioLk.Lock()
+	err := sh.savePriority(sector, priority)
+	if err == nil {
+		sh.prio[sector] = priority
+	}
+	sh.prioLk.Unlock()
+	if err != nil {
+		return xerrors.Errorf("saving sector priority: %w", err)
+	}
+
+	select {
+	case sh.prioChange <- sector:
+		return nil
+	case <-sh.closing:
+		return xerrors.New("closing")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// savePriority persists a priority override, the caller must hold prioLk
+func (sh *scheduler) savePriority(sector abi.SectorID, priority int) error {
+	key := storiface.SectorName(sector)
+
+	has, err := sh.prioStore.Has(key)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return sh.prioStore.Begin(key, &SectorPriority{Sector: sector, Priority: int64(priority)})
+	}
+
+	return sh.prioStore.Get(key).Mutate(func(sp *SectorPriority) error {
+		sp.Priority = int64(priority)
+		return nil
+	})
+}
+
+// clearPriority drops the priority override of a sector which won't get any
+// more sealing tasks.
+func (sh *scheduler) clearPriority(sector abi.SectorID) error {
+	sh.prioLk.Lock()
+	defer sh.prioLk.Unlock()
+
+	if _, ok := sh.prio[sector]; !ok {
+		return nil
+	}
+
+	if err := sh.prioStore.Get(storiface.SectorName(sector)).End(); err != nil {
+		return xerrors.Errorf("removing sector priority: %w", err)
+	}
+	delete(sh.prio, sector)
+	return nil
+}
+
+// SetSectorPriority overrides the scheduling priority of the tasks of a
+// sector, including the ones already queued. The override is persisted until
+// the sector is finalized or removed, so that tasks queued after a restart
+// keep it.
+func (m *Manager) SetSectorPriority(ctx context.Context, sector abi.SectorID, priority int) error {
+	return m.sched.setPriority(ctx, sector, priority)
+}
+
+// SchedQueue lists the tasks waiting in the scheduler queue, in scheduling
+// order.
+func (m *Manager) SchedQueue(ctx context.Context) ([]storiface.SchedQueueEntry, error) {
+	si, err := m.sched.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]storiface.SchedQueueEntry, 0, len(si.(SchedDiagInfo).Requests))
+	ahead := map[sealtasks.TaskType]int{}
+	for _, req := range si.(SchedDiagInfo).Requests {
+		out = append(out, storiface.SchedQueueEntry{
+			Sector:   req.Sector,
+			Task:     req.TaskType,
+			Priority: req.Priority,
+			Queued:   req.Start,
+		})
+	}
+
+	// Rough wait estimate: the tasks of the same type ahead in the queue and
+	// those running are processed as many at a time as are running now, each
+	// taking the average time tasks of this type took recently.
+	for i := range out {
+		tt := out[i].Task
+		avg, running := m.sched.workTracker.taskStats(tt)
+
+		n := ahead[tt] + running
+		ahead[tt]++
+
+		if avg == 0 {
+			continue
+		}
+		if running == 0 {
+			running = 1
+		}
+		out[i].EstWait = avg * time.Duration(n) / time.Duration(running)
+	}
+
+	return out, nil
+}
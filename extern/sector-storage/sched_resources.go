@@ -3,22 +3,25 @@ package sectorstorage
 import (
 	"sync"
 
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 )
 
-func (a *activeResources) withResources(id WorkerID, wr storiface.WorkerResources, r Resources, locker sync.Locker, cb func() error) error {
-	for !a.canHandleRequest(r, id, "withResources", wr) {
+func (a *activeResources) withResources(id WorkerID, wr storiface.WorkerResources, tt sealtasks.TaskType, r Resources, locker sync.Locker, cb func() error) error {
+	for !a.canHandleRequest(r, tt, id, "withResources", wr) {
 		if a.cond == nil {
 			a.cond = sync.NewCond(locker)
 		}
 		a.cond.Wait()
 	}
 
-	a.add(wr, r)
+	a.add(wr, tt, r)
 
 	err := cb()
 
-	a.free(wr, r)
+	a.free(wr, tt, r)
 	if a.cond != nil {
 		a.cond.Broadcast()
 	}
@@ -26,25 +29,34 @@ func (a *activeResources) withResources(id WorkerID, wr storiface.WorkerResource
 	return err
 }
 
-func (a *activeResources) add(wr storiface.WorkerResources, r Resources) {
+func (a *activeResources) add(wr storiface.WorkerResources, tt sealtasks.TaskType, r Resources) {
 	if r.CanGPU {
 		a.gpuUsed = true
 	}
 	a.cpuUse += r.Threads(wr.CPUs)
 	a.memUsedMin += r.MinMemory
 	a.memUsedMax += r.MaxMemory
+	if a.tasks == nil {
+		a.tasks = map[sealtasks.TaskType]int{}
+	}
+	a.tasks[tt]++
 }
 
-func (a *activeResources) free(wr storiface.WorkerResources, r Resources) {
+func (a *activeResources) free(wr storiface.WorkerResources, tt sealtasks.TaskType, r Resources) {
 	if r.CanGPU {
 		a.gpuUsed = false
 	}
 	a.cpuUse -= r.Threads(wr.CPUs)
 	a.memUsedMin -= r.MinMemory
 	a.memUsedMax -= r.MaxMemory
+	a.tasks[tt]--
 }
 
-func (a *activeResources) canHandleRequest(needRes Resources, wid WorkerID, caller string, res storiface.WorkerResources) bool {
+func (a *activeResources) canHandleRequest(needRes Resources, tt sealtasks.TaskType, wid WorkerID, caller string, res storiface.WorkerResources) bool {
+	if needRes.MaxConcurrent > 0 && a.tasks[tt] >= needRes.MaxConcurrent {
+		log.Debugf("sched: not scheduling on worker %s for %s; %d %s tasks running, limit %d", wid, caller, a.tasks[tt], tt.Short(), needRes.MaxConcurrent)
+		return false
+	}
 
 	// TODO: dedupe needRes.BaseMinMemory per task type (don't add if that task is already running)
 	minNeedMem := res.MemReserved + a.memUsedMin + needRes.MinMemory + needRes.BaseMinMemory
@@ -75,6 +87,13 @@ func (a *activeResources) canHandleRequest(needRes Resources, wid WorkerID, call
 	return true
 }
 
+// canFit returns whether the worker can run the task when idle, which isn't
+// the case if the resources it needs exceed what the worker has.
+func (wh *workerHandle) canFit(tt sealtasks.TaskType, spt abi.RegisteredSealProof) bool {
+	var idle activeResources
+	return idle.canHandleRequest(TaskResources(wh.info, tt, spt), tt, WorkerID{}, "canFit "+wh.info.Hostname, wh.info.Resources)
+}
+
 func (a *activeResources) utilization(wr storiface.WorkerResources) float64 {
 	var max float64
 
@@ -12,10 +12,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-statestore"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/fsutil"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
@@ -99,6 +102,10 @@ func (s *schedTestWorker) ReadPiece(ctx context.Context, writer io.Writer, id st
 	panic("implement me")
 }
 
+func (s *schedTestWorker) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (storiface.CallID, error) {
+	panic("implement me")
+}
+
 func (s *schedTestWorker) TaskTypes(ctx context.Context) (map[sealtasks.TaskType]struct{}, error) {
 	return s.taskTypes, nil
 }
@@ -164,8 +171,39 @@ func addTestWorker(t *testing.T, sched *scheduler, index *stores.Index, name str
 	require.NoError(t, sched.runWorker(context.TODO(), w))
 }
 
+func newTestScheduler(t testing.TB) *scheduler {
+	sched, err := newScheduler(statestore.New(datastore.NewMapDatastore()))
+	require.NoError(t, err)
+	return sched
+}
+
+func TestSchedPriorityPersisted(t *testing.T) {
+	ctx := context.Background()
+	ds := datastore.NewMapDatastore()
+	sector := abi.SectorID{Miner: 1000, Number: 1}
+
+	sched, err := newScheduler(statestore.New(ds))
+	require.NoError(t, err)
+	go sched.runSched()
+
+	require.NoError(t, sched.setPriority(ctx, sector, 20))
+	require.NoError(t, sched.setPriority(ctx, sector, 30))
+	require.NoError(t, sched.Close(ctx))
+
+	sched, err = newScheduler(statestore.New(ds))
+	require.NoError(t, err)
+	require.Equal(t, 30, sched.sectorPriority(ctx, sector))
+
+	require.NoError(t, sched.clearPriority(sector))
+	require.Equal(t, DefaultSchedPriority, sched.sectorPriority(ctx, sector))
+
+	sched, err = newScheduler(statestore.New(ds))
+	require.NoError(t, err)
+	require.Equal(t, DefaultSchedPriority, sched.sectorPriority(ctx, sector))
+}
+
 func TestSchedStartStop(t *testing.T) {
-	sched := newScheduler()
+	sched := newTestScheduler(t)
 	go sched.runSched()
 
 	addTestWorker(t, sched, stores.NewIndex(), "fred", nil)
@@ -288,7 +326,7 @@ func TestSched(t *testing.T) {
 		return func(t *testing.T) {
 			index := stores.NewIndex()
 
-			sched := newScheduler()
+			sched := newTestScheduler(t)
 			sched.testSync = make(chan struct{})
 
 			go sched.runSched()
@@ -529,7 +567,7 @@ func BenchmarkTrySched(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				b.StopTimer()
 
-				sched := newScheduler()
+				sched := newTestScheduler(b)
 				sched.workers[WorkerID{}] = &workerHandle{
 					workerRpc: nil,
 					info: storiface.WorkerInfo{
@@ -588,7 +626,7 @@ func TestWindowCompact(t *testing.T) {
 						taskType: task,
 						sector:   storage.SectorRef{ProofType: spt},
 					})
-					window.allocated.add(wh.info.Resources, ResourceTable[task][spt])
+					window.allocated.add(wh.info.Resources, task, ResourceTable[task][spt])
 				}
 
 				wh.activeWindows = append(wh.activeWindows, window)
@@ -607,7 +645,7 @@ func TestWindowCompact(t *testing.T) {
 
 				for ti, task := range tasks {
 					require.Equal(t, task, wh.activeWindows[wi].todo[ti].taskType, "%d, %d", wi, ti)
-					expectRes.add(wh.info.Resources, ResourceTable[task][spt])
+					expectRes.add(wh.info.Resources, task, ResourceTable[task][spt])
 				}
 
 				require.Equal(t, expectRes.cpuUse, wh.activeWindows[wi].allocated.cpuUse, "%d", wi)
@@ -644,3 +682,40 @@ func TestWindowCompact(t *testing.T) {
 		[][]sealtasks.TaskType{{sealtasks.TTPreCommit1, sealtasks.TTPreCommit1, sealtasks.TTAddPiece}, {sealtasks.TTPreCommit1, sealtasks.TTPreCommit2}}),
 	)
 }
+
+func TestTaskResourcesOverride(t *testing.T) {
+	spt := abi.RegisteredSealProof_StackedDrg32GiBV1
+	noGPU := false
+
+	info := storiface.WorkerInfo{
+		Resources: decentWorkerResources,
+		TaskResources: map[sealtasks.TaskType]storiface.TaskResources{
+			sealtasks.TTPreCommit1: {
+				MinMemory:     40 << 30,
+				MaxConcurrent: 2,
+			},
+			sealtasks.TTCommit2: {
+				CanGPU: &noGPU,
+			},
+		},
+	}
+
+	pc1 := TaskResources(info, sealtasks.TTPreCommit1, spt)
+	require.Equal(t, uint64(40<<30), pc1.MinMemory)
+	require.Equal(t, ResourceTable[sealtasks.TTPreCommit1][spt].MaxMemory, pc1.MaxMemory)
+	require.Equal(t, 2, pc1.MaxConcurrent)
+
+	require.False(t, TaskResources(info, sealtasks.TTCommit2, spt).CanGPU)
+	require.Equal(t, ResourceTable[sealtasks.TTAddPiece][spt], TaskResources(info, sealtasks.TTAddPiece, spt))
+
+	// the third PC1 doesn't fit even though there is enough memory
+	var active activeResources
+	for i := 0; i < 2; i++ {
+		require.True(t, active.canHandleRequest(pc1, sealtasks.TTPreCommit1, WorkerID{}, "test", info.Resources))
+		active.add(info.Resources, sealtasks.TTPreCommit1, pc1)
+	}
+	require.False(t, active.canHandleRequest(pc1, sealtasks.TTPreCommit1, WorkerID{}, "test", info.Resources))
+
+	active.free(info.Resources, sealtasks.TTPreCommit1, pc1)
+	require.True(t, active.canHandleRequest(pc1, sealtasks.TTPreCommit1, WorkerID{}, "test", info.Resources))
+}
@@ -6,6 +6,7 @@ import (
 
 	"golang.org/x/xerrors"
 
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/stores"
 )
 
@@ -51,6 +52,8 @@ func (sh *scheduler) runWorker(ctx context.Context, w Worker) error {
 
 	wid := WorkerID(sessID)
 
+	worker.tasks, worker.paths = fetchWorkerCache(ctx, w)
+
 	sh.workersLk.Lock()
 	_, exist := sh.workers[wid]
 	if exist {
@@ -144,6 +147,10 @@ func (sw *schedWorker) handleWorker() {
 			if !ok {
 				return
 			}
+			if !update && !pokeSched {
+				// heartbeat
+				sw.updateCache(ctx)
+			}
 			if pokeSched {
 				// a task has finished preparing, which can mean that we've freed some space on some worker
 				select {
@@ -170,6 +177,36 @@ func (sw *schedWorker) handleWorker() {
 	}
 }
 
+// fetchWorkerCache gets the task types and storage paths of the worker. Errors
+// are logged only, the cache is left empty and the worker doesn't take tasks
+// scheduled from the cache until the next heartbeat.
+func fetchWorkerCache(ctx context.Context, w Worker) (map[sealtasks.TaskType]struct{}, []stores.StoragePath) {
+	ctx, cancel := context.WithTimeout(ctx, stores.HeartbeatInterval/2)
+	defer cancel()
+
+	tasks, err := w.TaskTypes(ctx)
+	if err != nil {
+		log.Warnw("failed to get worker task types", "error", err)
+		return nil, nil
+	}
+
+	paths, err := w.Paths(ctx)
+	if err != nil {
+		log.Warnw("failed to get worker paths", "error", err)
+		return nil, nil
+	}
+
+	return tasks, paths
+}
+
+func (sw *schedWorker) updateCache(ctx context.Context) {
+	tasks, paths := fetchWorkerCache(ctx, sw.worker.workerRpc)
+
+	sw.sched.workersLk.Lock()
+	sw.worker.tasks, sw.worker.paths = tasks, paths
+	sw.sched.workersLk.Unlock()
+}
+
 func (sw *schedWorker) disable(ctx context.Context) error {
 	done := make(chan struct{})
 
@@ -295,15 +332,15 @@ func (sw *schedWorker) workerCompactWindows() {
 			var moved []int
 
 			for ti, todo := range window.todo {
-				needRes := ResourceTable[todo.taskType][todo.sector.ProofType]
-				if !lower.allocated.canHandleRequest(needRes, sw.wid, "compactWindows", worker.info.Resources) {
+				needRes := TaskResources(worker.info, todo.taskType, todo.sector.ProofType)
+				if !lower.allocated.canHandleRequest(needRes, todo.taskType, sw.wid, "compactWindows", worker.info.Resources) {
 					continue
 				}
 
 				moved = append(moved, ti)
 				lower.todo = append(lower.todo, todo)
-				lower.allocated.add(worker.info.Resources, needRes)
-				window.allocated.free(worker.info.Resources, needRes)
+				lower.allocated.add(worker.info.Resources, todo.taskType, needRes)
+				window.allocated.free(worker.info.Resources, todo.taskType, needRes)
 			}
 
 			if len(moved) > 0 {
@@ -351,8 +388,8 @@ assignLoop:
 
 			worker.lk.Lock()
 			for t, todo := range firstWindow.todo {
-				needRes := ResourceTable[todo.taskType][todo.sector.ProofType]
-				if worker.preparing.canHandleRequest(needRes, sw.wid, "startPreparing", worker.info.Resources) {
+				needRes := TaskResources(worker.info, todo.taskType, todo.sector.ProofType)
+				if worker.preparing.canHandleRequest(needRes, todo.taskType, sw.wid, "startPreparing", worker.info.Resources) {
 					tidx = t
 					break
 				}
@@ -390,10 +427,10 @@ assignLoop:
 func (sw *schedWorker) startProcessingTask(taskDone chan struct{}, req *workerRequest) error {
 	w, sh := sw.worker, sw.sched
 
-	needRes := ResourceTable[req.taskType][req.sector.ProofType]
+	needRes := TaskResources(w.info, req.taskType, req.sector.ProofType)
 
 	w.lk.Lock()
-	w.preparing.add(w.info.Resources, needRes)
+	w.preparing.add(w.info.Resources, req.taskType, needRes)
 	w.lk.Unlock()
 
 	go func() {
@@ -403,7 +440,7 @@ func (sw *schedWorker) startProcessingTask(taskDone chan struct{}, req *workerRe
 
 		if err != nil {
 			w.lk.Lock()
-			w.preparing.free(w.info.Resources, needRes)
+			w.preparing.free(w.info.Resources, req.taskType, needRes)
 			w.lk.Unlock()
 			sh.workersLk.Unlock()
 
@@ -424,9 +461,9 @@ func (sw *schedWorker) startProcessingTask(taskDone chan struct{}, req *workerRe
 		}
 
 		// wait (if needed) for resources in the 'active' window
-		err = w.active.withResources(sw.wid, w.info.Resources, needRes, &sh.workersLk, func() error {
+		err = w.active.withResources(sw.wid, w.info.Resources, req.taskType, needRes, &sh.workersLk, func() error {
 			w.lk.Lock()
-			w.preparing.free(w.info.Resources, needRes)
+			w.preparing.free(w.info.Resources, req.taskType, needRes)
 			w.lk.Unlock()
 			sh.workersLk.Unlock()
 			defer sh.workersLk.Lock() // we MUST return locked from this function
@@ -0,0 +1,265 @@
+package sectorstorage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-storage/storage"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/stores"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// ScrubConfig configures the background sector integrity scrubber
+type ScrubConfig struct {
+	// Pause between two passes over all the sectors
+	Interval time.Duration
+
+	// Pause between two sector checks, keeping the scrubber from competing
+	// with sealing and proving for disk bandwidth
+	SectorDelay time.Duration
+}
+
+// ScrubSector is a sector to check in a scrubbing pass
+type ScrubSector struct {
+	Ref   storage.SectorRef
+	CommR cid.Cid
+
+	// Next proving deadline of the sector
+	Deadline     uint64
+	DeadlineOpen abi.ChainEpoch
+}
+
+// ScrubSource lists the sectors which are expected to be provable
+type ScrubSource interface {
+	ScrubSectors(ctx context.Context) ([]ScrubSector, error)
+}
+
+// scrubber keeps track of the scrubbing passes and of the latest check of
+// each sector
+type scrubber struct {
+	lk sync.Mutex
+
+	status  storiface.ScrubStatus
+	results map[abi.SectorID]storiface.SectorScrubResult
+}
+
+func newScrubber() *scrubber {
+	return &scrubber{
+		results: map[abi.SectorID]storiface.SectorScrubResult{},
+	}
+}
+
+func (s *scrubber) startPass(total int) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.status.Running = true
+	s.status.PassStart = time.Now()
+	s.status.Checked = 0
+	s.status.Total = total
+}
+
+func (s *scrubber) endPass(listed map[abi.SectorID]struct{}) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	// forget sectors which aren't expected to be provable anymore
+	for id := range s.results {
+		if _, ok := listed[id]; !ok {
+			delete(s.results, id)
+		}
+	}
+
+	s.status.Running = false
+	s.status.Passes++
+	s.status.LastPassEnd = time.Now()
+}
+
+func (s *scrubber) record(res storiface.SectorScrubResult) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.status.Checked++
+	s.results[res.Sector] = res
+}
+
+func (s *scrubber) statusInfo() storiface.ScrubStatus {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	out := s.status
+	out.Paths = map[string]storiface.ScrubPathStatus{}
+	out.Failed = []storiface.SectorScrubResult{}
+
+	for _, res := range s.results {
+		ps := out.Paths[res.Storage]
+		ps.Checked++
+		if res.Error != "" {
+			ps.Failed++
+			out.Failed = append(out.Failed, res)
+		}
+		if res.Checked.After(ps.LastCheck) {
+			ps.LastCheck = res.Checked
+		}
+		out.Paths[res.Storage] = ps
+	}
+
+	sort.Slice(out.Failed, func(i, j int) bool {
+		if out.Failed[i].DeadlineOpen != out.Failed[j].DeadlineOpen {
+			return out.Failed[i].DeadlineOpen < out.Failed[j].DeadlineOpen
+		}
+		return out.Failed[i].Sector.Number < out.Failed[j].Sector.Number
+	})
+
+	return out
+}
+
+// RunScrubber periodically checks that the sectors listed by src can still be
+// proven, by generating a vanilla proof for each of them, one storage path at
+// a time. It returns when ctx is cancelled.
+func (m *Manager) RunScrubber(ctx context.Context, cfg ScrubConfig, src ScrubSource) {
+	m.scrub.lk.Lock()
+	m.scrub.status.Enabled = true
+	m.scrub.lk.Unlock()
+
+	for {
+		if err := m.scrubPass(ctx, cfg, src); err != nil {
+			log.Errorf("sector scrubber: %+v", err)
+		}
+
+		select {
+		case <-time.After(cfg.Interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Manager) scrubPass(ctx context.Context, cfg ScrubConfig, src ScrubSource) error {
+	sectors, err := src.ScrubSectors(ctx)
+	if err != nil {
+		return xerrors.Errorf("listing sectors: %w", err)
+	}
+
+	local, err := m.localStore.Local(ctx)
+	if err != nil {
+		return xerrors.Errorf("listing local storage: %w", err)
+	}
+	isLocal := map[stores.ID]bool{}
+	for _, p := range local {
+		isLocal[p.ID] = true
+	}
+
+	// sectors in paths not local to the miner can't be checked here
+	byPath := map[stores.ID][]ScrubSector{}
+	listed := map[abi.SectorID]struct{}{}
+	for _, sector := range sectors {
+		ssize, err := sector.Ref.ProofType.SectorSize()
+		if err != nil {
+			return err
+		}
+
+		si, err := m.index.StorageFindSector(ctx, sector.Ref.ID, storiface.FTSealed, ssize, false)
+		if err != nil {
+			return xerrors.Errorf("finding sector %v: %w", sector.Ref.ID, err)
+		}
+
+		var id stores.ID // empty when the sealed file isn't stored anywhere
+		for _, info := range si {
+			if isLocal[info.ID] {
+				id = info.ID
+				break
+			}
+		}
+		if id == "" && len(si) > 0 {
+			continue
+		}
+
+		byPath[id] = append(byPath[id], sector)
+		listed[sector.Ref.ID] = struct{}{}
+	}
+
+	m.scrub.startPass(len(listed))
+	defer m.scrub.endPass(listed)
+
+	for id, sectors := range byPath {
+		for _, sector := range sectors {
+			select {
+			case <-time.After(cfg.SectorDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			res, ok, err := m.scrubSector(ctx, id, sector)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			m.scrub.record(res)
+
+			if res.Error != "" {
+				log.Warnw("sector scrubber: sector likely to fault at its next deadline", "sector", sector.Ref.ID, "storage", id, "deadline", sector.Deadline, "open", sector.DeadlineOpen, "error", res.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+// scrubSector checks a single sector, returning false when the sector is busy
+// and should be checked in the next pass instead
+func (m *Manager) scrubSector(ctx context.Context, id stores.ID, sector ScrubSector) (storiface.SectorScrubResult, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	res := storiface.SectorScrubResult{
+		Sector:       sector.Ref.ID,
+		Storage:      string(id),
+		Deadline:     sector.Deadline,
+		DeadlineOpen: sector.DeadlineOpen,
+	}
+
+	// sectors being moved or removed aren't faulty, skip them until they're
+	// no longer in use
+	locked, err := m.index.StorageTryLock(ctx, sector.Ref.ID, storiface.FTSealed|storiface.FTCache, storiface.FTNone)
+	if err != nil {
+		return res, false, xerrors.Errorf("acquiring sector lock: %w", err)
+	}
+	if !locked {
+		return res, false, nil
+	}
+
+	wpp, err := sector.Ref.ProofType.RegisteredWindowPoStProof()
+	if err != nil {
+		return res, false, err
+	}
+
+	rg := func(ctx context.Context, id abi.SectorID) (cid.Cid, error) {
+		return sector.CommR, nil
+	}
+
+	bad, err := m.CheckProvable(ctx, wpp, []storage.SectorRef{sector.Ref}, rg)
+	if err != nil {
+		return res, false, xerrors.Errorf("checking sector %v: %w", sector.Ref.ID, err)
+	}
+
+	res.Checked = time.Now()
+	res.Error = bad[sector.Ref.ID]
+
+	return res, true, nil
+}
+
+// ScrubStatus returns the state of the sector integrity scrubber.
+func (m *Manager) ScrubStatus(ctx context.Context) (storiface.ScrubStatus, error) {
+	return m.scrub.statusInfo(), nil
+}
@@ -0,0 +1,57 @@
+package sectorstorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+func TestScrubberStatus(t *testing.T) {
+	s := newScrubber()
+
+	sector := func(n abi.SectorNumber) abi.SectorID {
+		return abi.SectorID{Miner: 1000, Number: n}
+	}
+
+	s.startPass(3)
+	s.record(storiface.SectorScrubResult{Sector: sector(1), Storage: "a", Checked: time.Now()})
+	s.record(storiface.SectorScrubResult{Sector: sector(2), Storage: "a", Checked: time.Now(), Error: "bad", DeadlineOpen: 20})
+	s.record(storiface.SectorScrubResult{Sector: sector(3), Storage: "b", Checked: time.Now(), Error: "bad", DeadlineOpen: 10})
+
+	st := s.statusInfo()
+	require.True(t, st.Running)
+	require.Equal(t, 3, st.Checked)
+	require.Equal(t, storiface.ScrubPathStatus{Checked: 2, Failed: 1, LastCheck: st.Paths["a"].LastCheck}, st.Paths["a"])
+	require.Equal(t, 1, st.Paths["b"].Failed)
+
+	// failed sectors are sorted by deadline
+	require.Len(t, st.Failed, 2)
+	require.Equal(t, sector(3), st.Failed[0].Sector)
+	require.Equal(t, sector(2), st.Failed[1].Sector)
+
+	s.endPass(map[abi.SectorID]struct{}{
+		sector(1): {},
+		sector(2): {},
+		sector(3): {},
+	})
+
+	// sector 3 got repaired, sector 2 is no longer active
+	s.startPass(2)
+	s.record(storiface.SectorScrubResult{Sector: sector(1), Storage: "a", Checked: time.Now()})
+	s.record(storiface.SectorScrubResult{Sector: sector(3), Storage: "b", Checked: time.Now()})
+	s.endPass(map[abi.SectorID]struct{}{
+		sector(1): {},
+		sector(3): {},
+	})
+
+	st = s.statusInfo()
+	require.False(t, st.Running)
+	require.Equal(t, uint64(2), st.Passes)
+	require.Empty(t, st.Failed)
+	require.Equal(t, 1, st.Paths["a"].Checked)
+}
@@ -14,6 +14,8 @@ const (
 	TTFetch        TaskType = "seal/v0/fetch"
 	TTUnseal       TaskType = "seal/v0/unseal"
 	TTReadUnsealed TaskType = "seal/v0/unsealread"
+
+	TTGenerateWindowPoSt TaskType = "post/v0/windowproof"
 )
 
 var order = map[TaskType]int{
@@ -25,7 +27,9 @@ var order = map[TaskType]int{
 	TTUnseal:       1,
 	TTFetch:        -1,
 	TTReadUnsealed: -1,
-	TTFinalize:     -2, // most priority
+	TTFinalize:     -2,
+
+	TTGenerateWindowPoSt: -3, // most priority, PoSt deadlines can't wait
 }
 
 var shortNames = map[TaskType]string{
@@ -41,6 +45,8 @@ var shortNames = map[TaskType]string{
 	TTFetch:        "GET",
 	TTUnseal:       "UNS",
 	TTReadUnsealed: "RD",
+
+	TTGenerateWindowPoSt: "WDP",
 }
 
 func (a TaskType) MuchLess(b TaskType) (bool, bool) {
@@ -61,3 +67,13 @@ func (a TaskType) Short() string {
 
 	return n
 }
+
+// ParseShort returns the task type with the given short name, e.g. "PC1".
+func ParseShort(s string) (TaskType, bool) {
+	for tt, n := range shortNames {
+		if n == s {
+			return tt, true
+		}
+	}
+	return "", false
+}
@@ -34,6 +34,9 @@ func (s *allocSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt abi
 	if _, supported := tasks[task]; !supported {
 		return false, nil
 	}
+	if !whnd.canFit(task, spt) {
+		return false, nil
+	}
 
 	paths, err := whnd.workerRpc.Paths(ctx)
 	if err != nil {
@@ -36,6 +36,9 @@ func (s *existingSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt
 	if _, supported := tasks[task]; !supported {
 		return false, nil
 	}
+	if !whnd.canFit(task, spt) {
+		return false, nil
+	}
 
 	paths, err := whnd.workerRpc.Paths(ctx)
 	if err != nil {
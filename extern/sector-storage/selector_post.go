@@ -0,0 +1,114 @@
+package sectorstorage
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/stores"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// postSelector accepts workers which can read the sealed and cache files of
+// all the sectors to prove from their own paths, proofs never fetch sectors
+type postSelector struct {
+	// storage paths holding each file needed by the proof
+	need [][]stores.ID
+}
+
+func newPoStSelector(need [][]stores.ID) *postSelector {
+	return &postSelector{
+		need: need,
+	}
+}
+
+// findPoStFiles returns the storage paths holding the sealed and the cache
+// files of each sector
+func findPoStFiles(ctx context.Context, index stores.SectorIndex, spt abi.RegisteredSealProof, sectors []abi.SectorID) ([][]stores.ID, error) {
+	ssize, err := spt.SectorSize()
+	if err != nil {
+		return nil, xerrors.Errorf("getting sector size: %w", err)
+	}
+
+	need := make([][]stores.ID, 0, 2*len(sectors))
+	for _, sector := range sectors {
+		for _, fileType := range []storiface.SectorFileType{storiface.FTSealed, storiface.FTCache} {
+			si, err := index.StorageFindSector(ctx, sector, fileType, ssize, false)
+			if err != nil {
+				return nil, xerrors.Errorf("finding sector %v(%s): %w", sector, fileType, err)
+			}
+
+			ids := make([]stores.ID, 0, len(si))
+			for _, info := range si {
+				ids = append(ids, info.ID)
+			}
+			need = append(need, ids)
+		}
+	}
+
+	return need, nil
+}
+
+func (s *postSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt abi.RegisteredSealProof, whnd *workerHandle) (bool, error) {
+	tasks, err := whnd.workerRpc.TaskTypes(ctx)
+	if err != nil {
+		return false, xerrors.Errorf("getting supported worker task types: %w", err)
+	}
+	if _, supported := tasks[task]; !supported {
+		return false, nil
+	}
+	if !whnd.canFit(task, spt) {
+		return false, nil
+	}
+
+	paths, err := whnd.workerRpc.Paths(ctx)
+	if err != nil {
+		return false, xerrors.Errorf("getting worker paths: %w", err)
+	}
+
+	return s.hasFiles(paths), nil
+}
+
+// okCached is Ok for the worker state cached by the scheduler, the caller must
+// hold workersLk
+func (s *postSelector) okCached(task sealtasks.TaskType, spt abi.RegisteredSealProof, whnd *workerHandle) bool {
+	if _, supported := whnd.tasks[task]; !supported {
+		return false
+	}
+	if !whnd.canFit(task, spt) {
+		return false
+	}
+
+	return s.hasFiles(whnd.paths)
+}
+
+func (s *postSelector) hasFiles(paths []stores.StoragePath) bool {
+	have := map[stores.ID]struct{}{}
+	for _, path := range paths {
+		have[path.ID] = struct{}{}
+	}
+
+	for _, ids := range s.need {
+		found := false
+		for _, id := range ids {
+			if _, ok := have[id]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *postSelector) Cmp(ctx context.Context, task sealtasks.TaskType, a, b *workerHandle) (bool, error) {
+	return a.utilization() < b.utilization(), nil
+}
+
+var _ WorkerSelector = &postSelector{}
@@ -24,9 +24,11 @@ func (s *taskSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt abi.
 	if err != nil {
 		return false, xerrors.Errorf("getting supported worker task types: %w", err)
 	}
-	_, supported := tasks[task]
+	if _, supported := tasks[task]; !supported {
+		return false, nil
+	}
 
-	return supported, nil
+	return whnd.canFit(task, spt), nil
 }
 
 func (s *taskSelector) Cmp(ctx context.Context, _ sealtasks.TaskType, a, b *workerHandle) (bool, error) {
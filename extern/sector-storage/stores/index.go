@@ -30,6 +30,9 @@ type StorageInfo struct {
 	URLs   []string // TODO: Support non-http transports
 	Weight uint64
 
+	MaxStorage uint64
+	Draining   bool
+
 	CanSeal  bool
 	CanStore bool
 }
@@ -158,6 +161,8 @@ func (i *Index) StorageAttach(ctx context.Context, si StorageInfo, st fsutil.FsS
 		i.stores[si.ID].info.Weight = si.Weight
 		i.stores[si.ID].info.CanSeal = si.CanSeal
 		i.stores[si.ID].info.CanStore = si.CanStore
+		i.stores[si.ID].info.MaxStorage = si.MaxStorage
+		i.stores[si.ID].info.Draining = si.Draining
 
 		return nil
 	}
@@ -392,6 +397,11 @@ func (i *Index) StorageBestAlloc(ctx context.Context, allocate storiface.SectorF
 			continue
 		}
 
+		if p.info.Draining {
+			log.Debugf("not allocating on %s, path is draining", p.info.ID)
+			continue
+		}
+
 		if spaceReq > uint64(p.fsi.Available) {
 			log.Debugf("not allocating on %s, out of space (available: %d, need: %d)", p.info.ID, p.fsi.Available, spaceReq)
 			continue
@@ -42,6 +42,20 @@ type LocalStorageMeta struct {
 
 	// Finalized sectors that will be proved over time will be stored here
 	CanStore bool
+
+	// MaxStorage limits the space sector data can use in this path
+	MaxStorage uint64 // 0 = no limit
+
+	// Draining paths don't get new sector data, existing sectors can still be
+	// read and removed
+	Draining bool
+}
+
+// StorageMetaUpdate describes changes to the metadata of a local storage
+// path, nil fields are left unchanged.
+type StorageMetaUpdate struct {
+	MaxStorage *uint64
+	Draining   *bool
 }
 
 // StorageConfig .epikstorage/storage.json
@@ -66,6 +80,10 @@ type LocalStorage interface {
 
 const MetaFile = "sectorstore.json"
 
+// UsageRefreshInterval is how often the disk usage of paths with a MaxStorage
+// limit is recomputed.
+var UsageRefreshInterval = time.Minute
+
 type Local struct {
 	localStorage LocalStorage
 	index        SectorIndex
@@ -77,7 +95,14 @@ type Local struct {
 }
 
 type path struct {
-	local string // absolute local path
+	local      string // absolute local path
+	maxStorage uint64
+	draining   bool
+
+	// used is the disk usage of the path, only tracked for paths with a
+	// MaxStorage limit. Walking the path is too slow to be done on every
+	// stat, so it's refreshed in the background.
+	used int64
 
 	reserved     int64
 	reservations map[abi.SectorID]storiface.SectorFileType
@@ -89,6 +114,19 @@ func (p *path) stat(ls LocalStorage) (fsutil.FsStat, error) {
 		return fsutil.FsStat{}, xerrors.Errorf("stat %s: %w", p.local, err)
 	}
 
+	if p.maxStorage > 0 {
+		stat.Max = int64(p.maxStorage)
+		stat.Used = p.used
+
+		avail := int64(p.maxStorage) - p.used
+		if avail < 0 {
+			avail = 0
+		}
+		if avail < stat.Available {
+			stat.Available = avail
+		}
+	}
+
 	stat.Reserved = p.reserved
 
 	for id, ft := range p.reservations {
@@ -130,6 +168,35 @@ func (p *path) stat(ls LocalStorage) (fsutil.FsStat, error) {
 	return stat, err
 }
 
+// updateUsage walks the path to recompute its disk usage when it has a
+// MaxStorage limit.
+func (p *path) updateUsage(ls LocalStorage) error {
+	if p.maxStorage == 0 {
+		p.used = 0
+		return nil
+	}
+
+	used, err := ls.DiskUsage(p.local)
+	if err != nil {
+		return xerrors.Errorf("getting disk usage of %s: %w", p.local, err)
+	}
+	p.used = used
+	return nil
+}
+
+// addUsed accounts for sector data added to or removed from the path between
+// usage refreshes.
+func (p *path) addUsed(n int64) {
+	if p.maxStorage == 0 {
+		return
+	}
+
+	p.used += n
+	if p.used < 0 {
+		p.used = 0
+	}
+}
+
 func (p *path) sectorPath(sid abi.SectorID, fileType storiface.SectorFileType) string {
 	return filepath.Join(p.local, fileType.String(), storiface.SectorName(sid))
 }
@@ -162,24 +229,24 @@ func (st *Local) OpenPath(ctx context.Context, p string) error {
 	// TODO: Check existing / dedupe
 
 	out := &path{
-		local: p,
+		local:      p,
+		maxStorage: meta.MaxStorage,
+		draining:   meta.Draining,
 
 		reserved:     0,
 		reservations: map[abi.SectorID]storiface.SectorFileType{},
 	}
 
+	if err := out.updateUsage(st.localStorage); err != nil {
+		return err
+	}
+
 	fst, err := out.stat(st.localStorage)
 	if err != nil {
 		return err
 	}
 
-	err = st.index.StorageAttach(ctx, StorageInfo{
-		ID:       meta.ID,
-		URLs:     st.urls,
-		Weight:   meta.Weight,
-		CanSeal:  meta.CanSeal,
-		CanStore: meta.CanStore,
-	}, fst)
+	err = st.index.StorageAttach(ctx, st.storageInfo(meta), fst)
 	if err != nil {
 		return xerrors.Errorf("declaring storage in index: %w", err)
 	}
@@ -207,6 +274,7 @@ func (st *Local) open(ctx context.Context) error {
 	}
 
 	go st.reportHealth(ctx)
+	go st.refreshUsage(ctx)
 
 	return nil
 }
@@ -226,23 +294,24 @@ func (st *Local) Redeclare(ctx context.Context) error {
 			return xerrors.Errorf("unmarshalling storage metadata for %s: %w", p.local, err)
 		}
 
-		fst, err := p.stat(st.localStorage)
-		if err != nil {
-			return err
-		}
-
 		if id != meta.ID {
 			log.Errorf("storage path ID changed: %s; %s -> %s", p.local, id, meta.ID)
 			continue
 		}
 
-		err = st.index.StorageAttach(ctx, StorageInfo{
-			ID:       id,
-			URLs:     st.urls,
-			Weight:   meta.Weight,
-			CanSeal:  meta.CanSeal,
-			CanStore: meta.CanStore,
-		}, fst)
+		p.maxStorage = meta.MaxStorage
+		p.draining = meta.Draining
+
+		if err := p.updateUsage(st.localStorage); err != nil {
+			return err
+		}
+
+		fst, err := p.stat(st.localStorage)
+		if err != nil {
+			return err
+		}
+
+		err = st.index.StorageAttach(ctx, st.storageInfo(meta), fst)
 		if err != nil {
 			return xerrors.Errorf("redeclaring storage in index: %w", err)
 		}
@@ -255,6 +324,79 @@ func (st *Local) Redeclare(ctx context.Context) error {
 	return nil
 }
 
+// SetPathMeta applies the update to the metadata file of a local storage path
+// and declares the new settings in the index.
+func (st *Local) SetPathMeta(ctx context.Context, id ID, upd StorageMetaUpdate) error {
+	st.localLk.Lock()
+	defer st.localLk.Unlock()
+
+	p, ok := st.paths[id]
+	if !ok || p.local == "" {
+		return xerrors.Errorf("storage path %s isn't local", id)
+	}
+
+	metaPath := filepath.Join(p.local, MetaFile)
+
+	mb, err := ioutil.ReadFile(metaPath)
+	if err != nil {
+		return xerrors.Errorf("reading storage metadata for %s: %w", p.local, err)
+	}
+
+	var meta LocalStorageMeta
+	if err := json.Unmarshal(mb, &meta); err != nil {
+		return xerrors.Errorf("unmarshalling storage metadata for %s: %w", p.local, err)
+	}
+
+	if upd.MaxStorage != nil {
+		meta.MaxStorage = *upd.MaxStorage
+	}
+	if upd.Draining != nil {
+		meta.Draining = *upd.Draining
+	}
+
+	mb, err = json.MarshalIndent(&meta, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("marshaling storage metadata: %w", err)
+	}
+
+	if err := ioutil.WriteFile(metaPath+".tmp", mb, 0644); err != nil { // nolint
+		return xerrors.Errorf("writing storage metadata for %s: %w", p.local, err)
+	}
+	if err := os.Rename(metaPath+".tmp", metaPath); err != nil {
+		return xerrors.Errorf("replacing storage metadata for %s: %w", p.local, err)
+	}
+
+	p.maxStorage = meta.MaxStorage
+	p.draining = meta.Draining
+
+	if err := p.updateUsage(st.localStorage); err != nil {
+		return err
+	}
+
+	fst, err := p.stat(st.localStorage)
+	if err != nil {
+		return err
+	}
+
+	if err := st.index.StorageAttach(ctx, st.storageInfo(meta), fst); err != nil {
+		return xerrors.Errorf("redeclaring storage in index: %w", err)
+	}
+
+	return st.index.StorageReportHealth(ctx, id, HealthReport{Stat: fst})
+}
+
+func (st *Local) storageInfo(meta LocalStorageMeta) StorageInfo {
+	return StorageInfo{
+		ID:         meta.ID,
+		URLs:       st.urls,
+		Weight:     meta.Weight,
+		MaxStorage: meta.MaxStorage,
+		Draining:   meta.Draining,
+		CanSeal:    meta.CanSeal,
+		CanStore:   meta.CanStore,
+	}
+}
+
 func (st *Local) declareSectors(ctx context.Context, p string, id ID, primary bool) error {
 	for _, t := range storiface.PathTypes {
 		ents, err := ioutil.ReadDir(filepath.Join(p, t.String()))
@@ -303,6 +445,46 @@ func (st *Local) reportHealth(ctx context.Context) {
 	}
 }
 
+func (st *Local) refreshUsage(ctx context.Context) {
+	for {
+		select {
+		case <-time.After(UsageRefreshInterval):
+		case <-ctx.Done():
+			return
+		}
+
+		st.updateUsage()
+	}
+}
+
+// updateUsage recomputes the disk usage of paths with a MaxStorage limit. The
+// paths are walked without holding localLk, so that allocations aren't
+// blocked for the duration of the walk.
+func (st *Local) updateUsage() {
+	st.localLk.RLock()
+	toWalk := map[*path]string{}
+	for _, p := range st.paths {
+		if p.maxStorage > 0 {
+			toWalk[p] = p.local
+		}
+	}
+	st.localLk.RUnlock()
+
+	for p, local := range toWalk {
+		used, err := st.localStorage.DiskUsage(local)
+		if err != nil {
+			log.Warnf("getting disk usage of %s: %+v", local, err)
+			continue
+		}
+
+		st.localLk.Lock()
+		if p.maxStorage > 0 {
+			p.used = used
+		}
+		st.localLk.Unlock()
+	}
+}
+
 func (st *Local) reportStorage(ctx context.Context) {
 	st.localLk.RLock()
 
@@ -458,7 +640,27 @@ func (st *Local) AcquireSector(ctx context.Context, sid storage.SectorRef, exist
 				continue
 			}
 
-			// TODO: Check free space
+			if p.draining {
+				continue
+			}
+
+			if p.maxStorage > 0 {
+				need, err := fileType.SealSpaceUse(ssize)
+				if err != nil {
+					return storiface.SectorPaths{}, storiface.SectorPaths{}, xerrors.Errorf("estimating required space: %w", err)
+				}
+
+				stat, err := p.stat(st.localStorage)
+				if err != nil {
+					log.Warnf("checking quota of %s: %+v", si.ID, err)
+					continue
+				}
+
+				if uint64(stat.Available) < need {
+					log.Debugf("not allocating on %s, over quota (available: %d, need: %d)", si.ID, stat.Available, need)
+					continue
+				}
+			}
 
 			best = p.sectorPath(sid.ID, fileType)
 			bestID = si.ID
@@ -580,8 +782,19 @@ func (st *Local) removeSector(ctx context.Context, sid abi.SectorID, typ storifa
 	spath := p.sectorPath(sid, typ)
 	log.Infof("remove %s", spath)
 
+	var freed int64
+	if p.maxStorage > 0 {
+		if used, err := st.localStorage.DiskUsage(spath); err == nil {
+			freed = used
+		}
+	}
+
 	if err := os.RemoveAll(spath); err != nil {
 		log.Errorf("removing sector (%v) from %s: %+v", sid, spath, err)
+	} else {
+		st.localLk.Lock()
+		p.addUsed(-freed)
+		st.localLk.Unlock()
 	}
 
 	st.reportStorage(ctx) // report freed space
@@ -0,0 +1,267 @@
+package stores
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/fsutil"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+// MoveSector moves all the files of a sector stored in the local path from to
+// the local path to. The destination must be able to store sectors, must not
+// be draining, and must have room for the files within its storage limit.
+// Files are copied and verified against the checksums of the source first,
+// then declared in the destination path, and only then dropped from the index
+// and deleted from the source path.
+func (st *Local) MoveSector(ctx context.Context, sid abi.SectorID, from, to ID) error {
+	if from == to {
+		return xerrors.Errorf("source and destination paths are the same")
+	}
+
+	st.localLk.RLock()
+	src, srcOk := st.paths[from]
+	dst, dstOk := st.paths[to]
+	st.localLk.RUnlock()
+
+	if !srcOk || src.local == "" {
+		return xerrors.Errorf("source path %s isn't local", from)
+	}
+	if !dstOk || dst.local == "" {
+		return xerrors.Errorf("destination path %s isn't local", to)
+	}
+
+	var types storiface.SectorFileType
+	primary := map[storiface.SectorFileType]bool{}
+	for _, fileType := range storiface.PathTypes {
+		si, err := st.index.StorageFindSector(ctx, sid, fileType, 0, false)
+		if err != nil {
+			return xerrors.Errorf("finding sector %v(%s): %w", sid, fileType, err)
+		}
+
+		for _, info := range si {
+			if info.ID == from {
+				types |= fileType
+				primary[fileType] = info.Primary
+			}
+		}
+	}
+
+	if types == storiface.FTNone {
+		return xerrors.Errorf("sector %v not found in path %s", sid, from)
+	}
+
+	dstInfo, err := st.index.StorageInfo(ctx, to)
+	if err != nil {
+		return xerrors.Errorf("getting destination path info: %w", err)
+	}
+	if !dstInfo.CanStore {
+		return xerrors.Errorf("destination path %s can't store sectors", to)
+	}
+	if dstInfo.Draining {
+		return xerrors.Errorf("destination path %s is draining", to)
+	}
+
+	// keep sealing tasks and other moves away from the sector files
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := st.index.StorageLock(lockCtx, sid, storiface.FTNone, types); err != nil {
+		return xerrors.Errorf("acquiring sector lock: %w", err)
+	}
+
+	var size int64
+	for _, fileType := range storiface.PathTypes {
+		if fileType&types == 0 {
+			continue
+		}
+
+		used, err := st.localStorage.DiskUsage(src.sectorPath(sid, fileType))
+		if err != nil {
+			return xerrors.Errorf("getting size of %v(%s): %w", sid, fileType, err)
+		}
+		size += used
+	}
+
+	release, err := st.reserveMove(dst, to, sid, types, size)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for _, fileType := range storiface.PathTypes {
+		if fileType&types == 0 {
+			continue
+		}
+
+		srcPath := src.sectorPath(sid, fileType)
+		dstPath := dst.sectorPath(sid, fileType)
+
+		log.Infof("moving %v(%s): %s -> %s", sid, fileType, srcPath, dstPath)
+
+		if err := copyVerified(ctx, srcPath, dstPath); err != nil {
+			return xerrors.Errorf("copying %v(%s): %w", sid, fileType, err)
+		}
+
+		if err := st.index.StorageDeclareSector(ctx, to, sid, fileType, primary[fileType]); err != nil {
+			return xerrors.Errorf("declare sector %v(%s) -> %s: %w", sid, fileType, to, err)
+		}
+
+		if err := st.index.StorageDropSector(ctx, from, sid, fileType); err != nil {
+			return xerrors.Errorf("dropping source sector %v(%s) from index: %w", sid, fileType, err)
+		}
+
+		if err := os.RemoveAll(srcPath); err != nil {
+			log.Errorf("removing moved sector (%v) from %s: %+v", sid, srcPath, err)
+		}
+	}
+
+	st.localLk.Lock()
+	dst.addUsed(size)
+	src.addUsed(-size)
+	st.localLk.Unlock()
+
+	st.reportStorage(ctx) // report space use changes
+
+	return nil
+}
+
+// reserveMove reserves the space for the sector files moved into the path p,
+// the reservation shrinks as the files are copied
+func (st *Local) reserveMove(p *path, id ID, sid abi.SectorID, types storiface.SectorFileType, size int64) (func(), error) {
+	st.localLk.Lock()
+	defer st.localLk.Unlock()
+
+	stat, err := p.stat(st.localStorage)
+	if err != nil {
+		return nil, xerrors.Errorf("getting destination path stat: %w", err)
+	}
+	if stat.Available < size {
+		return nil, xerrors.Errorf("can't move %d bytes to '%s' (id:%s), only %d available", size, p.local, id, stat.Available)
+	}
+
+	p.reserved += size
+	p.reservations[sid] |= types
+
+	return func() {
+		st.localLk.Lock()
+		defer st.localLk.Unlock()
+
+		p.reserved -= size
+		p.reservations[sid] &^= types
+		if p.reservations[sid] == storiface.FTNone {
+			delete(p.reservations, sid)
+		}
+	}, nil
+}
+
+// copyVerified copies the file or directory at from to to. The copy is staged
+// in the FetchTempSubdir of the destination, which isn't declared as sectors
+// when the path is opened, and renamed into place once the checksums of all
+// copied files matched.
+func copyVerified(ctx context.Context, from, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return xerrors.Errorf("destination %s already exists", to)
+	}
+
+	tmp, err := tempFetchDest(to, true)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(tmp); err != nil {
+		return xerrors.Errorf("removing stale %s: %w", tmp, err)
+	}
+
+	err = filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(tmp, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755) // nolint
+		}
+
+		return copyFileVerified(path, target, info.Mode())
+	})
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, to)
+}
+
+// copyFileVerified copies a file and checks that the checksum of the copy read
+// back matches the source. The copy is synced and, where supported, dropped
+// from the page cache before being read back, so that the check covers what
+// is on the disk; otherwise it only guards against errors while copying.
+func copyFileVerified(from, to string, mode os.FileMode) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint
+
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	srcSum := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, srcSum), in); err != nil {
+		_ = out.Close()
+		return xerrors.Errorf("copying %s: %w", from, err)
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := fsutil.DropCache(out); err != nil {
+		_ = out.Close()
+		return xerrors.Errorf("dropping cached pages of %s: %w", to, err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	dstSum, err := fileChecksum(to)
+	if err != nil {
+		return xerrors.Errorf("checksumming copy of %s: %w", from, err)
+	}
+	if !bytes.Equal(srcSum.Sum(nil), dstSum) {
+		return xerrors.Errorf("checksum mismatch copying %s", from)
+	}
+
+	return nil
+}
+
+func fileChecksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
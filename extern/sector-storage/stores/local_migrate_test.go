@@ -0,0 +1,97 @@
+package stores
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
+)
+
+func TestCopyVerified(t *testing.T) {
+	root, err := ioutil.TempDir("", "epik-copy-verified")
+	require.NoError(t, err)
+	defer os.RemoveAll(root) // nolint
+
+	src := filepath.Join(root, "src", "s-t01000-1")
+	require.NoError(t, os.MkdirAll(src, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "p_aux"), []byte("aux"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "t_aux"), []byte("tree"), 0644))
+
+	dst := filepath.Join(root, "dst", "s-t01000-1")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dst), 0755))
+
+	require.NoError(t, copyVerified(context.Background(), src, dst))
+
+	b, err := ioutil.ReadFile(filepath.Join(dst, "t_aux"))
+	require.NoError(t, err)
+	require.Equal(t, "tree", string(b))
+
+	// staged where opening the path doesn't declare it as a sector
+	_, err = os.Stat(filepath.Join(root, "dst", FetchTempSubdir, "s-t01000-1"))
+	require.True(t, os.IsNotExist(err))
+
+	// never overwrite existing data
+	require.Error(t, copyVerified(context.Background(), src, dst))
+}
+
+func TestMoveSectorChecksDestination(t *testing.T) {
+	ctx := context.TODO()
+
+	root, err := ioutil.TempDir("", "sector-storage-teststorage-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root) // nolint
+
+	tstor := &TestingLocalStorage{
+		root: root,
+	}
+
+	index := NewIndex()
+
+	st, err := NewLocal(ctx, tstor, index, nil)
+	require.NoError(t, err)
+
+	from, err := tstor.initMeta("1")
+	require.NoError(t, err)
+	require.NoError(t, st.OpenPath(ctx, filepath.Join(root, "1")))
+
+	to, err := tstor.initMeta("2")
+	require.NoError(t, err)
+	require.NoError(t, st.OpenPath(ctx, filepath.Join(root, "2")))
+
+	sid := abi.SectorID{Miner: 1000, Number: 1}
+	sealed := filepath.Join(root, "1", storiface.FTSealed.String(), storiface.SectorName(sid))
+	require.NoError(t, ioutil.WriteFile(sealed, []byte("sealed"), 0644))
+	require.NoError(t, index.StorageDeclareSector(ctx, from, sid, storiface.FTSealed, true))
+
+	drain, resume := true, false
+	require.NoError(t, st.SetPathMeta(ctx, to, StorageMetaUpdate{Draining: &drain}))
+	require.Error(t, st.MoveSector(ctx, sid, from, to))
+	require.NoError(t, st.SetPathMeta(ctx, to, StorageMetaUpdate{Draining: &resume}))
+
+	full := uint64(1)
+	require.NoError(t, st.SetPathMeta(ctx, to, StorageMetaUpdate{MaxStorage: &full}))
+	require.Error(t, st.MoveSector(ctx, sid, from, to))
+	unlimited := uint64(0)
+	require.NoError(t, st.SetPathMeta(ctx, to, StorageMetaUpdate{MaxStorage: &unlimited}))
+
+	require.NoError(t, st.MoveSector(ctx, sid, from, to))
+
+	b, err := ioutil.ReadFile(filepath.Join(root, "2", storiface.FTSealed.String(), storiface.SectorName(sid)))
+	require.NoError(t, err)
+	require.Equal(t, "sealed", string(b))
+
+	_, err = os.Stat(sealed)
+	require.True(t, os.IsNotExist(err))
+
+	si, err := index.StorageFindSector(ctx, sid, storiface.FTSealed, 0, false)
+	require.NoError(t, err)
+	require.Len(t, si, 1)
+	require.Equal(t, to, si[0].ID)
+}
@@ -9,7 +9,10 @@ import (
 	"testing"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/fsutil"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-storage/storage"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -42,9 +45,14 @@ func (t *TestingLocalStorage) Stat(path string) (fsutil.FsStat, error) {
 }
 
 func (t *TestingLocalStorage) init(subpath string) error {
+	_, err := t.initMeta(subpath)
+	return err
+}
+
+func (t *TestingLocalStorage) initMeta(subpath string) (ID, error) {
 	path := filepath.Join(t.root, subpath)
 	if err := os.Mkdir(path, 0755); err != nil {
-		return err
+		return "", err
 	}
 
 	metaFile := filepath.Join(path, MetaFile)
@@ -58,14 +66,14 @@ func (t *TestingLocalStorage) init(subpath string) error {
 
 	mb, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if err := ioutil.WriteFile(metaFile, mb, 0644); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return meta.ID, nil
 }
 
 var _ LocalStorage = &TestingLocalStorage{}
@@ -93,3 +101,134 @@ func TestLocalStorage(t *testing.T) {
 
 	// TODO: put more things here
 }
+
+func TestLocalStorageQuotaAndDrain(t *testing.T) {
+	ctx := context.TODO()
+
+	root, err := ioutil.TempDir("", "sector-storage-teststorage-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root) // nolint
+
+	tstor := &TestingLocalStorage{
+		root: root,
+	}
+
+	index := NewIndex()
+
+	st, err := NewLocal(ctx, tstor, index, nil)
+	require.NoError(t, err)
+
+	id, err := tstor.initMeta("1")
+	require.NoError(t, err)
+	require.NoError(t, st.OpenPath(ctx, filepath.Join(tstor.root, "1")))
+
+	sector := storage.SectorRef{
+		ID:        abi.SectorID{Miner: 1000, Number: 1},
+		ProofType: abi.RegisteredSealProof_StackedDrg2KiBV1,
+	}
+	allocate := func() error {
+		_, _, err := st.AcquireSector(ctx, sector, storiface.FTNone, storiface.FTSealed, storiface.PathStorage, storiface.AcquireMove)
+		return err
+	}
+
+	require.NoError(t, allocate())
+
+	drain, resume := true, false
+	require.NoError(t, st.SetPathMeta(ctx, id, StorageMetaUpdate{Draining: &drain}))
+	require.Error(t, allocate())
+
+	si, err := index.StorageInfo(ctx, id)
+	require.NoError(t, err)
+	require.True(t, si.Draining)
+
+	require.NoError(t, st.SetPathMeta(ctx, id, StorageMetaUpdate{Draining: &resume}))
+	require.NoError(t, allocate())
+
+	small := uint64(1024) // less than a sealed 2KiB sector
+	require.NoError(t, st.SetPathMeta(ctx, id, StorageMetaUpdate{MaxStorage: &small}))
+	require.Error(t, allocate())
+
+	fst, err := st.FsStat(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(small), fst.Max)
+
+	large := uint64(pathSize)
+	require.NoError(t, st.SetPathMeta(ctx, id, StorageMetaUpdate{MaxStorage: &large}))
+	require.NoError(t, allocate())
+
+	// settings are persisted in the path metadata
+	mb, err := ioutil.ReadFile(filepath.Join(tstor.root, "1", MetaFile))
+	require.NoError(t, err)
+	var meta LocalStorageMeta
+	require.NoError(t, json.Unmarshal(mb, &meta))
+	require.Equal(t, large, meta.MaxStorage)
+	require.False(t, meta.Draining)
+}
+
+type usageCountingStorage struct {
+	*TestingLocalStorage
+
+	path  string
+	used  int64
+	walks int
+}
+
+func (u *usageCountingStorage) DiskUsage(path string) (int64, error) {
+	if path != u.path {
+		return u.TestingLocalStorage.DiskUsage(path)
+	}
+	u.walks++
+	return u.used, nil
+}
+
+func TestLocalStorageUsageRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	root, err := ioutil.TempDir("", "sector-storage-teststorage-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root) // nolint
+
+	tstor := &usageCountingStorage{
+		TestingLocalStorage: &TestingLocalStorage{root: root},
+		path:                filepath.Join(root, "1"),
+		used:                1024,
+	}
+
+	index := NewIndex()
+
+	st, err := NewLocal(ctx, tstor, index, nil)
+	require.NoError(t, err)
+
+	id, err := tstor.initMeta("1")
+	require.NoError(t, err)
+	require.NoError(t, st.OpenPath(ctx, tstor.path))
+
+	max := uint64(pathSize)
+	require.NoError(t, st.SetPathMeta(ctx, id, StorageMetaUpdate{MaxStorage: &max}))
+	require.Equal(t, 1, tstor.walks)
+
+	sector := storage.SectorRef{
+		ID:        abi.SectorID{Miner: 1000, Number: 1},
+		ProofType: abi.RegisteredSealProof_StackedDrg2KiBV1,
+	}
+
+	// allocations and health reports use the cached usage
+	_, _, err = st.AcquireSector(ctx, sector, storiface.FTNone, storiface.FTSealed, storiface.PathStorage, storiface.AcquireMove)
+	require.NoError(t, err)
+	st.reportStorage(ctx)
+
+	fst, err := st.FsStat(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1024), fst.Used)
+	require.Equal(t, 1, tstor.walks)
+
+	tstor.used = 4096
+	st.updateUsage()
+	require.Equal(t, 2, tstor.walks)
+
+	fst, err = st.FsStat(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(4096), fst.Used)
+	require.Equal(t, int64(pathSize-4096), fst.Available)
+}
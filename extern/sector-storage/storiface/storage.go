@@ -1,5 +1,11 @@
 package storiface
 
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
 type PathType string
 
 const (
@@ -13,3 +19,41 @@ const (
 	AcquireMove AcquireMode = "move"
 	AcquireCopy AcquireMode = "copy"
 )
+
+// ScrubStatus is the state of the background sector integrity scrubber
+type ScrubStatus struct {
+	Enabled bool
+	Running bool   // a pass is in progress
+	Passes  uint64 // completed passes
+
+	PassStart   time.Time
+	LastPassEnd time.Time
+
+	// progress of the current pass
+	Checked int
+	Total   int
+
+	Paths map[string]ScrubPathStatus // by storage path ID
+
+	// sectors which failed their latest check, by deadline
+	Failed []SectorScrubResult
+}
+
+type ScrubPathStatus struct {
+	// results of the latest check of the sectors stored in the path
+	Checked   int
+	Failed    int
+	LastCheck time.Time
+}
+
+type SectorScrubResult struct {
+	Sector  abi.SectorID
+	Storage string // ID of the path holding the sealed sector
+	Checked time.Time
+	Error   string
+
+	// next proving deadline of the sector, which it will likely fault in when
+	// the check failed
+	Deadline     uint64
+	DeadlineOpen abi.ChainEpoch
+}
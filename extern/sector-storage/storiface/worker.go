@@ -11,6 +11,7 @@ import (
 	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 	"github.com/filecoin-project/specs-storage/storage"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
@@ -18,8 +19,29 @@ import (
 
 type WorkerInfo struct {
 	Hostname string
+	// Tags are user-defined labels, used by the scheduler affinity rules
+	Tags []string
 
 	Resources WorkerResources
+
+	// TaskResources overrides the resources the scheduler assumes tasks of
+	// the listed types need on the worker
+	TaskResources map[sealtasks.TaskType]TaskResources
+}
+
+// TaskResources overrides the default resource table for a task type. Zero
+// values keep the defaults.
+type TaskResources struct {
+	MinMemory     uint64
+	MaxMemory     uint64
+	BaseMinMemory uint64
+
+	// Threads used by a task, -1 uses most of the worker's threads
+	MaxParallelism int
+	CanGPU         *bool
+
+	// MaxConcurrent limits the number of tasks of the type running at once
+	MaxConcurrent int
 }
 
 type WorkerResources struct {
@@ -64,6 +86,16 @@ type WorkerJob struct {
 	Hostname string `json:",omitempty"` // optional, set for ret-wait jobs
 }
 
+// SchedQueueEntry is a task waiting in the scheduler queue
+type SchedQueueEntry struct {
+	Sector   abi.SectorID
+	Task     sealtasks.TaskType
+	Priority int
+	Queued   time.Time
+
+	EstWait time.Duration // rough estimate of the time left before the task starts, 0 if unknown
+}
+
 type CallID struct {
 	Sector abi.SectorID
 	ID     uuid.UUID
@@ -89,6 +121,15 @@ type WorkerCalls interface {
 	UnsealPiece(context.Context, storage.SectorRef, UnpaddedByteIndex, abi.UnpaddedPieceSize, abi.SealRandomness, cid.Cid) (CallID, error)
 	ReadPiece(context.Context, io.Writer, storage.SectorRef, UnpaddedByteIndex, abi.UnpaddedPieceSize) (CallID, error)
 	Fetch(context.Context, storage.SectorRef, SectorFileType, PathType, AcquireMode) (CallID, error)
+	GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (CallID, error)
+}
+
+// WindowPoStResult is the result of a window PoSt computed by a worker.
+// Skipped lists the sectors which couldn't be proven, in which case the
+// call also returns an error.
+type WindowPoStResult struct {
+	Proofs  []proof2.PoStProof
+	Skipped []abi.SectorID
 }
 
 type ErrorCode int
@@ -143,4 +184,5 @@ type WorkerReturn interface {
 	ReturnUnsealPiece(ctx context.Context, callID CallID, err *CallError) error
 	ReturnReadPiece(ctx context.Context, callID CallID, ok bool, err *CallError) error
 	ReturnFetch(ctx context.Context, callID CallID, err *CallError) error
+	ReturnGenerateWindowPoSt(ctx context.Context, callID CallID, res WindowPoStResult, err *CallError) error
 }
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 	"github.com/filecoin-project/specs-storage/storage"
 	"github.com/google/uuid"
 
@@ -25,6 +26,10 @@ type testWorker struct {
 	pc1lk   sync.Mutex
 	pc1wait *sync.WaitGroup
 
+	posts int
+	// stallPoSt makes window PoSt calls never return, like on a busy worker
+	stallPoSt bool
+
 	session uuid.UUID
 
 	Worker
@@ -93,6 +98,21 @@ func (t *testWorker) Fetch(ctx context.Context, sector storage.SectorRef, fileTy
 	})
 }
 
+func (t *testWorker) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (storiface.CallID, error) {
+	return t.asyncCall(postSector(minerID, sectorInfo), func(ci storiface.CallID) {
+		t.posts++
+		if t.stallPoSt {
+			return
+		}
+
+		proofs, skipped, err := t.mockSeal.GenerateWindowPoSt(ctx, minerID, sectorInfo, randomness)
+		res := storiface.WindowPoStResult{Proofs: proofs, Skipped: skipped}
+		if err := t.ret.ReturnGenerateWindowPoSt(ctx, ci, res, toCallError(err)); err != nil {
+			log.Error(err)
+		}
+	})
+}
+
 func (t *testWorker) TaskTypes(ctx context.Context) (map[sealtasks.TaskType]struct{}, error) {
 	return t.acceptTasks, nil
 }
@@ -20,6 +20,7 @@ import (
 	ffi "github.com/filecoin-project/filecoin-ffi"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-statestore"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 	storage "github.com/filecoin-project/specs-storage/storage"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/ffiwrapper"
@@ -33,6 +34,12 @@ var pathTypes = []storiface.SectorFileType{storiface.FTUnsealed, storiface.FTSea
 type WorkerConfig struct {
 	TaskTypes []sealtasks.TaskType
 	NoSwap    bool
+
+	// TaskResources overrides the scheduler's ResourceTable on this worker
+	TaskResources map[sealtasks.TaskType]storiface.TaskResources
+
+	// Tags are matched by the scheduler affinity rules
+	Tags []string
 }
 
 // used do provide custom proofs impl (mostly used in testing)
@@ -44,7 +51,10 @@ type LocalWorker struct {
 	sindex     stores.SectorIndex
 	ret        storiface.WorkerReturn
 	executor   ExecutorFunc
+	postExec   ExecutorFunc
 	noSwap     bool
+	taskRes    map[sealtasks.TaskType]storiface.TaskResources
+	tags       []string
 
 	ct          *workerCallTracker
 	acceptTasks map[sealtasks.TaskType]struct{}
@@ -74,6 +84,8 @@ func newLocalWorker(executor ExecutorFunc, wcfg WorkerConfig, store stores.Store
 		acceptTasks: acceptTasks,
 		executor:    executor,
 		noSwap:      wcfg.NoSwap,
+		taskRes:     wcfg.TaskResources,
+		tags:        wcfg.Tags,
 
 		session: uuid.New(),
 		closing: make(chan struct{}),
@@ -81,6 +93,9 @@ func newLocalWorker(executor ExecutorFunc, wcfg WorkerConfig, store stores.Store
 
 	if w.executor == nil {
 		w.executor = w.ffiExec
+		w.postExec = w.ffiPoStExec
+	} else {
+		w.postExec = w.executor
 	}
 
 	unfinished, err := w.ct.unfinished()
@@ -149,6 +164,12 @@ func (l *LocalWorker) ffiExec() (ffiwrapper.Storage, error) {
 	return ffiwrapper.New(&localWorkerPathProvider{w: l})
 }
 
+// ffiPoStExec only reads sectors from the local paths of the worker, proving
+// must never fetch sector files
+func (l *LocalWorker) ffiPoStExec() (ffiwrapper.Storage, error) {
+	return ffiwrapper.New(&readonlyProvider{stor: l.localStore, index: l.sindex})
+}
+
 type ReturnType string
 
 const (
@@ -163,6 +184,8 @@ const (
 	UnsealPiece     ReturnType = "UnsealPiece"
 	ReadPiece       ReturnType = "ReadPiece"
 	Fetch           ReturnType = "Fetch"
+
+	GenerateWindowPoSt ReturnType = "GenerateWindowPoSt"
 )
 
 // in: func(WorkerReturn, context.Context, CallID, err string)
@@ -211,6 +234,8 @@ var returnFunc = map[ReturnType]func(context.Context, storiface.CallID, storifac
 	UnsealPiece:     rfunc(storiface.WorkerReturn.ReturnUnsealPiece),
 	ReadPiece:       rfunc(storiface.WorkerReturn.ReturnReadPiece),
 	Fetch:           rfunc(storiface.WorkerReturn.ReturnFetch),
+
+	GenerateWindowPoSt: rfunc(storiface.WorkerReturn.ReturnGenerateWindowPoSt),
 }
 
 func (l *LocalWorker) asyncCall(ctx context.Context, sector storage.SectorRef, rt ReturnType, work func(ctx context.Context, ci storiface.CallID) (interface{}, error)) (storiface.CallID, error) {
@@ -457,6 +482,25 @@ func (l *LocalWorker) ReadPiece(ctx context.Context, writer io.Writer, sector st
 	})
 }
 
+func (l *LocalWorker) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (storiface.CallID, error) {
+	if len(sectorInfo) == 0 {
+		return storiface.UndefCall, xerrors.Errorf("no sectors to prove")
+	}
+
+	sb, err := l.postExec()
+	if err != nil {
+		return storiface.UndefCall, err
+	}
+
+	return l.asyncCall(ctx, postSector(minerID, sectorInfo), GenerateWindowPoSt, func(ctx context.Context, ci storiface.CallID) (interface{}, error) {
+		proofs, skipped, err := sb.GenerateWindowPoSt(ctx, minerID, sectorInfo, randomness)
+		return storiface.WindowPoStResult{
+			Proofs:  proofs,
+			Skipped: skipped,
+		}, err
+	})
+}
+
 func (l *LocalWorker) TaskTypes(context.Context) (map[sealtasks.TaskType]struct{}, error) {
 	l.taskLk.Lock()
 	defer l.taskLk.Unlock()
@@ -512,6 +556,7 @@ func (l *LocalWorker) Info(context.Context) (storiface.WorkerInfo, error) {
 
 	return storiface.WorkerInfo{
 		Hostname: hostname,
+		Tags:     l.tags,
 		Resources: storiface.WorkerResources{
 			MemPhysical: mem.Total,
 			MemSwap:     memSwap,
@@ -519,6 +564,7 @@ func (l *LocalWorker) Info(context.Context) (storiface.WorkerInfo, error) {
 			CPUs:        uint64(runtime.NumCPU()),
 			GPUs:        gpus,
 		},
+		TaskResources: l.taskRes,
 	}, nil
 }
 
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
 	"github.com/filecoin-project/specs-storage/storage"
 
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/sealtasks"
@@ -26,20 +28,45 @@ type workTracker struct {
 	done    map[storiface.CallID]struct{}
 	running map[storiface.CallID]trackedWork
 
-	// TODO: done, aggregate stats, queue stats, scheduler feedback
+	// moving average of the time calls of each task type take, used to
+	// estimate queue wait times
+	durations map[sealtasks.TaskType]time.Duration
+
+	// TODO: done, aggregate stats, scheduler feedback
 }
 
 func (wt *workTracker) onDone(callID storiface.CallID) {
 	wt.lk.Lock()
 	defer wt.lk.Unlock()
 
-	_, ok := wt.running[callID]
+	t, ok := wt.running[callID]
 	if !ok {
 		wt.done[callID] = struct{}{}
 		return
 	}
 
 	delete(wt.running, callID)
+
+	took := time.Since(t.job.Start)
+	if avg, ok := wt.durations[t.job.Task]; ok {
+		took = (avg*7 + took) / 8
+	}
+	wt.durations[t.job.Task] = took
+}
+
+// taskStats returns the average duration of calls of the given task type, zero
+// if none completed yet, and how many of them are currently running.
+func (wt *workTracker) taskStats(tt sealtasks.TaskType) (avg time.Duration, running int) {
+	wt.lk.Lock()
+	defer wt.lk.Unlock()
+
+	for _, t := range wt.running {
+		if t.job.Task == tt {
+			running++
+		}
+	}
+
+	return wt.durations[tt], running
 }
 
 func (wt *workTracker) track(wid WorkerID, sid storage.SectorRef, task sealtasks.TaskType) func(storiface.CallID, error) (storiface.CallID, error) {
@@ -135,4 +162,12 @@ func (t *trackedWorker) ReadPiece(ctx context.Context, writer io.Writer, id stor
 	return t.tracker.track(t.wid, id, sealtasks.TTReadUnsealed)(t.Worker.ReadPiece(ctx, writer, id, index, size))
 }
 
+func (t *trackedWorker) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, sectorInfo []proof2.SectorInfo, randomness abi.PoStRandomness) (storiface.CallID, error) {
+	if len(sectorInfo) == 0 {
+		return storiface.UndefCall, xerrors.Errorf("no sectors to prove")
+	}
+
+	return t.tracker.track(t.wid, postSector(minerID, sectorInfo), sealtasks.TTGenerateWindowPoSt)(t.Worker.GenerateWindowPoSt(ctx, minerID, sectorInfo, randomness))
+}
+
 var _ Worker = &trackedWorker{}
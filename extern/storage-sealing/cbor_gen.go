@@ -427,7 +427,7 @@ func (t *SectorInfo) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{184, 24}); err != nil {
+	if _, err := w.Write([]byte{184, 25}); err != nil {
 		return err
 	}
 
@@ -908,6 +908,31 @@ func (t *SectorInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.Retries ([]sealing.RetryCount) (slice)
+	if len("Retries") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Retries\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Retries"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Retries")); err != nil {
+		return err
+	}
+
+	if len(t.Retries) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Retries was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Retries))); err != nil {
+		return err
+	}
+	for _, v := range t.Retries {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
 	// t.LastErr (string) (string)
 	if len("LastErr") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"LastErr\" was too long")
@@ -1460,6 +1485,36 @@ func (t *SectorInfo) UnmarshalCBOR(r io.Reader) error {
 
 				t.TerminatedAt = abi.ChainEpoch(extraI)
 			}
+			// t.Retries ([]sealing.RetryCount) (slice)
+		case "Retries":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Retries: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Retries = make([]RetryCount, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v RetryCount
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.Retries[i] = v
+			}
+
 			// t.LastErr (string) (string)
 		case "LastErr":
 
@@ -1695,3 +1750,123 @@ func (t *Log) UnmarshalCBOR(r io.Reader) error {
 
 	return nil
 }
+func (t *RetryCount) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.State (sealing.SectorState) (string)
+	if len("State") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"State\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("State"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("State")); err != nil {
+		return err
+	}
+
+	if len(t.State) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.State was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.State))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.State)); err != nil {
+		return err
+	}
+
+	// t.Count (uint64) (uint64)
+	if len("Count") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Count\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Count"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Count")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Count)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *RetryCount) UnmarshalCBOR(r io.Reader) error {
+	*t = RetryCount{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("RetryCount: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.State (sealing.SectorState) (string)
+		case "State":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.State = SectorState(sval)
+			}
+			// t.Count (uint64) (uint64)
+		case "Count":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Count = uint64(extra)
+
+			}
+
+		default:
+			return fmt.Errorf("unknown struct field %d: '%s'", i, name)
+		}
+	}
+
+	return nil
+}
@@ -24,6 +24,9 @@ func (m *Sealing) Plan(events []statemachine.Event, user interface{}) (interface
 
 	return func(ctx statemachine.Context, si SectorInfo) error {
 		err := next(ctx, si)
+		if xerrors.Is(err, errRetriesExhausted) {
+			return nil // paused until the user acts on the sector
+		}
 		if err != nil {
 			log.Errorf("unhandled sector error (%d): %+v", si.SectorNumber, err)
 			return nil
@@ -228,11 +231,19 @@ func (m *Sealing) plan(events []statemachine.Event, state *SectorInfo) (func(sta
 		return nil, 0, xerrors.Errorf("planner for state %s not found", state.State)
 	}
 
+	before := state.State
+
 	processed, err := p(events, state)
 	if err != nil {
 		return nil, 0, xerrors.Errorf("running planner for state %s failed: %w", state.State, err)
 	}
 
+	if _, retried := retriedStates[before]; retried && state.State != before {
+		if _, forced := events[0].User.(SectorForceState); !forced {
+			state.addRetry(before)
+		}
+	}
+
 	/////
 	// Now decide what to do next
 
@@ -48,6 +48,7 @@ type SectorForceState struct {
 
 func (evt SectorForceState) applyGlobal(state *SectorInfo) bool {
 	state.State = evt.State
+	state.Retries = nil // the user took care of the sector, start counting retries anew
 	return true
 }
 
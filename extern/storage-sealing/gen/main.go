@@ -16,6 +16,7 @@ func main() {
 		sealing.DealSchedule{},
 		sealing.SectorInfo{},
 		sealing.Log{},
+		sealing.RetryCount{},
 	)
 	if err != nil {
 		fmt.Println(err)
@@ -0,0 +1,149 @@
+package sealing
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-statemachine"
+
+	"github.com/EpiK-Protocol/go-epik/extern/storage-sealing/sealiface"
+)
+
+const minRetryTime = 1 * time.Minute
+
+// errRetriesExhausted is returned by failedCooldown when the sector ran out of
+// retries in its failed state, in which case it must not be retried.
+var errRetriesExhausted = xerrors.New("retries exhausted")
+
+// retriedStates are the failed states handled according to the retry policies
+var retriedStates = map[SectorState]struct{}{
+	SealPreCommit1Failed: {},
+	SealPreCommit2Failed: {},
+	PreCommitFailed:      {},
+	ComputeProofFailed:   {},
+	CommitFailed:         {},
+	FinalizeFailed:       {},
+	RemoveFailed:         {},
+	TerminateFailed:      {},
+}
+
+func (t *SectorInfo) retries(st SectorState) uint64 {
+	for _, r := range t.Retries {
+		if r.State == st {
+			return r.Count
+		}
+	}
+	return 0
+}
+
+func (t *SectorInfo) addRetry(st SectorState) {
+	for i := range t.Retries {
+		if t.Retries[i].State == st {
+			t.Retries[i].Count++
+			return
+		}
+	}
+	t.Retries = append(t.Retries, RetryCount{State: st, Count: 1})
+}
+
+func retryPolicy(cfg sealiface.Config, st SectorState) sealiface.RetryPolicy {
+	if p, ok := cfg.RetryPolicies[string(st)]; ok {
+		return p
+	}
+	return cfg.DefaultRetryPolicy
+}
+
+// retryBackoff returns how long to wait before retrying after the given
+// number of retries. A zero MaxBackoff doesn't limit the backoff.
+func retryBackoff(p sealiface.RetryPolicy, retries uint64) time.Duration {
+	wait := p.Backoff
+	if wait <= 0 {
+		wait = minRetryTime
+	}
+
+	for i := uint64(0); i < retries && (p.MaxBackoff == 0 || wait < p.MaxBackoff) && wait <= math.MaxInt64/2; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	return wait
+}
+
+// failedCooldown waits before a sector is retried out of its failed state, as
+// configured by the retry policy of the state. Once the retries are exhausted
+// it escalates and returns errRetriesExhausted.
+func (m *Sealing) failedCooldown(ctx statemachine.Context, sector SectorInfo) error {
+	cfg, err := m.getConfig()
+	if err != nil {
+		return xerrors.Errorf("getting sealing config: %w", err)
+	}
+
+	policy := retryPolicy(cfg, sector.State)
+	retries := sector.retries(sector.State)
+
+	if policy.MaxAttempts > 0 && retries >= policy.MaxAttempts {
+		return m.retriesExhausted(ctx, cfg, policy, sector, retries)
+	}
+
+	if len(sector.Log) == 0 {
+		return nil
+	}
+
+	retryStart := time.Unix(int64(sector.Log[len(sector.Log)-1].Timestamp), 0).Add(retryBackoff(policy, retries))
+	if !time.Now().After(retryStart) {
+		log.Infof("%s(%d), waiting %s before retrying (retry %d)", sector.State, sector.SectorNumber, time.Until(retryStart), retries+1)
+		select {
+		case <-time.After(time.Until(retryStart)):
+		case <-ctx.Context().Done():
+			return ctx.Context().Err()
+		}
+	}
+
+	return nil
+}
+
+func (m *Sealing) retriesExhausted(ctx statemachine.Context, cfg sealiface.Config, policy sealiface.RetryPolicy, sector SectorInfo, retries uint64) error {
+	log.Errorf("sector %d: %d retries out of %s exhausted", sector.SectorNumber, retries, sector.State)
+
+	if cfg.RetryExhaustedHook != "" {
+		go runRetryHook(cfg.RetryExhaustedHook, sector.SectorNumber, sector.State, retries)
+	}
+
+	switch policy.OnExhausted {
+	case "", sealiface.RetryExhaustedPause:
+	case sealiface.RetryExhaustedRemove:
+		if sector.State != RemoveFailed {
+			if err := ctx.Send(SectorRemove{}); err != nil {
+				return err
+			}
+		}
+	case sealiface.RetryExhaustedTerminate:
+		if sector.State != TerminateFailed {
+			if err := ctx.Send(SectorTerminate{}); err != nil {
+				return err
+			}
+		}
+	default:
+		log.Errorf("unknown retry exhausted action %q, pausing sector %d", policy.OnExhausted, sector.SectorNumber)
+	}
+
+	return errRetriesExhausted
+}
+
+func runRetryHook(hook string, sector abi.SectorNumber, st SectorState, retries uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, hook, strconv.FormatUint(uint64(sector), 10), string(st), strconv.FormatUint(retries, 10)).CombinedOutput()
+	if err != nil {
+		log.Errorf("running retry exhausted hook for sector %d: %+v; output: %s", sector, err, out)
+	}
+}
@@ -0,0 +1,61 @@
+package sealing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/extern/storage-sealing/sealiface"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := sealiface.RetryPolicy{
+		Backoff:    time.Minute,
+		MaxBackoff: 5 * time.Minute,
+	}
+
+	require.Equal(t, time.Minute, retryBackoff(p, 0))
+	require.Equal(t, 2*time.Minute, retryBackoff(p, 1))
+	require.Equal(t, 4*time.Minute, retryBackoff(p, 2))
+	require.Equal(t, 5*time.Minute, retryBackoff(p, 3))
+	require.Equal(t, 5*time.Minute, retryBackoff(p, 10))
+
+	require.Equal(t, minRetryTime, retryBackoff(sealiface.RetryPolicy{}, 0))
+
+	// a zero MaxBackoff doesn't limit the backoff
+	uncapped := sealiface.RetryPolicy{Backoff: time.Minute}
+	require.Equal(t, 32*time.Minute, retryBackoff(uncapped, 5))
+	require.Equal(t, 32*minRetryTime, retryBackoff(sealiface.RetryPolicy{}, 5))
+	require.True(t, retryBackoff(uncapped, 1000) > 0)
+}
+
+func TestRetryCounters(t *testing.T) {
+	ma, _ := address.NewIDAddress(55151)
+	m := test{
+		s: &Sealing{
+			maddr: ma,
+			stats: SectorStats{
+				bySector: map[abi.SectorID]statSectorState{},
+			},
+		},
+		t:     t,
+		state: &SectorInfo{State: PreCommit1},
+	}
+
+	for i := 0; i < 2; i++ {
+		m.planSingle(SectorSealPreCommit1Failed{xerrors.New("test")})
+		require.Equal(t, SealPreCommit1Failed, m.state.State)
+
+		m.planSingle(SectorRetrySealPreCommit1{})
+		require.Equal(t, PreCommit1, m.state.State)
+	}
+	require.Equal(t, uint64(2), m.state.retries(SealPreCommit1Failed))
+
+	m.planSingle(SectorSealPreCommit1Failed{xerrors.New("test")})
+	m.planSingle(SectorForceState{State: PreCommit1})
+	require.Empty(t, m.state.Retries)
+}
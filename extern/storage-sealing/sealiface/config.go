@@ -15,4 +15,36 @@ type Config struct {
 	MaxSealingSectorsForDeals uint64
 
 	WaitDealsDelay time.Duration
+
+	// Retry policies of failed sector states, keyed by state name (e.g.
+	// SealPreCommit1Failed). States without a policy use DefaultRetryPolicy.
+	RetryPolicies      map[string]RetryPolicy
+	DefaultRetryPolicy RetryPolicy
+
+	// Command run when a sector runs out of retries, with the sector number,
+	// failed state and number of retries as arguments
+	RetryExhaustedHook string
+
+	// URL receiving a JSON POST for every sector state transition
+	StateWebhook string
+}
+
+const (
+	RetryExhaustedPause     = "pause" // leave the sector in the failed state, needs manual user action
+	RetryExhaustedRemove    = "remove"
+	RetryExhaustedTerminate = "terminate"
+)
+
+type RetryPolicy struct {
+	// 0 = no limit
+	MaxAttempts uint64
+
+	// Wait before retrying, doubled after every retry up to MaxBackoff
+	// (0 = no limit)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// What to do once MaxAttempts is reached, one of the RetryExhausted*
+	// actions, pause if empty
+	OnExhausted string
 }
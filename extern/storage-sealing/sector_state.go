@@ -93,3 +93,15 @@ func toStatState(st SectorState) statSectorState {
 
 	return sstFailed
 }
+
+// IsFailed returns whether the state is one of the error modes, which are
+// entered when sealing or removing the sector fails.
+func IsFailed(st SectorState) bool {
+	switch st {
+	case FailedUnrecoverable, SealPreCommit1Failed, SealPreCommit2Failed, PreCommitFailed, ComputeProofFailed, CommitFailed,
+		PackingFailed, FinalizeFailed, DealsExpired, RecoverDealIDs, TerminateFailed, RemoveFailed:
+		return true
+	}
+
+	return false
+}
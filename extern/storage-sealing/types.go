@@ -53,6 +53,12 @@ type Log struct {
 	Kind string
 }
 
+// RetryCount is the number of times a sector was retried out of a failed state
+type RetryCount struct {
+	State SectorState
+	Count uint64
+}
+
 type ReturnState string
 
 const (
@@ -106,6 +112,9 @@ type SectorInfo struct {
 	TerminateMessage *cid.Cid
 	TerminatedAt     abi.ChainEpoch
 
+	// Retries out of failed states
+	Retries []RetryCount
+
 	// Debug
 	LastErr string
 
@@ -100,6 +100,7 @@ func main() {
 		sectorstorage.Call{},
 		sectorstorage.WorkState{},
 		sectorstorage.WorkID{},
+		sectorstorage.SectorPriority{},
 	)
 	if err != nil {
 		fmt.Println(err)
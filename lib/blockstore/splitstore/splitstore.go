@@ -0,0 +1,576 @@
+// Package splitstore implements a blockstore split in a hot and a cold part.
+//
+// All writes go to the hot store, which is meant to hold the recent part of
+// the chain. Periodically, as the chain advances, the hot store is compacted
+// online: objects which are not reachable from the recent chain and which were
+// written more than HotStoreFinality epochs ago are either moved to the cold
+// store ("universal" cold store) or deleted ("discard" cold store).
+//
+// The write epoch of an object is also refreshed when Has finds it in the hot
+// store: the VM doesn't rewrite subtrees which the store already has, so an
+// old object referenced again by a new state would otherwise keep its old
+// epoch and could be purged while state computed during the compaction
+// still links to it.
+//
+// Reads are served from the hot store first and fall back to the cold store.
+// The hot store is wrapped in the same cache as the regular chain blockstore.
+package splitstore
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ipfs/bbloom"
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	bstore "github.com/EpiK-Protocol/go-epik/lib/blockstore"
+	badgerbs "github.com/EpiK-Protocol/go-epik/lib/blockstore/badger"
+)
+
+var log = logging.Logger("splitstore")
+
+const (
+	// ColdStoreUniversal moves compacted objects to the cold store.
+	ColdStoreUniversal = "universal"
+	// ColdStoreDiscard deletes compacted objects.
+	ColdStoreDiscard = "discard"
+)
+
+var (
+	baseEpochKey = datastore.NewKey("/splitstore/baseEpoch")
+
+	// batchSize is the number of objects moved or deleted at once during
+	// compaction.
+	batchSize = 16384
+
+	// minMarkSetSize is the number of objects the mark set is sized for when
+	// there is no previous compaction to estimate it from.
+	minMarkSetSize int64 = 1 << 22
+	// markSetFalsePositive is the false positive rate of the mark set. False
+	// positives only keep dead objects in the hot store until the next
+	// compaction.
+	markSetFalsePositive = 0.0001
+)
+
+// Config configures the compaction of the hot store.
+type Config struct {
+	// ColdStoreType is either ColdStoreUniversal or ColdStoreDiscard.
+	ColdStoreType string
+	// HotStoreFinality is the number of epochs objects are kept in the hot
+	// store, regardless of their reachability.
+	HotStoreFinality abi.ChainEpoch
+	// CompactionInterval is the minimum number of epochs between compactions.
+	CompactionInterval abi.ChainEpoch
+}
+
+// ChainAccessor is the subset of the ChainStore used by the splitstore.
+type ChainAccessor interface {
+	GetHeaviestTipSet() *types.TipSet
+	SubscribeHeadChanges(f func(rev, app []*types.TipSet) error)
+	WalkSnapshot(ctx context.Context, ts *types.TipSet, inclRecentRoots abi.ChainEpoch, skipOldMsgs bool, cb func(cid.Cid) error) error
+}
+
+// SplitStore is a blockstore with a hot and a cold part, see the package docs.
+type SplitStore struct {
+	cfg Config
+
+	// hot is cached like the chain blockstore is without the splitstore,
+	// hotGC is the underlying badger store, if any
+	hot   bstore.Blockstore
+	hotGC *badgerbs.Blockstore
+	cold  bstore.Blockstore
+
+	// tracker maps the multihash of each object in the hot store to the
+	// epoch it was written at. Objects missing from the tracker predate the
+	// last compaction.
+	tracker datastore.Batching
+	// ds persists the base epoch.
+	ds datastore.Datastore
+
+	// writeLk is held for reading by writes, and for writing while compaction
+	// removes objects from the hot store, so that objects written concurrently
+	// aren't lost.
+	writeLk sync.RWMutex
+
+	mx        sync.Mutex
+	chain     ChainAccessor
+	curEpoch  abi.ChainEpoch
+	baseEpoch abi.ChainEpoch
+
+	// touchLk guards the objects touched at touchEpoch, whose write epoch
+	// doesn't need to be checked again until the epoch changes
+	touchLk    sync.Mutex
+	touchEpoch abi.ChainEpoch
+	touched    map[string]struct{}
+
+	compacting int32
+	// markSetSize is the number of objects marked live by the last
+	// compaction, used to size the next mark set
+	markSetSize int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ bstore.Blockstore = (*SplitStore)(nil)
+
+// Open creates a splitstore over the given hot and cold stores. The tracker
+// datastore is owned by the splitstore, and closed by Close.
+func Open(hot, cold bstore.Blockstore, tracker datastore.Batching, ds datastore.Datastore, cfg Config) (*SplitStore, error) {
+	switch cfg.ColdStoreType {
+	case ColdStoreUniversal, ColdStoreDiscard:
+	default:
+		return nil, xerrors.Errorf("unknown cold store type %q", cfg.ColdStoreType)
+	}
+	if cfg.HotStoreFinality <= 0 {
+		return nil, xerrors.Errorf("hot store finality must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cached, err := bstore.CachedBlockstore(ctx, hot, bstore.DefaultCacheOpts())
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("caching hot store: %w", err)
+	}
+	hotGC, _ := hot.(*badgerbs.Blockstore)
+
+	return &SplitStore{
+		cfg:     cfg,
+		hot:     cached,
+		hotGC:   hotGC,
+		cold:    cold,
+		tracker: tracker,
+		ds:      ds,
+		touched: map[string]struct{}{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}, nil
+}
+
+// Start starts tracking the chain, and compacting the hot store as it
+// advances.
+func (s *SplitStore) Start(chain ChainAccessor) error {
+	head := chain.GetHeaviestTipSet()
+	if head == nil {
+		return xerrors.Errorf("chain has no head")
+	}
+
+	s.mx.Lock()
+	s.chain = chain
+	s.curEpoch = head.Height()
+
+	b, err := s.ds.Get(baseEpochKey)
+	switch err {
+	case nil:
+		s.baseEpoch = bytesToEpoch(b)
+	case datastore.ErrNotFound:
+		// nothing to compact before the splitstore was enabled
+		s.baseEpoch = head.Height()
+		err = s.ds.Put(baseEpochKey, epochToBytes(s.baseEpoch))
+	}
+	s.mx.Unlock()
+	if err != nil {
+		return xerrors.Errorf("loading base epoch: %w", err)
+	}
+
+	log.Infow("starting splitstore", "cold", s.cfg.ColdStoreType, "baseEpoch", s.baseEpoch, "head", head.Height())
+
+	chain.SubscribeHeadChanges(s.headChange)
+	return nil
+}
+
+// Close stops any running compaction and closes the tracker.
+func (s *SplitStore) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return s.tracker.Close()
+}
+
+// BaseEpoch returns the boundary of the last compaction.
+func (s *SplitStore) BaseEpoch() abi.ChainEpoch {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.baseEpoch
+}
+
+func (s *SplitStore) headChange(_, app []*types.TipSet) error {
+	if len(app) == 0 {
+		return nil
+	}
+	head := app[len(app)-1]
+
+	s.mx.Lock()
+	s.curEpoch = head.Height()
+	due := head.Height()-s.cfg.HotStoreFinality-s.baseEpoch >= s.cfg.CompactionInterval
+	s.mx.Unlock()
+
+	if !due || !atomic.CompareAndSwapInt32(&s.compacting, 0, 1) {
+		return nil
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer atomic.StoreInt32(&s.compacting, 0)
+
+		if err := s.compact(s.ctx, head); err != nil {
+			log.Errorf("compacting hot store: %+v", err)
+		}
+	}()
+	return nil
+}
+
+// compact moves or deletes the objects in the hot store that are unreachable
+// from the recent chain and older than the hot store finality.
+func (s *SplitStore) compact(ctx context.Context, head *types.TipSet) error {
+	start := time.Now()
+	boundary := head.Height() - s.cfg.HotStoreFinality
+	log.Infow("compacting hot store", "head", head.Height(), "boundary", boundary)
+
+	// mark everything reachable from the chain within the finality window;
+	// headers are always reachable. The mark set is a bloom filter, so that
+	// it doesn't grow with the size of the chain.
+	sizeHint := atomic.LoadInt64(&s.markSetSize) * 5 / 4
+	if sizeHint < minMarkSetSize {
+		sizeHint = minMarkSetSize
+	}
+	marked, err := bbloom.New(float64(sizeHint), markSetFalsePositive)
+	if err != nil {
+		return xerrors.Errorf("creating mark set: %w", err)
+	}
+
+	var live int64
+	err = s.chain.WalkSnapshot(ctx, head, s.cfg.HotStoreFinality, true, func(c cid.Cid) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		marked.Add(c.Hash())
+		live++
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("marking live objects: %w", err)
+	}
+	atomic.StoreInt64(&s.markSetSize, live)
+	log.Infow("marking done", "live", live, "took", time.Since(start))
+
+	kctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	keys, err := s.hot.AllKeysChan(kctx)
+	if err != nil {
+		return xerrors.Errorf("listing hot store: %w", err)
+	}
+
+	var dead []cid.Cid
+	for c := range keys {
+		if marked.Has(c.Hash()) {
+			continue
+		}
+		epoch, err := s.writeEpoch(c)
+		if err != nil {
+			return err
+		}
+		if epoch >= boundary {
+			continue
+		}
+		dead = append(dead, c)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	log.Infow("sweeping hot store", "dead", len(dead))
+
+	var purged int
+	for i := 0; i < len(dead); i += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := i + batchSize
+		if end > len(dead) {
+			end = len(dead)
+		}
+		n, err := s.purge(dead[i:end], boundary)
+		if err != nil {
+			return err
+		}
+		purged += n
+	}
+
+	s.mx.Lock()
+	s.baseEpoch = boundary
+	s.mx.Unlock()
+	if err := s.ds.Put(baseEpochKey, epochToBytes(boundary)); err != nil {
+		return xerrors.Errorf("saving base epoch: %w", err)
+	}
+
+	if s.hotGC != nil {
+		if err := s.hotGC.CollectGarbage(); err != nil {
+			log.Warnf("hot store value log gc: %s", err)
+		}
+	}
+
+	log.Infow("compaction done", "purged", purged, "took", time.Since(start))
+	return nil
+}
+
+// purge moves (or deletes) a batch of dead objects out of the hot store. Objects
+// rewritten since they were found dead are left in place.
+func (s *SplitStore) purge(batch []cid.Cid, boundary abi.ChainEpoch) (int, error) {
+	s.writeLk.Lock()
+	defer s.writeLk.Unlock()
+
+	var blks []blocks.Block
+	var purge []cid.Cid
+	for _, c := range batch {
+		epoch, err := s.writeEpoch(c)
+		if err != nil {
+			return 0, err
+		}
+		if epoch >= boundary {
+			continue
+		}
+
+		if s.cfg.ColdStoreType == ColdStoreUniversal {
+			blk, err := s.hot.Get(c)
+			if err == bstore.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return 0, xerrors.Errorf("getting %s from hot store: %w", c, err)
+			}
+			blks = append(blks, blk)
+		}
+		purge = append(purge, c)
+	}
+
+	if len(blks) > 0 {
+		if err := s.cold.PutMany(blks); err != nil {
+			return 0, xerrors.Errorf("moving objects to cold store: %w", err)
+		}
+	}
+
+	tb, err := s.tracker.Batch()
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range purge {
+		if err := s.hot.DeleteBlock(c); err != nil {
+			return 0, xerrors.Errorf("deleting %s from hot store: %w", c, err)
+		}
+		if err := tb.Delete(dshelp.MultihashToDsKey(c.Hash())); err != nil {
+			return 0, err
+		}
+	}
+	if err := tb.Commit(); err != nil {
+		return 0, xerrors.Errorf("updating tracker: %w", err)
+	}
+
+	return len(purge), nil
+}
+
+func (s *SplitStore) writeEpoch(c cid.Cid) (abi.ChainEpoch, error) {
+	b, err := s.tracker.Get(dshelp.MultihashToDsKey(c.Hash()))
+	switch err {
+	case nil:
+		return bytesToEpoch(b), nil
+	case datastore.ErrNotFound:
+		return 0, nil
+	default:
+		return 0, xerrors.Errorf("getting write epoch of %s: %w", c, err)
+	}
+}
+
+func (s *SplitStore) epoch() abi.ChainEpoch {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.curEpoch
+}
+
+func (s *SplitStore) DeleteBlock(c cid.Cid) error {
+	s.writeLk.RLock()
+	defer s.writeLk.RUnlock()
+
+	if err := s.hot.DeleteBlock(c); err != nil {
+		return err
+	}
+	if err := s.tracker.Delete(dshelp.MultihashToDsKey(c.Hash())); err != nil {
+		return err
+	}
+	return s.cold.DeleteBlock(c)
+}
+
+func (s *SplitStore) Has(c cid.Cid) (bool, error) {
+	s.writeLk.RLock()
+	defer s.writeLk.RUnlock()
+
+	has, err := s.hot.Has(c)
+	if err != nil {
+		return false, err
+	}
+	if has {
+		return true, s.touch(c)
+	}
+	return s.cold.Has(c)
+}
+
+// touch refreshes the write epoch of an object in the hot store, see the
+// package docs. Objects are checked against the tracker once per epoch. The
+// caller must hold writeLk for reading.
+func (s *SplitStore) touch(c cid.Cid) error {
+	cur := s.epoch()
+	key := string(c.Hash())
+
+	s.touchLk.Lock()
+	if s.touchEpoch != cur {
+		s.touchEpoch = cur
+		s.touched = map[string]struct{}{}
+	}
+	_, done := s.touched[key]
+	s.touchLk.Unlock()
+	if done {
+		return nil
+	}
+
+	epoch, err := s.writeEpoch(c)
+	if err != nil {
+		return err
+	}
+	if epoch < cur {
+		if err := s.tracker.Put(dshelp.MultihashToDsKey(c.Hash()), epochToBytes(cur)); err != nil {
+			return xerrors.Errorf("tracking %s: %w", c, err)
+		}
+	}
+
+	s.touchLk.Lock()
+	if s.touchEpoch == cur {
+		s.touched[key] = struct{}{}
+	}
+	s.touchLk.Unlock()
+	return nil
+}
+
+func (s *SplitStore) Get(c cid.Cid) (blocks.Block, error) {
+	blk, err := s.hot.Get(c)
+	if err == bstore.ErrNotFound {
+		return s.cold.Get(c)
+	}
+	return blk, err
+}
+
+func (s *SplitStore) GetSize(c cid.Cid) (int, error) {
+	size, err := s.hot.GetSize(c)
+	if err == bstore.ErrNotFound {
+		return s.cold.GetSize(c)
+	}
+	return size, err
+}
+
+func (s *SplitStore) View(c cid.Cid, cb func([]byte) error) error {
+	err := view(s.hot, c, cb)
+	if err == bstore.ErrNotFound {
+		return view(s.cold, c, cb)
+	}
+	return err
+}
+
+func view(bs bstore.Blockstore, c cid.Cid, cb func([]byte) error) error {
+	if v, ok := bs.(bstore.Viewer); ok {
+		return v.View(c, cb)
+	}
+	blk, err := bs.Get(c)
+	if err != nil {
+		return err
+	}
+	return cb(blk.RawData())
+}
+
+func (s *SplitStore) Put(blk blocks.Block) error {
+	return s.PutMany([]blocks.Block{blk})
+}
+
+func (s *SplitStore) PutMany(blks []blocks.Block) error {
+	s.writeLk.RLock()
+	defer s.writeLk.RUnlock()
+
+	// track first, so that an object in the hot store is never older than
+	// its tracked epoch
+	eb := epochToBytes(s.epoch())
+	tb, err := s.tracker.Batch()
+	if err != nil {
+		return err
+	}
+	for _, blk := range blks {
+		if err := tb.Put(dshelp.MultihashToDsKey(blk.Cid().Hash()), eb); err != nil {
+			return err
+		}
+	}
+	if err := tb.Commit(); err != nil {
+		return xerrors.Errorf("tracking written objects: %w", err)
+	}
+
+	return s.hot.PutMany(blks)
+}
+
+func (s *SplitStore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	hot, err := s.hot.AllKeysChan(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	cold, err := s.cold.AllKeysChan(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	out := make(chan cid.Cid)
+	go func() {
+		defer cancel()
+		defer close(out)
+
+		for _, in := range []<-chan cid.Cid{hot, cold} {
+			for c := range in {
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (s *SplitStore) HashOnRead(enabled bool) {
+	s.hot.HashOnRead(enabled)
+	s.cold.HashOnRead(enabled)
+}
+
+func epochToBytes(e abi.ChainEpoch) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(buf, int64(e))
+	return buf[:n]
+}
+
+func bytesToEpoch(b []byte) abi.ChainEpoch {
+	e, _ := binary.Varint(b)
+	return abi.ChainEpoch(e)
+}
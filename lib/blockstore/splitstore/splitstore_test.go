@@ -0,0 +1,187 @@
+package splitstore
+
+import (
+	"context"
+	"testing"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
+	bstore "github.com/EpiK-Protocol/go-epik/lib/blockstore"
+)
+
+type mockChain struct {
+	head *types.TipSet
+	live []cid.Cid
+	// walked is called after marking
+	walked func()
+	subs   []func(rev, app []*types.TipSet) error
+}
+
+func (c *mockChain) GetHeaviestTipSet() *types.TipSet {
+	return c.head
+}
+
+func (c *mockChain) SubscribeHeadChanges(f func(rev, app []*types.TipSet) error) {
+	c.subs = append(c.subs, f)
+}
+
+func (c *mockChain) WalkSnapshot(ctx context.Context, ts *types.TipSet, inclRecentRoots abi.ChainEpoch, skipOldMsgs bool, cb func(cid.Cid) error) error {
+	for _, l := range c.live {
+		if err := cb(l); err != nil {
+			return err
+		}
+	}
+	if c.walked != nil {
+		c.walked()
+	}
+	return nil
+}
+
+func (c *mockChain) setHead(h abi.ChainEpoch) {
+	blk := mock.MkBlock(nil, 1, 1)
+	blk.Height = h
+	c.head = mock.TipSet(blk)
+}
+
+func TestSplitStoreCompaction(t *testing.T) {
+	for _, coldType := range []string{ColdStoreUniversal, ColdStoreDiscard} {
+		coldType := coldType
+		t.Run(coldType, func(t *testing.T) {
+			testSplitStoreCompaction(t, coldType)
+		})
+	}
+}
+
+func testSplitStoreCompaction(t *testing.T, coldType string) {
+	ctx := context.Background()
+
+	hot := bstore.NewTemporarySync()
+	cold := bstore.NewTemporarySync()
+	tracker := dssync.MutexWrap(datastore.NewMapDatastore())
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	ss, err := Open(hot, cold, tracker, ds, Config{
+		ColdStoreType:      coldType,
+		HotStoreFinality:   10,
+		CompactionInterval: 5,
+	})
+	require.NoError(t, err)
+
+	chain := &mockChain{}
+	chain.setHead(1)
+	require.NoError(t, ss.Start(chain))
+	require.Equal(t, abi.ChainEpoch(1), ss.BaseEpoch())
+
+	oldLive := blocks.NewBlock([]byte("old live"))
+	oldDead := blocks.NewBlock([]byte("old dead"))
+	oldRewritten := blocks.NewBlock([]byte("old rewritten"))
+	oldReferenced := blocks.NewBlock([]byte("old referenced"))
+	oldReferencedLate := blocks.NewBlock([]byte("old referenced while compacting"))
+	require.NoError(t, ss.PutMany([]blocks.Block{oldLive, oldDead, oldRewritten, oldReferenced, oldReferencedLate}))
+	chain.live = []cid.Cid{oldLive.Cid()}
+
+	coldOnly := blocks.NewBlock([]byte("cold"))
+	require.NoError(t, cold.Put(coldOnly))
+
+	// not due yet
+	chain.setHead(14)
+	require.NoError(t, ss.headChange(nil, []*types.TipSet{chain.head}))
+	recent := blocks.NewBlock([]byte("recent"))
+	require.NoError(t, ss.Put(recent))
+
+	// old objects written again, or found by the VM when flushing a new
+	// state, after the boundary
+	require.NoError(t, ss.Put(oldRewritten))
+	has, err := ss.Has(oldReferenced.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+
+	// an old object referenced by a state computed after marking
+	chain.walked = func() {
+		has, err := ss.Has(oldReferencedLate.Cid())
+		require.NoError(t, err)
+		require.True(t, has)
+	}
+
+	chain.setHead(16)
+	require.NoError(t, ss.compact(ctx, chain.head))
+	require.Equal(t, abi.ChainEpoch(6), ss.BaseEpoch())
+
+	for _, blk := range []blocks.Block{oldLive, recent, oldRewritten, oldReferenced, oldReferencedLate} {
+		has, err := hot.Has(blk.Cid())
+		require.NoError(t, err)
+		require.True(t, has)
+	}
+
+	has, err = hot.Has(oldDead.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+
+	has, err = ss.Has(oldDead.Cid())
+	require.NoError(t, err)
+	require.Equal(t, coldType == ColdStoreUniversal, has)
+
+	// reads fall back to the cold store
+	blk, err := ss.Get(coldOnly.Cid())
+	require.NoError(t, err)
+	require.Equal(t, coldOnly.RawData(), blk.RawData())
+
+	require.NoError(t, ss.Close())
+}
+
+// countingDs counts the reads of the tracker
+type countingDs struct {
+	datastore.Batching
+	gets int
+}
+
+func (d *countingDs) Get(k datastore.Key) ([]byte, error) {
+	d.gets++
+	return d.Batching.Get(k)
+}
+
+func TestSplitStoreTouchOncePerEpoch(t *testing.T) {
+	tracker := &countingDs{Batching: dssync.MutexWrap(datastore.NewMapDatastore())}
+
+	ss, err := Open(bstore.NewTemporarySync(), bstore.NewTemporarySync(), tracker, datastore.NewMapDatastore(), Config{
+		ColdStoreType:      ColdStoreDiscard,
+		HotStoreFinality:   10,
+		CompactionInterval: 5,
+	})
+	require.NoError(t, err)
+
+	chain := &mockChain{}
+	chain.setHead(1)
+	require.NoError(t, ss.Start(chain))
+
+	blk := blocks.NewBlock([]byte("block"))
+	require.NoError(t, ss.Put(blk))
+
+	hasAt := func(h abi.ChainEpoch, n int) {
+		chain.setHead(h)
+		require.NoError(t, ss.headChange(nil, []*types.TipSet{chain.head}))
+
+		tracker.gets = 0
+		for i := 0; i < n; i++ {
+			has, err := ss.Has(blk.Cid())
+			require.NoError(t, err)
+			require.True(t, has)
+		}
+		require.Equal(t, 1, tracker.gets)
+
+		epoch, err := ss.writeEpoch(blk.Cid())
+		require.NoError(t, err)
+		require.Equal(t, h, epoch)
+	}
+
+	hasAt(2, 3)
+	hasAt(3, 3)
+}
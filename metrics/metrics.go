@@ -28,6 +28,7 @@ var (
 	ReceivedFrom, _ = tag.NewKey("received_from")
 	Endpoint, _     = tag.NewKey("endpoint")
 	APIInterface, _ = tag.NewKey("api") // to distinguish between gateway api and full node api endpoint calls
+	Client, _       = tag.NewKey("client")
 )
 
 // Measures
@@ -57,6 +58,10 @@ var (
 	APIRequestDuration                  = stats.Float64("api/request_duration_ms", "Duration of API requests", stats.UnitMilliseconds)
 	VMFlushCopyDuration                 = stats.Float64("vm/flush_copy_ms", "Time spent in VM Flush Copy", stats.UnitMilliseconds)
 	VMFlushCopyCount                    = stats.Int64("vm/flush_copy_count", "Number of copied objects", stats.UnitDimensionless)
+	GatewayCalls                        = stats.Int64("gateway/calls", "Counter for gateway API calls", stats.UnitDimensionless)
+	GatewayRateLimited                  = stats.Int64("gateway/rate_limited", "Counter for gateway API calls rejected by the rate limiter", stats.UnitDimensionless)
+	GatewayCacheHit                     = stats.Int64("gateway/cache_hit", "Counter for gateway API calls served from the response cache", stats.UnitDimensionless)
+	GatewayCacheMiss                    = stats.Int64("gateway/cache_miss", "Counter for cacheable gateway API calls forwarded to the node", stats.UnitDimensionless)
 )
 
 var (
@@ -176,6 +181,26 @@ var (
 		Measure:     VMFlushCopyCount,
 		Aggregation: view.Sum(),
 	}
+	GatewayCallsView = &view.View{
+		Measure:     GatewayCalls,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Endpoint, Client},
+	}
+	GatewayRateLimitedView = &view.View{
+		Measure:     GatewayRateLimited,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Endpoint, Client},
+	}
+	GatewayCacheHitView = &view.View{
+		Measure:     GatewayCacheHit,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Endpoint},
+	}
+	GatewayCacheMissView = &view.View{
+		Measure:     GatewayCacheMiss,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Endpoint},
+	}
 )
 
 // DefaultViews is an array of OpenCensus views for metric gathering purposes
@@ -207,6 +232,14 @@ var DefaultViews = append([]*view.View{
 },
 	rpcmetrics.DefaultViews...)
 
+// GatewayViews are the views only recorded by the gateway
+var GatewayViews = []*view.View{
+	GatewayCallsView,
+	GatewayRateLimitedView,
+	GatewayCacheHitView,
+	GatewayCacheMissView,
+}
+
 // SinceInMilliseconds returns the duration of time since the provide time as a float64.
 func SinceInMilliseconds(startTime time.Time) float64 {
 	return float64(time.Since(startTime).Nanoseconds()) / 1e6
@@ -58,6 +58,7 @@ import (
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 	sealing "github.com/EpiK-Protocol/go-epik/extern/storage-sealing"
 	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/lib/blockstore/splitstore"
 	"github.com/EpiK-Protocol/go-epik/lib/peermgr"
 	_ "github.com/EpiK-Protocol/go-epik/lib/sigs/bls"
 	_ "github.com/EpiK-Protocol/go-epik/lib/sigs/secp"
@@ -139,6 +140,7 @@ const (
 	HandleDealsKey
 	HandleRetrievalKey
 	RunSectorServiceKey
+	RunSectorScrubberKey
 
 	// daemon
 	ExtractApiKey
@@ -146,6 +148,9 @@ const (
 	SettlePaymentChannelsKey
 	RunPeerTaggerKey
 	SetupFallbackBlockstoreKey
+	StartSplitstoreKey
+	RunMpoolAutoBumpKey
+	RunMpoolGapFillerKey
 
 	SetApiEndpointKey
 
@@ -363,6 +368,7 @@ func Online() Option {
 
 			Override(new(*sectorblocks.SectorBlocks), sectorblocks.NewSectorBlocks),
 			Override(new(*storage.Miner), modules.StorageMiner(config.DefaultStorageMiner().Fees)),
+			Override(new(*storage.WindowPoStScheduler), modules.WindowPostScheduler(config.DefaultStorageMiner().Fees, config.DefaultStorageMiner().Proving)),
 			Override(new(*storage.AddressSelector), modules.AddressSelector(nil)),
 			Override(new(dtypes.NetworkName), modules.StorageNetworkName),
 
@@ -485,6 +491,22 @@ func ConfigFullNode(c interface{}) Option {
 		),
 		Override(new(dtypes.Graphsync), modules.Graphsync(cfg.Client.SimultaneousTransfers)),
 
+		If(cfg.Chainstore.EnableSplitstore,
+			Override(new(*splitstore.SplitStore), modules.SplitBlockstore(&cfg.Chainstore)),
+			Override(new(dtypes.ChainRawBlockstore), modules.SplitChainRawBlockstore),
+			Override(StartSplitstoreKey, modules.StartSplitstore),
+		),
+		If(cfg.Chainstore.EnableMsgIndex,
+			Override(new(*store.MsgIndex), modules.MsgIndex),
+		),
+
+		If(cfg.Fees.AutoBump,
+			Override(RunMpoolAutoBumpKey, modules.RunMpoolAutoBump(&cfg.Fees)),
+		),
+		If(cfg.Wallet.AutoFillNonceGaps,
+			Override(RunMpoolGapFillerKey, modules.RunMpoolGapFiller),
+		),
+
 		If(cfg.Metrics.HeadNotifs,
 			Override(HeadMetricsKey, metrics.SendHeadNotifs(cfg.Metrics.Nickname)),
 		),
@@ -524,6 +546,8 @@ func ConfigStorageMiner(c interface{}) Option {
 		Override(new(sectorstorage.SealerConfig), cfg.Storage),
 		Override(new(*storage.AddressSelector), modules.AddressSelector(&cfg.Addresses)),
 		Override(new(*storage.Miner), modules.StorageMiner(cfg.Fees)),
+		Override(new(*storage.WindowPoStScheduler), modules.WindowPostScheduler(cfg.Fees, cfg.Proving)),
+		Override(RunSectorScrubberKey, modules.RunSectorScrubber(cfg.Scrub)),
 	)
 }
 
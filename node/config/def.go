@@ -6,6 +6,7 @@ import (
 
 	"github.com/ipfs/go-cid"
 
+	"github.com/EpiK-Protocol/go-epik/build"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	sectorstorage "github.com/EpiK-Protocol/go-epik/extern/sector-storage"
 )
@@ -20,10 +21,11 @@ type Common struct {
 // FullNode is a full node config
 type FullNode struct {
 	Common
-	Client  Client
-	Metrics Metrics
-	Wallet  Wallet
-	Fees    FeeConfig
+	Client     Client
+	Metrics    Metrics
+	Wallet     Wallet
+	Fees       FeeConfig
+	Chainstore Chainstore
 }
 
 // // Common
@@ -37,6 +39,8 @@ type StorageMiner struct {
 	Storage    sectorstorage.SealerConfig
 	Fees       MinerFeeConfig
 	Addresses  MinerAddressConfig
+	Scrub      ScrubConfig
+	Proving    ProvingConfig
 }
 
 type DealmakingConfig struct {
@@ -66,6 +70,32 @@ type SealingConfig struct {
 	MaxSealingSectorsForDeals uint64
 
 	WaitDealsDelay Duration
+
+	// Retry policies of failed sector states, keyed by state name (e.g.
+	// SealPreCommit1Failed). States without a policy use DefaultRetryPolicy.
+	RetryPolicies      map[string]RetryPolicy
+	DefaultRetryPolicy RetryPolicy
+
+	// Command run when a sector runs out of retries, with the sector number,
+	// failed state and number of retries as arguments
+	RetryExhaustedHook string
+
+	// URL receiving a JSON POST for every sector state transition
+	StateWebhook string
+}
+
+type RetryPolicy struct {
+	// 0 = no limit
+	MaxAttempts uint64
+
+	// Wait before retrying, doubled after every retry up to MaxBackoff
+	// (0 = no limit)
+	Backoff    Duration
+	MaxBackoff Duration
+
+	// What to do once MaxAttempts is reached: pause (the default), remove or
+	// terminate
+	OnExhausted string
 }
 
 type MinerFeeConfig struct {
@@ -82,6 +112,32 @@ type MinerAddressConfig struct {
 	CommitControl    []string
 }
 
+// ScrubConfig configures the background check of the sectors stored in the
+// local storage paths, which catches damaged sector files before they fail a
+// window PoSt
+type ScrubConfig struct {
+	Enable bool
+
+	// Pause between two passes over all the sectors
+	Interval Duration
+	// Pause between two sector checks, limiting the disk load
+	SectorDelay Duration
+}
+
+// ProvingConfig configures the computation of the window PoSts
+type ProvingConfig struct {
+	// Number of partition batches, each proven with a single proof and
+	// submitted in its own message, computed at once. Batches go to the
+	// workers with the window PoSt task enabled, the ones computed by the
+	// miner itself are proven one at a time.
+	ParallelBatches int
+
+	// Maximum number of partitions in a batch, 0 for the network maximum.
+	// Smaller batches spread a deadline over more workers, at the cost of
+	// more messages.
+	MaxPartitionsPerPoStMessage int
+}
+
 // API contains configs for API endpoint
 type API struct {
 	ListenAddress       string
@@ -128,10 +184,39 @@ type Wallet struct {
 	RemoteBackend string
 	EnableLedger  bool
 	DisableLocal  bool
+
+	// AutoFillNonceGaps fills the nonce gaps of the pending messages sent
+	// from this node with zero-value self-sends.
+	AutoFillNonceGaps bool
 }
 
 type FeeConfig struct {
 	DefaultMaxFee types.EPK
+
+	// AutoBump replaces local messages which have been pending for
+	// BumpAfterEpochs with re-estimated gas values, spending up to
+	// DefaultMaxFee per message.
+	AutoBump        bool
+	BumpAfterEpochs uint64
+}
+
+type Chainstore struct {
+	EnableSplitstore bool
+	Splitstore       Splitstore
+
+	// index executed messages by sender and recipient as they are synced,
+	// which speeds up StateSearchMessages
+	EnableMsgIndex bool
+}
+
+type Splitstore struct {
+	// "universal" moves compacted objects to the cold store (the regular
+	// chain blockstore), "discard" deletes them
+	ColdStoreType string
+	// number of epochs objects are kept in the hot store
+	HotStoreFinality uint64
+	// minimum number of epochs between compactions
+	CompactionInterval uint64
 }
 
 func defCommon() Common {
@@ -169,11 +254,20 @@ func DefaultFullNode() *FullNode {
 	return &FullNode{
 		Common: defCommon(),
 		Fees: FeeConfig{
-			DefaultMaxFee: DefaultDefaultMaxFee,
+			DefaultMaxFee:   DefaultDefaultMaxFee,
+			BumpAfterEpochs: 20,
 		},
 		Client: Client{
 			SimultaneousTransfers: DefaultSimultaneousTransfers,
 		},
+		Chainstore: Chainstore{
+			EnableSplitstore: false,
+			Splitstore: Splitstore{
+				ColdStoreType:      "universal",
+				HotStoreFinality:   uint64(build.Finality),
+				CompactionInterval: uint64(build.Finality),
+			},
+		},
 	}
 }
 
@@ -186,6 +280,11 @@ func DefaultStorageMiner() *StorageMiner {
 			MaxSealingSectors:         0,
 			MaxSealingSectorsForDeals: 0,
 			WaitDealsDelay:            Duration(time.Hour * 6),
+
+			DefaultRetryPolicy: RetryPolicy{
+				Backoff:    Duration(time.Minute),
+				MaxBackoff: Duration(time.Minute),
+			},
 		},
 
 		Storage: sectorstorage.SealerConfig{
@@ -227,6 +326,16 @@ func DefaultStorageMiner() *StorageMiner {
 			PreCommitControl: []string{},
 			CommitControl:    []string{},
 		},
+
+		Scrub: ScrubConfig{
+			Enable:      true,
+			Interval:    Duration(24 * time.Hour),
+			SectorDelay: Duration(5 * time.Second),
+		},
+
+		Proving: ProvingConfig{
+			ParallelBatches: 4,
+		},
 	}
 	cfg.Common.API.ListenAddress = "/ip4/127.0.0.1/tcp/2345/http"
 	cfg.Common.API.RemoteListenAddress = "127.0.0.1:2345"
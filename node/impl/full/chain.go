@@ -552,12 +552,45 @@ func (a *ChainAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch, skipo
 	if err != nil {
 		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 	}
+
+	return exportStream(ctx, func(w io.Writer) error {
+		return a.Chain.Export(ctx, ts, nroots, skipoldmsgs, w)
+	}), nil
+}
+
+func (a *ChainAPI) ChainExportDelta(ctx context.Context, base types.TipSetKey, target types.TipSetKey) (<-chan []byte, error) {
+	bts, err := a.Chain.GetTipSetFromKey(base)
+	if err != nil {
+		return nil, xerrors.Errorf("loading base tipset %s: %w", base, err)
+	}
+	tts, err := a.Chain.GetTipSetFromKey(target)
+	if err != nil {
+		return nil, xerrors.Errorf("loading target tipset %s: %w", target, err)
+	}
+
+	return exportStream(ctx, func(w io.Writer) error {
+		return a.Chain.ExportDelta(ctx, bts, tts, w)
+	}), nil
+}
+
+func (a *ChainAPI) ChainStateIndexBackfill(ctx context.Context, tsk types.TipSetKey, toHeight abi.ChainEpoch) (int, error) {
+	ts, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return 0, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	return a.Chain.BackfillStateIndex(ctx, ts, toHeight)
+}
+
+// exportStream runs export in the background, and streams what it writes in
+// chunks. A final empty chunk signals the export completed successfully.
+func exportStream(ctx context.Context, export func(w io.Writer) error) <-chan []byte {
 	r, w := io.Pipe()
 	out := make(chan []byte)
 	go func() {
 		bw := bufio.NewWriterSize(w, 1<<20)
 
-		err := a.Chain.Export(ctx, ts, nroots, skipoldmsgs, bw)
+		err := export(bw)
 		bw.Flush()            //nolint:errcheck // it is a write to a pipe
 		w.CloseWithError(err) //nolint:errcheck // it is a pipe
 	}()
@@ -593,5 +626,5 @@ func (a *ChainAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch, skipo
 		}
 	}()
 
-	return out, nil
+	return out
 }
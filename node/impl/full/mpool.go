@@ -3,13 +3,18 @@ package full
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	"go.uber.org/fx"
+	"go.uber.org/multierr"
 	"golang.org/x/xerrors"
 
 	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin"
 	"github.com/EpiK-Protocol/go-epik/chain/messagepool"
 	"github.com/EpiK-Protocol/go-epik/chain/messagesigner"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
@@ -42,6 +47,22 @@ type MpoolAPI struct {
 	MessageSigner *messagesigner.MessageSigner
 
 	PushLocks *dtypes.MpoolLocker
+	GetMaxFee dtypes.DefaultMaxFeeFunc
+}
+
+// MessageBump describes the replacement of a stuck message.
+type MessageBump struct {
+	From       address.Address
+	Nonce      uint64
+	Old        cid.Cid
+	New        cid.Cid
+	OldPremium abi.TokenAmount
+	NewPremium abi.TokenAmount
+	OldFeeCap  abi.TokenAmount
+	NewFeeCap  abi.TokenAmount
+
+	// Skipped is why the message wasn't replaced, New is undefined then
+	Skipped string
 }
 
 func (a *MpoolAPI) MpoolGetConfig(context.Context) (*types.MpoolConfig, error) {
@@ -211,16 +232,260 @@ func (a *MpoolAPI) MpoolBatchPushUntrusted(ctx context.Context, smsgs []*types.S
 	return messageCids, nil
 }
 
-func (a *MpoolAPI) MpoolBatchPushMessage(ctx context.Context, msgs []*types.Message, spec *api.MessageSendSpec) ([]*types.SignedMessage, error) {
-	var smsgs []*types.SignedMessage
-	for _, msg := range msgs {
-		smsg, err := a.MpoolPushMessage(ctx, msg, spec)
+func (a *MpoolAPI) MpoolBatchPushMessage(ctx context.Context, msgs []*types.Message, spec *api.MessageSendSpec) ([]api.MessagePushResult, error) {
+	results := make([]api.MessagePushResult, len(msgs))
+
+	// group the messages by sender, keeping their order
+	var senders []address.Address
+	bySender := make(map[address.Address][]int)
+	for i, msg := range msgs {
+		if msg.Nonce != 0 {
+			results[i].Error = xerrors.Errorf("MpoolBatchPushMessage expects message nonce to be 0, was %d", msg.Nonce).Error()
+			continue
+		}
+
+		fromA, err := a.Stmgr.ResolveToKeyAddress(ctx, msg.From, nil)
+		if err != nil {
+			results[i].Error = xerrors.Errorf("getting key address: %w", err).Error()
+			continue
+		}
+
+		if _, ok := bySender[fromA]; !ok {
+			senders = append(senders, fromA)
+		}
+		bySender[fromA] = append(bySender[fromA], i)
+	}
+
+	for _, from := range senders {
+		if err := a.batchPushFrom(ctx, from, msgs, bySender[from], spec, results); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// batchPushFrom pushes the messages at idxs, all sent from the given key
+// address, filling their results. Gas is estimated for all of them before the
+// first one is signed, so that they get contiguous nonces.
+func (a *MpoolAPI) batchPushFrom(ctx context.Context, from address.Address, msgs []*types.Message, idxs []int, spec *api.MessageSendSpec, results []api.MessagePushResult) error {
+	done, err := a.PushLocks.TakeLock(ctx, from)
+	if err != nil {
+		return xerrors.Errorf("taking lock: %w", err)
+	}
+	defer done()
+
+	balance, err := a.WalletBalance(ctx, from)
+	if err != nil {
+		for _, i := range idxs {
+			results[i].Error = xerrors.Errorf("mpool push: getting origin balance: %w", err).Error()
+		}
+		return nil
+	}
+
+	var toSign []*types.Message
+	var signIdxs []int
+	value := big.Zero()
+	for _, i := range idxs {
+		msg := *msgs[i]
+		msg.From = from
+
+		est, err := a.GasAPI.GasEstimateMessageGas(ctx, &msg, spec, types.EmptyTSK)
+		if err != nil {
+			results[i].Error = xerrors.Errorf("GasEstimateMessageGas error: %w", err).Error()
+			continue
+		}
+
+		if est.GasPremium.GreaterThan(est.GasFeeCap) {
+			results[i].Error = xerrors.Errorf("after estimation, GasPremium %s is greater than GasFeeCap %s", est.GasPremium, est.GasFeeCap).Error()
+			continue
+		}
+
+		if balance.LessThan(big.Add(value, est.Value)) {
+			results[i].Error = xerrors.Errorf("mpool push: not enough funds: %s < %s", balance, big.Add(value, est.Value)).Error()
+			continue
+		}
+		value = big.Add(value, est.Value)
+
+		toSign = append(toSign, est)
+		signIdxs = append(signIdxs, i)
+	}
+
+	smsgs, err := a.MessageSigner.SignMessages(ctx, toSign, func(smsg *types.SignedMessage) error {
+		if _, err := a.MpoolModuleAPI.MpoolPush(ctx, smsg); err != nil {
+			return xerrors.Errorf("mpool push: failed to push message: %w", err)
+		}
+		return nil
+	})
+
+	for k, i := range signIdxs {
+		switch {
+		case k < len(smsgs):
+			results[i].Message = smsgs[k]
+		case k == len(smsgs):
+			results[i].Error = err.Error()
+		default:
+			results[i].Error = "not pushed, an earlier message of the sender failed"
+		}
+	}
+
+	return nil
+}
+
+func (a *MpoolAPI) MpoolBumpStuck(ctx context.Context, from address.Address, maxFee types.BigInt) ([]cid.Cid, error) {
+	if from == address.Undef {
+		return nil, xerrors.New("sender address required")
+	}
+
+	bumps, err := a.BumpPending(ctx, from, maxFee, nil)
+
+	out := make([]cid.Cid, 0, len(bumps))
+	var skipped error
+	for _, b := range bumps {
+		if b.Skipped != "" {
+			skipped = multierr.Append(skipped, xerrors.Errorf("message with nonce %d not replaced: %s", b.Nonce, b.Skipped))
+			continue
+		}
+		out = append(out, b.New)
+	}
+	if err != nil {
+		return out, err
+	}
+	return out, skipped
+}
+
+// BumpPending replaces the pending messages of the sender for which stuck
+// returns true, or all of them if it is nil, in nonce order. Messages are
+// repriced as by `mpool replace --auto`, and the replacements are signed and
+// pushed. Messages which can't be repriced enough to replace them within
+// maxFee are skipped, and returned with the reason in Skipped. On error, the
+// bumps done so far are returned.
+func (a *MpoolAPI) BumpPending(ctx context.Context, from address.Address, maxFee abi.TokenAmount, stuck func(*types.SignedMessage) bool) ([]MessageBump, error) {
+	fromA, err := a.Stmgr.ResolveToKeyAddress(ctx, from, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("getting key address: %w", err)
+	}
+
+	// keep MpoolPushMessage from assigning nonces while replacing
+	done, err := a.PushLocks.TakeLock(ctx, fromA)
+	if err != nil {
+		return nil, xerrors.Errorf("taking lock: %w", err)
+	}
+	defer done()
+
+	pending, _ := a.Mpool.PendingFor(fromA)
+
+	var out []MessageBump
+	for _, sm := range pending {
+		if stuck != nil && !stuck(sm) {
+			continue
+		}
+
+		msg := sm.Message
+		spec := &api.MessageSendSpec{MaxFee: maxFee}
+
+		msg.GasFeeCap = abi.NewTokenAmount(0)
+		msg.GasPremium = abi.NewTokenAmount(0)
+		est, err := a.GasAPI.GasEstimateMessageGas(ctx, &msg, spec, types.EmptyTSK)
+		if err != nil {
+			return out, xerrors.Errorf("estimating gas of message with nonce %d: %w", sm.Message.Nonce, err)
+		}
+
+		minRBF := messagepool.ComputeMinRBF(sm.Message.GasPremium)
+		msg.GasPremium = big.Max(est.GasPremium, minRBF)
+		msg.GasFeeCap = big.Max(est.GasFeeCap, msg.GasPremium)
+		messagepool.CapGasFee(a.GetMaxFee, &msg, spec)
+
+		// the mpool would refuse the replacement
+		if msg.GasPremium.LessThan(minRBF) {
+			out = append(out, MessageBump{
+				From:       fromA,
+				Nonce:      msg.Nonce,
+				Old:        sm.Cid(),
+				OldPremium: sm.Message.GasPremium,
+				NewPremium: msg.GasPremium,
+				OldFeeCap:  sm.Message.GasFeeCap,
+				NewFeeCap:  msg.GasFeeCap,
+				Skipped:    fmt.Sprintf("max fee caps the premium at %s, below the replace-by-fee minimum %s", msg.GasPremium, minRBF),
+			})
+			continue
+		}
+
+		smsg, err := a.WalletSignMessage(ctx, msg.From, &msg)
+		if err != nil {
+			return out, xerrors.Errorf("signing replacement of message with nonce %d: %w", msg.Nonce, err)
+		}
+
+		if _, err := a.Mpool.Push(smsg); err != nil {
+			return out, xerrors.Errorf("pushing replacement of message with nonce %d: %w", msg.Nonce, err)
+		}
+
+		out = append(out, MessageBump{
+			From:       fromA,
+			Nonce:      msg.Nonce,
+			Old:        sm.Cid(),
+			New:        smsg.Cid(),
+			OldPremium: sm.Message.GasPremium,
+			NewPremium: smsg.Message.GasPremium,
+			OldFeeCap:  sm.Message.GasFeeCap,
+			NewFeeCap:  smsg.Message.GasFeeCap,
+		})
+	}
+
+	return out, nil
+}
+
+func (a *MpoolAPI) MpoolCheckGaps(ctx context.Context) ([]api.NonceGap, error) {
+	return a.Mpool.NonceGaps()
+}
+
+func (a *MpoolAPI) MpoolFillGap(ctx context.Context, gap api.NonceGap) ([]cid.Cid, error) {
+	return a.FillNonceGap(ctx, gap)
+}
+
+const fillerGasLimit = 1000000
+
+// FillNonceGap pushes zero-value self-sends with the missing nonces of the
+// gap, so that the pending messages above it can be included. It returns the
+// cids of the pushed messages, also on error.
+func (a *MpoolAPI) FillNonceGap(ctx context.Context, gap api.NonceGap) ([]cid.Cid, error) {
+	done, err := a.PushLocks.TakeLock(ctx, gap.From)
+	if err != nil {
+		return nil, xerrors.Errorf("taking lock: %w", err)
+	}
+	defer done()
+
+	var out []cid.Cid
+	for _, nonce := range gap.Missing {
+		msg := &types.Message{
+			From:   gap.From,
+			To:     gap.From,
+			Value:  types.NewInt(0),
+			Nonce:  nonce,
+			Method: builtin.MethodSend,
+			// like `epik-shed noncefix`, the limit isn't estimated, as the
+			// pending messages above the gap can't be applied before it
+			GasLimit: fillerGasLimit,
+		}
+
+		msg, err := a.GasAPI.GasEstimateMessageGas(ctx, msg, nil, types.EmptyTSK)
+		if err != nil {
+			return out, xerrors.Errorf("estimating gas of filler with nonce %d: %w", nonce, err)
+		}
+
+		smsg, err := a.WalletSignMessage(ctx, msg.From, msg)
+		if err != nil {
+			return out, xerrors.Errorf("signing filler with nonce %d: %w", nonce, err)
+		}
+
+		c, err := a.Mpool.Push(smsg)
 		if err != nil {
-			return smsgs, err
+			return out, xerrors.Errorf("pushing filler with nonce %d: %w", nonce, err)
 		}
-		smsgs = append(smsgs, smsg)
+		out = append(out, c)
 	}
-	return smsgs, nil
+
+	return out, nil
 }
 
 func (a *MpoolAPI) MpoolGetNonce(ctx context.Context, addr address.Address) (uint64, error) {
@@ -87,6 +87,7 @@ type StateAPI struct {
 	StateManager  *stmgr.StateManager
 	Chain         *store.ChainStore
 	Beacon        beacon.Schedule
+	MsgIndex      *store.MsgIndex `optional:"true"`
 }
 
 func (a *StateAPI) StateNetworkName(ctx context.Context) (dtypes.NetworkName, error) {
@@ -484,7 +485,7 @@ func stateForTs(ctx context.Context, ts *types.TipSet, cstore *store.ChainStore,
 		ts = cstore.GetHeaviestTipSet()
 	}
 
-	st, _, err := smgr.TipSetState(ctx, ts)
+	st, _, err := smgr.IndexedTipSetState(ctx, ts)
 	if err != nil {
 		return nil, err
 	}
@@ -976,6 +977,59 @@ func (a *StateAPI) StateListMessages(ctx context.Context, match *api.MessageMatc
 	return out, nil
 }
 
+func (a *StateAPI) StateSearchMessages(ctx context.Context, filter *api.MessageFilter, fromHeight, toHeight abi.ChainEpoch, limit int) ([]api.IndexedMessage, error) {
+	head := a.Chain.GetHeaviestTipSet()
+	if toHeight <= 0 || toHeight >= head.Height() {
+		toHeight = head.Height()
+	}
+	if fromHeight > toHeight {
+		return nil, xerrors.Errorf("fromHeight %d is above toHeight %d", fromHeight, toHeight)
+	}
+
+	var out []api.IndexedMessage
+	if a.MsgIndex != nil {
+		base := a.MsgIndex.Base()
+		if toHeight >= base {
+			from := fromHeight
+			if from < base {
+				from = base
+			}
+
+			msgs, err := a.MsgIndex.Search(ctx, filter, from, toHeight, limit)
+			if err != nil {
+				return nil, err
+			}
+			if fromHeight >= base || (limit > 0 && len(msgs) >= limit) {
+				return msgs, nil
+			}
+
+			// messages below the base of the index are found by walking the
+			// chain
+			out = msgs
+			toHeight = base - 1
+			if limit > 0 {
+				limit -= len(msgs)
+			}
+		}
+	}
+
+	// the messages included at toHeight are executed in the next tipset
+	ts := head
+	if toHeight < head.Height() {
+		var err error
+		ts, err = a.Chain.GetTipsetByHeight(ctx, toHeight+1, head, false)
+		if err != nil {
+			return nil, xerrors.Errorf("loading tipset at %d: %w", toHeight+1, err)
+		}
+	}
+
+	msgs, err := a.Chain.ScanMessages(ctx, ts, filter, fromHeight, toHeight, limit)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, msgs...), nil
+}
+
 func (a *StateAPI) StateCompute(ctx context.Context, height abi.ChainEpoch, msgs []*types.Message, tsk types.TipSetKey) (*api.ComputeStateOutput, error) {
 	ts, err := a.Chain.GetTipSetFromKey(tsk)
 	if err != nil {
@@ -47,6 +47,7 @@ type StorageMinerAPI struct {
 	StorageProvider   storagemarket.StorageProvider
 	RetrievalProvider retrievalmarket.RetrievalProvider
 	Miner             *storage.Miner
+	WdPoSt            *storage.WindowPoStScheduler
 	BlockMiner        *miner.Miner
 	Full              api.FullNode
 	StorageMgr        *sectorstorage.Manager `optional:"true"`
@@ -117,6 +118,10 @@ func (sm *StorageMinerAPI) PledgeSector(ctx context.Context) error {
 	return sm.Miner.PledgeSector()
 }
 
+func (sm *StorageMinerAPI) SectorsNotify(ctx context.Context) (<-chan api.SectorStateChange, error) {
+	return sm.Miner.SectorsNotify(ctx), nil
+}
+
 func (sm *StorageMinerAPI) SectorsStatus(ctx context.Context, sid abi.SectorNumber, showOnChainInfo bool) (api.SectorInfo, error) {
 	info, err := sm.Miner.GetSectorInfo(sid)
 	if err != nil {
@@ -141,6 +146,11 @@ func (sm *StorageMinerAPI) SectorsStatus(ctx context.Context, sid abi.SectorNumb
 		}
 	}
 
+	retries := make(map[api.SectorState]uint64, len(info.Retries))
+	for _, r := range info.Retries {
+		retries[api.SectorState(r.State)] = r.Count
+	}
+
 	sInfo := api.SectorInfo{
 		SectorID: sid,
 		State:    api.SectorState(info.State),
@@ -159,6 +169,7 @@ func (sm *StorageMinerAPI) SectorsStatus(ctx context.Context, sid abi.SectorNumb
 		PreCommitMsg: info.PreCommitMessage,
 		CommitMsg:    info.CommitMessage,
 		Retries:      info.InvalidProofs,
+		StateRetries: retries,
 
 		LastErr: info.LastErr,
 		Log:     log,
@@ -284,6 +295,26 @@ func (sm *StorageMinerAPI) StorageStat(ctx context.Context, id stores.ID) (fsuti
 	return sm.StorageMgr.FsStat(ctx, id)
 }
 
+func (sm *StorageMinerAPI) StorageMoveSector(ctx context.Context, sector abi.SectorNumber, from, to stores.ID) error {
+	mid, err := address.IDFromAddress(sm.Miner.Address())
+	if err != nil {
+		return err
+	}
+
+	return sm.StorageMgr.MoveSectorStorage(ctx, abi.SectorID{
+		Miner:  abi.ActorID(mid),
+		Number: sector,
+	}, from, to)
+}
+
+func (sm *StorageMinerAPI) StorageSetMeta(ctx context.Context, id stores.ID, upd stores.StorageMetaUpdate) error {
+	return sm.StorageMgr.SetStorageMeta(ctx, id, upd)
+}
+
+func (sm *StorageMinerAPI) StorageScrubStatus(ctx context.Context) (storiface.ScrubStatus, error) {
+	return sm.StorageMgr.ScrubStatus(ctx)
+}
+
 func (sm *StorageMinerAPI) SectorStartSealing(ctx context.Context, number abi.SectorNumber) error {
 	return sm.Miner.StartPackingSector(number)
 }
@@ -354,6 +385,33 @@ func (sm *StorageMinerAPI) SealingAbort(ctx context.Context, call storiface.Call
 	return sm.StorageMgr.Abort(ctx, call)
 }
 
+func (sm *StorageMinerAPI) SealingSchedSetPriority(ctx context.Context, sector abi.SectorNumber, priority int) error {
+	// priorities are persisted, don't keep them around for sectors which
+	// won't run any sealing tasks
+	si, err := sm.Miner.GetSectorInfo(sector)
+	if err != nil {
+		return xerrors.Errorf("getting sector %d: %w", sector, err)
+	}
+	switch si.State {
+	case sealing.Proving, sealing.Removed:
+		return xerrors.Errorf("sector %d is already %s", sector, si.State)
+	}
+
+	mid, err := address.IDFromAddress(sm.Miner.Address())
+	if err != nil {
+		return err
+	}
+
+	return sm.StorageMgr.SetSectorPriority(ctx, abi.SectorID{
+		Miner:  abi.ActorID(mid),
+		Number: sector,
+	}, priority)
+}
+
+func (sm *StorageMinerAPI) SealingSchedQueue(ctx context.Context) ([]storiface.SchedQueueEntry, error) {
+	return sm.StorageMgr.SchedQueue(ctx)
+}
+
 func (sm *StorageMinerAPI) MarketImportDealData(ctx context.Context, propCid cid.Cid, path string) error {
 	fi, err := os.Open(path)
 	if err != nil {
@@ -624,6 +682,19 @@ func (sm *StorageMinerAPI) CheckProvable(ctx context.Context, pp abi.RegisteredP
 	return out, nil
 }
 
+func (sm *StorageMinerAPI) ComputeWindowPoSt(ctx context.Context, dlIdx uint64, tsk types.TipSetKey) (*api.WindowPoStDryRun, error) {
+	var ts *types.TipSet
+	if tsk != types.EmptyTSK {
+		var err error
+		ts, err = sm.Full.ChainGetTipSet(ctx, tsk)
+		if err != nil {
+			return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+		}
+	}
+
+	return sm.WdPoSt.ComputePoSt(ctx, dlIdx, ts)
+}
+
 func (sm *StorageMinerAPI) ActorAddressConfig(ctx context.Context) (api.AddressConfig, error) {
 	return sm.AddrSel.AddressConfig, nil
 }
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/ipfs/go-bitswap"
 	"github.com/ipfs/go-bitswap/network"
 	"github.com/ipfs/go-blockservice"
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	badger "github.com/ipfs/go-ds-badger2"
 	"github.com/ipld/go-car"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/routing"
@@ -17,6 +20,8 @@ import (
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/go-state-types/abi"
+
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/ffiwrapper"
 	"github.com/EpiK-Protocol/go-epik/journal"
 
@@ -31,8 +36,11 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/chain/vm"
 	"github.com/EpiK-Protocol/go-epik/lib/blockstore"
+	badgerbs "github.com/EpiK-Protocol/go-epik/lib/blockstore/badger"
+	"github.com/EpiK-Protocol/go-epik/lib/blockstore/splitstore"
 	"github.com/EpiK-Protocol/go-epik/lib/bufbstore"
 	"github.com/EpiK-Protocol/go-epik/lib/timedbs"
+	"github.com/EpiK-Protocol/go-epik/node/config"
 	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
 	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
 	"github.com/EpiK-Protocol/go-epik/node/repo"
@@ -91,6 +99,81 @@ func ChainRawBlockstore(lc fx.Lifecycle, mctx helpers.MetricsCtx, r repo.LockedR
 	return cbs, nil
 }
 
+// SplitBlockstore opens the hot/cold splitstore. The regular chain blockstore
+// of the repo is used as the cold store.
+func SplitBlockstore(cfg *config.Chainstore) func(lc fx.Lifecycle, r repo.LockedRepo, ds dtypes.MetadataDS) (*splitstore.SplitStore, error) {
+	return func(lc fx.Lifecycle, r repo.LockedRepo, ds dtypes.MetadataDS) (*splitstore.SplitStore, error) {
+		path, err := r.SplitstorePath()
+		if err != nil {
+			return nil, err
+		}
+
+		cold, err := r.Blockstore(repo.BlockstoreChain)
+		if err != nil {
+			return nil, err
+		}
+
+		opts, err := repo.BadgerBlockstoreOptions(repo.BlockstoreChain, filepath.Join(path, "hot"), false)
+		if err != nil {
+			return nil, err
+		}
+		hot, err := badgerbs.Open(opts)
+		if err != nil {
+			return nil, xerrors.Errorf("opening hot store: %w", err)
+		}
+
+		tracker, err := badger.NewDatastore(filepath.Join(path, "tracker"), &badger.DefaultOptions)
+		if err != nil {
+			_ = hot.Close()
+			return nil, xerrors.Errorf("opening splitstore tracker: %w", err)
+		}
+
+		ss, err := splitstore.Open(hot, cold, tracker, ds, splitstore.Config{
+			ColdStoreType:      cfg.Splitstore.ColdStoreType,
+			HotStoreFinality:   abi.ChainEpoch(cfg.Splitstore.HotStoreFinality),
+			CompactionInterval: abi.ChainEpoch(cfg.Splitstore.CompactionInterval),
+		})
+		if err != nil {
+			_ = tracker.Close()
+			_ = hot.Close()
+			return nil, err
+		}
+
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				if err := ss.Close(); err != nil {
+					return err
+				}
+				return hot.Close()
+			},
+		})
+
+		return ss, nil
+	}
+}
+
+func SplitChainRawBlockstore(ss *splitstore.SplitStore) dtypes.ChainRawBlockstore {
+	return blockstore.WrapIDStore(ss)
+}
+
+// splitstoreChain adapts the ChainStore to the splitstore.ChainAccessor
+// interface, which can't depend on the chain store's types.
+type splitstoreChain struct {
+	*store.ChainStore
+}
+
+func (c splitstoreChain) SubscribeHeadChanges(f func(rev, app []*types.TipSet) error) {
+	c.ChainStore.SubscribeHeadChanges(f)
+}
+
+func StartSplitstore(lc fx.Lifecycle, ss *splitstore.SplitStore, cs *store.ChainStore) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return ss.Start(splitstoreChain{cs})
+		},
+	})
+}
+
 func ChainBlockService(bs dtypes.ChainRawBlockstore, rem dtypes.ChainBitswap) dtypes.ChainBlockService {
 	return blockservice.New(bs, rem)
 }
@@ -127,6 +210,10 @@ func ChainStore(lc fx.Lifecycle, bs dtypes.ChainBlockstore, lbs dtypes.ChainRawB
 	return chain
 }
 
+func MsgIndex(cs *store.ChainStore, ds dtypes.MetadataDS) (*store.MsgIndex, error) {
+	return store.NewMsgIndex(cs, namespace.Wrap(ds, datastore.NewKey("/msgindex")))
+}
+
 func ErrorGenesis() Genesis {
 	return func() (header *types.BlockHeader, e error) {
 		return nil, xerrors.New("No genesis block provided, provide the file with 'epik daemon --genesis=[genesis file]'")
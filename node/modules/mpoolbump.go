@@ -0,0 +1,101 @@
+package modules
+
+import (
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/messagepool"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+	"github.com/EpiK-Protocol/go-epik/node/impl/full"
+	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
+)
+
+// RunMpoolAutoBump starts a service replacing local messages which have been
+// pending for cfg.BumpAfterEpochs, see MpoolAPI.BumpPending. Every bump is
+// recorded in the journal as an mpool:bump event.
+func RunMpoolAutoBump(cfg *config.FeeConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, mp *messagepool.MessagePool, mpoolAPI full.MpoolAPI, cs *store.ChainStore, j journal.Journal) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, mp *messagepool.MessagePool, mpoolAPI full.MpoolAPI, cs *store.ChainStore, j journal.Journal) {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		evtType := j.RegisterEventType("mpool", "bump")
+		after := abi.ChainEpoch(cfg.BumpAfterEpochs)
+		blockDelay := time.Duration(build.BlockDelaySecs) * time.Second
+
+		// epoch at which each pending local message was first seen
+		seen := make(map[cid.Cid]abi.ChainEpoch)
+
+		check := func(head *types.TipSet) {
+			// messages don't get included while syncing
+			if build.Clock.Since(time.Unix(int64(head.MinTimestamp()), 0)) > blockDelay*time.Duration(after) {
+				return
+			}
+
+			live := make(map[cid.Cid]struct{})
+			for _, a := range mp.LocalAddresses() {
+				pending, _ := mp.PendingFor(a)
+
+				var stuck bool
+				for _, sm := range pending {
+					c := sm.Cid()
+					live[c] = struct{}{}
+					if _, ok := seen[c]; !ok {
+						seen[c] = head.Height()
+					}
+					stuck = stuck || head.Height()-seen[c] >= after
+				}
+				if !stuck {
+					continue
+				}
+
+				bumps, err := mpoolAPI.BumpPending(ctx, a, big.Zero(), func(sm *types.SignedMessage) bool {
+					first, ok := seen[sm.Cid()]
+					return ok && head.Height()-first >= after
+				})
+				for _, b := range bumps {
+					b := b
+					if b.Skipped != "" {
+						// give the fees time to change before trying again
+						seen[b.Old] = head.Height()
+						log.Warnw("not bumping stuck message", "from", b.From, "nonce", b.Nonce, "message", b.Old, "reason", b.Skipped)
+						continue
+					}
+					log.Infow("bumped stuck message", "from", b.From, "nonce", b.Nonce, "old", b.Old, "new", b.New, "premium", b.NewPremium, "feecap", b.NewFeeCap)
+					j.RecordEvent(evtType, func() interface{} {
+						return b
+					})
+				}
+				if err != nil {
+					log.Warnf("bumping stuck messages of %s: %s", a, err)
+				}
+			}
+
+			for c := range seen {
+				if _, ok := live[c]; !ok {
+					delete(seen, c)
+				}
+			}
+		}
+
+		go func() {
+			tk := build.Clock.Ticker(blockDelay)
+			defer tk.Stop()
+
+			for {
+				select {
+				case <-tk.C:
+					check(cs.GetHeaviestTipSet())
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
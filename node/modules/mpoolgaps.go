@@ -0,0 +1,91 @@
+package modules
+
+import (
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/messagepool"
+	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/node/impl/full"
+	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
+)
+
+type nonceGapFill struct {
+	From     address.Address
+	Nonces   []uint64
+	Messages []cid.Cid
+}
+
+// RunMpoolGapFiller starts a service filling the nonce gaps of local senders
+// with zero-value self-sends, see MpoolAPI.FillNonceGap. Only nonces missing
+// in two consecutive checks are filled, so that messages being pushed aren't
+// raced. Every fill is recorded in the journal as an mpool:fill event.
+func RunMpoolGapFiller(mctx helpers.MetricsCtx, lc fx.Lifecycle, mp *messagepool.MessagePool, mpoolAPI full.MpoolAPI, j journal.Journal) {
+	ctx := helpers.LifecycleCtx(mctx, lc)
+	evtType := j.RegisterEventType("mpool", "fill")
+
+	type senderNonce struct {
+		from  address.Address
+		nonce uint64
+	}
+	prev := make(map[senderNonce]struct{})
+
+	check := func() {
+		gaps, err := mp.NonceGaps()
+		if err != nil {
+			log.Warnf("checking nonce gaps: %s", err)
+			return
+		}
+
+		cur := make(map[senderNonce]struct{})
+		for _, gap := range gaps {
+			fill := api.NonceGap{From: gap.From, StateNonce: gap.StateNonce}
+			for _, n := range gap.Missing {
+				k := senderNonce{gap.From, n}
+				cur[k] = struct{}{}
+				if _, ok := prev[k]; ok {
+					fill.Missing = append(fill.Missing, n)
+				}
+			}
+			if len(fill.Missing) == 0 {
+				continue
+			}
+
+			cids, err := mpoolAPI.FillNonceGap(ctx, fill)
+			if len(cids) > 0 {
+				log.Infow("filled nonce gap", "from", fill.From, "nonces", fill.Missing[:len(cids)])
+				j.RecordEvent(evtType, func() interface{} {
+					return nonceGapFill{
+						From:     fill.From,
+						Nonces:   fill.Missing[:len(cids)],
+						Messages: cids,
+					}
+				})
+			}
+			if err != nil {
+				log.Warnf("filling nonce gap of %s: %s", fill.From, err)
+			}
+		}
+		prev = cur
+	}
+
+	go func() {
+		tk := build.Clock.Ticker(time.Duration(build.BlockDelaySecs) * time.Second)
+		defer tk.Stop()
+
+		for {
+			select {
+			case <-tk.C:
+				check()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
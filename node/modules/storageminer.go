@@ -213,12 +213,41 @@ func StorageMiner(fc config.MinerFeeConfig) func(params StorageMinerParams) (*st
 
 		ctx := helpers.LifecycleCtx(mctx, lc)
 
-		fps, err := storage.NewWindowedPoStScheduler(api, fc, as, sealer, sealer, j, maddr)
+		sm, err := storage.NewMiner(api, maddr, h, ds, sealer, sc, verif, gsd, fc, j, as)
 		if err != nil {
 			return nil, err
 		}
 
-		sm, err := storage.NewMiner(api, maddr, h, ds, sealer, sc, verif, gsd, fc, j, as)
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				return sm.Run(ctx)
+			},
+			OnStop: sm.Stop,
+		})
+
+		return sm, nil
+	}
+}
+
+func WindowPostScheduler(fc config.MinerFeeConfig, pc config.ProvingConfig) func(params StorageMinerParams) (*storage.WindowPoStScheduler, error) {
+	return func(params StorageMinerParams) (*storage.WindowPoStScheduler, error) {
+		var (
+			mctx   = params.MetricsCtx
+			lc     = params.Lifecycle
+			api    = params.API
+			sealer = params.Sealer
+			j      = params.Journal
+			as     = params.AddrSel
+		)
+
+		maddr, err := minerAddrFromDS(params.MetadataDS)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		fps, err := storage.NewWindowedPoStScheduler(api, fc, pc, as, sealer, sealer, j, maddr)
 		if err != nil {
 			return nil, err
 		}
@@ -226,12 +255,11 @@ func StorageMiner(fc config.MinerFeeConfig) func(params StorageMinerParams) (*st
 		lc.Append(fx.Hook{
 			OnStart: func(context.Context) error {
 				go fps.Run(ctx)
-				return sm.Run(ctx)
+				return nil
 			},
-			OnStop: sm.Stop,
 		})
 
-		return sm, nil
+		return fps, nil
 	}
 }
 
@@ -651,14 +679,16 @@ func RetrievalProvider(h host.Host,
 
 var WorkerCallsPrefix = datastore.NewKey("/worker/calls")
 var ManagerWorkPrefix = datastore.NewKey("/stmgr/calls")
+var SchedPriorityPrefix = datastore.NewKey("/stmgr/priorities")
 
 func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, ls stores.LocalStorage, si stores.SectorIndex, sc sectorstorage.SealerConfig, urls sectorstorage.URLs, sa sectorstorage.StorageAuth, ds dtypes.MetadataDS) (*sectorstorage.Manager, error) {
 	ctx := helpers.LifecycleCtx(mctx, lc)
 
 	wsts := statestore.New(namespace.Wrap(ds, WorkerCallsPrefix))
 	smsts := statestore.New(namespace.Wrap(ds, ManagerWorkPrefix))
+	spsts := statestore.New(namespace.Wrap(ds, SchedPriorityPrefix))
 
-	sst, err := sectorstorage.New(ctx, ls, si, sc, urls, sa, wsts, smsts)
+	sst, err := sectorstorage.New(ctx, ls, si, sc, urls, sa, wsts, smsts, spsts)
 	if err != nil {
 		return nil, err
 	}
@@ -670,6 +700,26 @@ func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, ls stores.LocalStor
 	return sst, nil
 }
 
+func RunSectorScrubber(cfg config.ScrubConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, m *sectorstorage.Manager, sm *storage.Miner) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, m *sectorstorage.Manager, sm *storage.Miner) {
+		if !cfg.Enable {
+			return
+		}
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go m.RunScrubber(ctx, sectorstorage.ScrubConfig{
+					Interval:    time.Duration(cfg.Interval),
+					SectorDelay: time.Duration(cfg.SectorDelay),
+				}, sm)
+				return nil
+			},
+		})
+	}
+}
+
 func StorageAuth(ctx helpers.MetricsCtx, ca lapi.Common) (sectorstorage.StorageAuth, error) {
 	token, err := ca.AuthNew(ctx, []auth.Permission{"admin"})
 	if err != nil {
@@ -815,6 +865,16 @@ func NewSetSealConfigFunc(r repo.LockedRepo) (dtypes.SetSealingConfigFunc, error
 				MaxSealingSectors:         cfg.MaxSealingSectors,
 				MaxSealingSectorsForDeals: cfg.MaxSealingSectorsForDeals,
 				WaitDealsDelay:            config.Duration(cfg.WaitDealsDelay),
+
+				DefaultRetryPolicy: toConfigRetryPolicy(cfg.DefaultRetryPolicy),
+				RetryExhaustedHook: cfg.RetryExhaustedHook,
+				StateWebhook:       cfg.StateWebhook,
+			}
+			if cfg.RetryPolicies != nil {
+				c.Sealing.RetryPolicies = map[string]config.RetryPolicy{}
+				for st, p := range cfg.RetryPolicies {
+					c.Sealing.RetryPolicies[st] = toConfigRetryPolicy(p)
+				}
 			}
 		})
 		return
@@ -829,12 +889,40 @@ func NewGetSealConfigFunc(r repo.LockedRepo) (dtypes.GetSealingConfigFunc, error
 				MaxSealingSectors:         cfg.Sealing.MaxSealingSectors,
 				MaxSealingSectorsForDeals: cfg.Sealing.MaxSealingSectorsForDeals,
 				WaitDealsDelay:            time.Duration(cfg.Sealing.WaitDealsDelay),
+
+				DefaultRetryPolicy: toSealingRetryPolicy(cfg.Sealing.DefaultRetryPolicy),
+				RetryExhaustedHook: cfg.Sealing.RetryExhaustedHook,
+				StateWebhook:       cfg.Sealing.StateWebhook,
+			}
+			if cfg.Sealing.RetryPolicies != nil {
+				out.RetryPolicies = map[string]sealiface.RetryPolicy{}
+				for st, p := range cfg.Sealing.RetryPolicies {
+					out.RetryPolicies[st] = toSealingRetryPolicy(p)
+				}
 			}
 		})
 		return
 	}, nil
 }
 
+func toConfigRetryPolicy(p sealiface.RetryPolicy) config.RetryPolicy {
+	return config.RetryPolicy{
+		MaxAttempts: p.MaxAttempts,
+		Backoff:     config.Duration(p.Backoff),
+		MaxBackoff:  config.Duration(p.MaxBackoff),
+		OnExhausted: p.OnExhausted,
+	}
+}
+
+func toSealingRetryPolicy(p config.RetryPolicy) sealiface.RetryPolicy {
+	return sealiface.RetryPolicy{
+		MaxAttempts: p.MaxAttempts,
+		Backoff:     time.Duration(p.Backoff),
+		MaxBackoff:  time.Duration(p.MaxBackoff),
+		OnExhausted: p.OnExhausted,
+	}
+}
+
 func NewSetExpectedSealDurationFunc(r repo.LockedRepo) (dtypes.SetExpectedSealDurationFunc, error) {
 	return func(delay time.Duration) (err error) {
 		err = mutateCfg(r, func(cfg *config.StorageMiner) {
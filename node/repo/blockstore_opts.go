@@ -44,7 +44,8 @@ func BadgerBlockstoreOptions(domain BlockstoreDomain, path string, readonly bool
 	opts.MaxTableSize = 64 << 20
 
 	// NOTE: The chain blockstore doesn't require any GC (blocks are never
-	// deleted). This will change if we move to a tiered blockstore.
+	// deleted), unless it's the hot store of the splitstore, which runs value
+	// log GC after compaction.
 
 	opts.ReadOnly = readonly
 
@@ -329,6 +329,14 @@ func (fsr *fsLockedRepo) Blockstore(domain BlockstoreDomain) (blockstore.Blockst
 	return fsr.bs, fsr.bsErr
 }
 
+func (fsr *fsLockedRepo) SplitstorePath() (string, error) {
+	path := fsr.join(filepath.Join(fsDatastore, "splitstore"))
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
 // join joins path elements with fsr.path
 func (fsr *fsLockedRepo) join(paths ...string) string {
 	return filepath.Join(append([]string{fsr.path}, paths...)...)
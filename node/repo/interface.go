@@ -56,6 +56,9 @@ type LockedRepo interface {
 	// Blockstore returns an IPLD blockstore for the requested domain.
 	Blockstore(domain BlockstoreDomain) (blockstore.Blockstore, error)
 
+	// SplitstorePath returns the path of the splitstore directory.
+	SplitstorePath() (string, error)
+
 	// Returns config in this repo
 	Config() (interface{}, error)
 	SetConfig(func(interface{})) error
@@ -251,6 +251,14 @@ func (lmem *lockedMemRepo) Blockstore(domain BlockstoreDomain) (blockstore.Block
 	return lmem.mem.blockstore, nil
 }
 
+func (lmem *lockedMemRepo) SplitstorePath() (string, error) {
+	path := filepath.Join(lmem.Path(), "splitstore")
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
 func (lmem *lockedMemRepo) ListDatastores(ns string) ([]int64, error) {
 	return nil, nil
 }
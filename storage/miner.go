@@ -57,6 +57,8 @@ type Miner struct {
 	sealingEvtType journal.EventType
 
 	journal journal.Journal
+
+	notifier *sectorNotifier
 }
 
 // SealingStateEvt is a journal event that records a sector state transition.
@@ -129,6 +131,7 @@ func NewMiner(api storageMinerApi, maddr address.Address, h host.Host, ds datast
 		getSealConfig:  gsd,
 		journal:        journal,
 		sealingEvtType: journal.RegisterEventType("storage", "sealing_states"),
+		notifier:       newSectorNotifier(),
 	}
 
 	return m, nil
@@ -162,6 +165,7 @@ func (m *Miner) Run(ctx context.Context) error {
 	m.sealing = sealing.New(adaptedAPI, fc, NewEventsAdapter(evts), m.maddr, m.ds, m.sealer, m.sc, m.verif, sealing.GetSealingConfigFunc(m.getSealConfig), m.handleSealingNotifications, as)
 
 	go m.sealing.Run(ctx) //nolint:errcheck // logged intside the function
+	go m.notifier.runWebhook(ctx, m.getSealConfig)
 
 	return nil
 }
@@ -176,6 +180,21 @@ func (m *Miner) handleSealingNotifications(before, after sealing.SectorInfo) {
 			Error:        after.LastErr,
 		}
 	})
+
+	if before.State != after.State {
+		change := api.SectorStateChange{
+			SectorNumber: after.SectorNumber,
+			From:         api.SectorState(before.State),
+			To:           api.SectorState(after.State),
+			Time:         time.Now(),
+		}
+		// LastErr sticks around after the sector recovers, only report it
+		// with the failure it comes from
+		if sealing.IsFailed(after.State) {
+			change.Error = after.LastErr
+		}
+		m.notifier.publish(change)
+	}
 }
 
 func (m *Miner) Stop(ctx context.Context) error {
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
+)
+
+const (
+	sectorNotifyBuffer = 128
+	webhookQueueSize   = 1024
+	webhookTimeout     = 10 * time.Second
+)
+
+// sectorNotifier fans sector state changes out to the SectorsNotify
+// subscribers and to the configured webhook. Slow consumers lose changes
+// rather than stalling the sealing state machines.
+type sectorNotifier struct {
+	lk   sync.Mutex
+	next uint64
+	subs map[uint64]chan api.SectorStateChange
+
+	webhook chan api.SectorStateChange
+}
+
+func newSectorNotifier() *sectorNotifier {
+	return &sectorNotifier{
+		subs:    map[uint64]chan api.SectorStateChange{},
+		webhook: make(chan api.SectorStateChange, webhookQueueSize),
+	}
+}
+
+func (n *sectorNotifier) subscribe(ctx context.Context) <-chan api.SectorStateChange {
+	ch := make(chan api.SectorStateChange, sectorNotifyBuffer)
+
+	n.lk.Lock()
+	id := n.next
+	n.next++
+	n.subs[id] = ch
+	n.lk.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		n.lk.Lock()
+		delete(n.subs, id)
+		close(ch)
+		n.lk.Unlock()
+	}()
+
+	return ch
+}
+
+func (n *sectorNotifier) publish(c api.SectorStateChange) {
+	n.lk.Lock()
+	for _, ch := range n.subs {
+		select {
+		case ch <- c:
+		default:
+			log.Warnf("sector notification subscriber too slow, dropping %d %s -> %s", c.SectorNumber, c.From, c.To)
+		}
+	}
+	n.lk.Unlock()
+
+	select {
+	case n.webhook <- c:
+	default:
+		log.Warnf("sector webhook queue full, dropping %d %s -> %s", c.SectorNumber, c.From, c.To)
+	}
+}
+
+// runWebhook posts the state changes to the webhook URL set in the sealing
+// config, if any.
+func (n *sectorNotifier) runWebhook(ctx context.Context, getCfg dtypes.GetSealingConfigFunc) {
+	client := &http.Client{Timeout: webhookTimeout}
+
+	for {
+		select {
+		case c := <-n.webhook:
+			cfg, err := getCfg()
+			if err != nil {
+				log.Errorf("sector webhook: getting sealing config: %+v", err)
+				continue
+			}
+			if cfg.StateWebhook == "" {
+				continue
+			}
+
+			if err := postStateChange(ctx, client, cfg.StateWebhook, c); err != nil {
+				log.Warnf("sector webhook: sector %d %s -> %s: %+v", c.SectorNumber, c.From, c.To, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func postStateChange(ctx context.Context, client *http.Client, url string, c api.SectorStateChange) error {
+	b, err := json.Marshal(&c)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return xerrors.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SectorsNotify returns a channel of the sector state transitions happening
+// until ctx is cancelled.
+func (m *Miner) SectorsNotify(ctx context.Context) <-chan api.SectorStateChange {
+	return m.notifier.subscribe(ctx)
+}
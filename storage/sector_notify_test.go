@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	sealing "github.com/EpiK-Protocol/go-epik/extern/storage-sealing"
+	"github.com/EpiK-Protocol/go-epik/extern/storage-sealing/sealiface"
+	"github.com/EpiK-Protocol/go-epik/journal"
+)
+
+func TestSectorNotifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type hook struct {
+		change api.SectorStateChange
+		err    error
+	}
+
+	hooked := make(chan hook, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var h hook
+		h.err = json.NewDecoder(r.Body).Decode(&h.change)
+		hooked <- h
+	}))
+	defer srv.Close()
+
+	n := newSectorNotifier()
+	go n.runWebhook(ctx, func() (sealiface.Config, error) {
+		return sealiface.Config{StateWebhook: srv.URL}, nil
+	})
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	ch := n.subscribe(subCtx)
+
+	change := api.SectorStateChange{SectorNumber: 3, From: "PreCommit1", To: "PreCommit2"}
+	n.publish(change)
+
+	require.Equal(t, change, <-ch)
+	h := <-hooked
+	require.NoError(t, h.err)
+	require.Equal(t, change, h.change)
+
+	subCancel()
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestSealingNotificationErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := &Miner{journal: journal.NilJournal(), notifier: newSectorNotifier()}
+	ch := m.notifier.subscribe(ctx)
+
+	m.handleSealingNotifications(
+		sealing.SectorInfo{SectorNumber: 4, State: sealing.PreCommit1},
+		sealing.SectorInfo{SectorNumber: 4, State: sealing.SealPreCommit1Failed, LastErr: "boom"})
+	c := <-ch
+	require.Equal(t, abi.SectorNumber(4), c.SectorNumber)
+	require.Equal(t, "boom", c.Error)
+
+	m.handleSealingNotifications(
+		sealing.SectorInfo{SectorNumber: 4, State: sealing.SealPreCommit1Failed, LastErr: "boom"},
+		sealing.SectorInfo{SectorNumber: 4, State: sealing.PreCommit1, LastErr: "boom"})
+	c = <-ch
+	require.Equal(t, api.SectorState(sealing.PreCommit1), c.To)
+	require.Empty(t, c.Error)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/dline"
+	"github.com/filecoin-project/specs-storage/storage"
+
+	sectorstorage "github.com/EpiK-Protocol/go-epik/extern/sector-storage"
+)
+
+// ScrubSectors lists the active sectors of the miner with their next proving
+// deadline, for the sector integrity scrubber.
+func (m *Miner) ScrubSectors(ctx context.Context) ([]sectorstorage.ScrubSector, error) {
+	mid, err := address.IDFromAddress(m.maddr)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := m.api.ChainHead(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	di, err := m.api.StateMinerProvingDeadline(ctx, m.maddr, ts.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting proving deadline: %w", err)
+	}
+
+	var out []sectorstorage.ScrubSector
+	for dlIdx := uint64(0); dlIdx < di.WPoStPeriodDeadlines; dlIdx++ {
+		partitions, err := m.api.StateMinerPartitions(ctx, m.maddr, dlIdx, ts.Key())
+		if err != nil {
+			return nil, xerrors.Errorf("getting partitions of deadline %d: %w", dlIdx, err)
+		}
+
+		open := nextDeadlineOpen(di, dlIdx)
+
+		for _, partition := range partitions {
+			sectors, err := m.api.StateMinerSectors(ctx, m.maddr, &partition.ActiveSectors, ts.Key())
+			if err != nil {
+				return nil, xerrors.Errorf("getting sectors of deadline %d: %w", dlIdx, err)
+			}
+
+			for _, info := range sectors {
+				out = append(out, sectorstorage.ScrubSector{
+					Ref: storage.SectorRef{
+						ID: abi.SectorID{
+							Miner:  abi.ActorID(mid),
+							Number: info.SectorNumber,
+						},
+						ProofType: info.SealProof,
+					},
+					CommR:        info.SealedCID,
+					Deadline:     dlIdx,
+					DeadlineOpen: open,
+				})
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// nextDeadlineOpen returns the first epoch of the current or next proving
+// window of the deadline.
+func nextDeadlineOpen(di *dline.Info, dlIdx uint64) abi.ChainEpoch {
+	open := di.PeriodStart + abi.ChainEpoch(dlIdx)*di.WPoStChallengeWindow
+	if dlIdx < di.Index {
+		open += di.WPoStProvingPeriod
+	}
+	return open
+}
+
+var _ sectorstorage.ScrubSource = &Miner{}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// ComputePoSt runs the sector checks and the proof generation of runPost for
+// the partitions of a deadline, one partition at a time so that each one gets
+// timed, and returns the results instead of submitting the proofs. The
+// challenge is taken at the tipset height, as the challenge epoch of the
+// deadline may not be reached yet.
+func (s *WindowPoStScheduler) ComputePoSt(ctx context.Context, dlIdx uint64, ts *types.TipSet) (*api.WindowPoStDryRun, error) {
+	if ts == nil {
+		var err error
+		ts, err = s.api.ChainHead(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("getting chain head: %w", err)
+		}
+	}
+
+	di, err := s.api.StateMinerProvingDeadline(ctx, s.actor, ts.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting proving deadline: %w", err)
+	}
+	if dlIdx >= di.WPoStPeriodDeadlines {
+		return nil, xerrors.Errorf("deadline %d out of range (%d deadlines)", dlIdx, di.WPoStPeriodDeadlines)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := s.actor.MarshalCBOR(buf); err != nil {
+		return nil, xerrors.Errorf("failed to marshal address to cbor: %w", err)
+	}
+
+	rand, err := s.api.ChainGetRandomnessFromBeacon(ctx, ts.Key(), crypto.DomainSeparationTag_WindowedPoStChallengeSeed, ts.Height(), buf.Bytes())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get chain randomness from beacon for window post (ts=%d; deadline=%d): %w", ts.Height(), dlIdx, err)
+	}
+
+	partitions, err := s.api.StateMinerPartitions(ctx, s.actor, dlIdx, ts.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting partitions: %w", err)
+	}
+
+	mid, err := address.IDFromAddress(s.actor)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &api.WindowPoStDryRun{
+		Deadline:   dlIdx,
+		Height:     ts.Height(),
+		Partitions: make([]api.PartitionDryRun, 0, len(partitions)),
+	}
+
+	start := build.Clock.Now()
+	for partIdx, partition := range partitions {
+		res := s.computePartitionPoSt(ctx, abi.ActorID(mid), partition, abi.PoStRandomness(rand), ts)
+		res.Index = uint64(partIdx)
+
+		out.Partitions = append(out.Partitions, res)
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	out.Elapsed = build.Clock.Since(start)
+
+	return out, nil
+}
+
+func (s *WindowPoStScheduler) computePartitionPoSt(ctx context.Context, mid abi.ActorID, partition api.Partition, rand abi.PoStRandomness, ts *types.TipSet) api.PartitionDryRun {
+	var res api.PartitionDryRun
+
+	// Retry until we run out of sectors to prove, like runPost
+	postSkipped := bitfield.New()
+	for {
+		checkStart := build.Clock.Now()
+		sinfos, _, bad, err := s.sectorsToProve(ctx, partition, postSkipped, ts)
+		res.CheckTime += build.Clock.Since(checkStart)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+
+		res.Bad = bad
+		if len(sinfos) == 0 {
+			return res
+		}
+
+		proveStart := build.Clock.Now()
+		postOut, ps, err := s.prover.GenerateWindowPoSt(ctx, mid, sinfos, rand)
+		res.ProveTime += build.Clock.Since(proveStart)
+
+		if err == nil {
+			if len(postOut) == 0 {
+				res.Error = "received no proofs back from generate window post"
+				return res
+			}
+
+			res.Sectors = len(sinfos)
+			return res
+		}
+
+		if len(ps) == 0 {
+			res.Error = xerrors.Errorf("running window post failed: %w", err).Error()
+			return res
+		}
+
+		if ctx.Err() != nil {
+			res.Error = ctx.Err().Error()
+			return res
+		}
+
+		for _, sector := range ps {
+			postSkipped.Set(uint64(sector.Number))
+			res.Skipped = append(res.Skipped, sector.Number)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/filecoin-project/specs-storage/storage"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/mock"
+	"github.com/EpiK-Protocol/go-epik/journal"
+)
+
+// TestWDPostComputePoSt checks that a dry run reports the sectors failing the
+// checks and the sectors skipped by the prover for each partition.
+func TestWDPostComputePoSt(t *testing.T) {
+	ctx := context.Background()
+
+	postAct := tutils.NewIDAddr(t, 100)
+	sectorRef := func(n abi.SectorNumber) storage.SectorRef {
+		return storage.SectorRef{
+			ID:        abi.SectorID{Miner: 100, Number: n},
+			ProofType: abi.RegisteredSealProof_StackedDrg2KiBV1,
+		}
+	}
+
+	var genesis []abi.SectorID
+	mockStgMinerAPI := newMockStorageMinerAPI()
+	for p := uint64(0); p < 2; p++ {
+		sectors := bitfield.New()
+		for s := p * 4; s < p*4+4; s++ {
+			sectors.Set(s)
+			genesis = append(genesis, sectorRef(abi.SectorNumber(s)).ID)
+		}
+		mockStgMinerAPI.setPartitions([]api.Partition{{
+			AllSectors:        sectors,
+			FaultySectors:     bitfield.New(),
+			RecoveringSectors: bitfield.New(),
+			LiveSectors:       sectors,
+			ActiveSectors:     sectors,
+		}})
+	}
+
+	sm := mock.NewMockSectorMgr(genesis)
+	require.NoError(t, sm.MarkFailed(sectorRef(1), true))    // fails CheckProvable
+	require.NoError(t, sm.MarkCorrupted(sectorRef(5), true)) // fails proving
+
+	scheduler := &WindowPoStScheduler{
+		api:          mockStgMinerAPI,
+		prover:       sm,
+		faultTracker: sm,
+		proofType:    abi.RegisteredPoStProof_StackedDrgWindow2KiBV1,
+		actor:        postAct,
+		journal:      journal.NilJournal(),
+		addrSel:      &AddressSelector{},
+	}
+
+	res, err := scheduler.ComputePoSt(ctx, 3, mockTipSet(t))
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(3), res.Deadline)
+	require.Len(t, res.Partitions, 2)
+
+	p0 := res.Partitions[0]
+	require.Empty(t, p0.Error)
+	require.Equal(t, uint64(0), p0.Index)
+	require.Equal(t, 4, p0.Sectors)
+	require.Contains(t, p0.Bad, abi.SectorNumber(1))
+	require.Empty(t, p0.Skipped)
+
+	p1 := res.Partitions[1]
+	require.Empty(t, p1.Error)
+	require.Equal(t, uint64(1), p1.Index)
+	require.Equal(t, 4, p1.Sectors)
+	require.Empty(t, p1.Bad)
+	require.Equal(t, []abi.SectorNumber{5}, p1.Skipped)
+
+	_, err = scheduler.ComputePoSt(ctx, 1000, mockTipSet(t))
+	require.Error(t, err)
+}
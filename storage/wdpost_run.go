@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"context"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-bitfield"
@@ -97,6 +98,12 @@ func (s *WindowPoStScheduler) runGeneratePoST(
 	ctx, span := trace.StartSpan(ctx, "WindowPoStScheduler.generatePoST")
 	defer span.End()
 
+	// proofs are useless once the challenge window closes, the deadline also
+	// tells the prover how long it can wait for a worker before proving locally
+	closeAt := time.Now().Add(time.Duration(deadline.Close-ts.Height()) * time.Duration(build.BlockDelaySecs) * time.Second)
+	ctx, cancel := context.WithDeadline(ctx, closeAt)
+	defer cancel()
+
 	posts, err := s.runPost(ctx, *deadline, ts)
 	if err != nil {
 		log.Errorf("runPost failed: %+v", err)
@@ -190,15 +197,17 @@ func (s *WindowPoStScheduler) runSubmitPoST(
 	return submitErr
 }
 
-func (s *WindowPoStScheduler) checkSectors(ctx context.Context, check bitfield.BitField, tsk types.TipSetKey) (bitfield.BitField, error) {
+// checkSectors returns the sectors of check which can be proven, and the
+// reasons the others can't.
+func (s *WindowPoStScheduler) checkSectors(ctx context.Context, check bitfield.BitField, tsk types.TipSetKey) (bitfield.BitField, map[abi.SectorNumber]string, error) {
 	mid, err := address.IDFromAddress(s.actor)
 	if err != nil {
-		return bitfield.BitField{}, err
+		return bitfield.BitField{}, nil, err
 	}
 
 	sectorInfos, err := s.api.StateMinerSectors(ctx, s.actor, &check, tsk)
 	if err != nil {
-		return bitfield.BitField{}, err
+		return bitfield.BitField{}, nil, err
 	}
 
 	sectors := make(map[abi.SectorNumber]struct{})
@@ -216,10 +225,12 @@ func (s *WindowPoStScheduler) checkSectors(ctx context.Context, check bitfield.B
 
 	bad, err := s.faultTracker.CheckProvable(ctx, s.proofType, tocheck, nil)
 	if err != nil {
-		return bitfield.BitField{}, xerrors.Errorf("checking provable sectors: %w", err)
+		return bitfield.BitField{}, nil, xerrors.Errorf("checking provable sectors: %w", err)
 	}
-	for id := range bad {
+	badNums := make(map[abi.SectorNumber]string, len(bad))
+	for id, reason := range bad {
 		delete(sectors, id.Number)
+		badNums[id.Number] = reason
 	}
 
 	log.Warnw("Checked sectors", "checked", len(tocheck), "good", len(sectors))
@@ -229,7 +240,7 @@ func (s *WindowPoStScheduler) checkSectors(ctx context.Context, check bitfield.B
 		sbf.Set(uint64(s))
 	}
 
-	return sbf, nil
+	return sbf, badNums, nil
 }
 
 func (s *WindowPoStScheduler) checkNextRecoveries(ctx context.Context, dlIdx uint64, partitions []api.Partition, tsk types.TipSetKey) ([]miner.RecoveryDeclaration, *types.SignedMessage, error) {
@@ -258,7 +269,7 @@ func (s *WindowPoStScheduler) checkNextRecoveries(ctx context.Context, dlIdx uin
 
 		faulty += uc
 
-		recovered, err := s.checkSectors(ctx, unrecovered, tsk)
+		recovered, _, err := s.checkSectors(ctx, unrecovered, tsk)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("checking unrecovered sectors: %w", err)
 		}
@@ -339,7 +350,7 @@ func (s *WindowPoStScheduler) checkNextFaults(ctx context.Context, dlIdx uint64,
 			return nil, nil, xerrors.Errorf("determining non faulty sectors: %w", err)
 		}
 
-		good, err := s.checkSectors(ctx, nonFaulty, tsk)
+		good, _, err := s.checkSectors(ctx, nonFaulty, tsk)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("checking sectors: %w", err)
 		}
@@ -498,155 +509,213 @@ func (s *WindowPoStScheduler) runPost(ctx context.Context, di dline.Info, ts *ty
 		return nil, err
 	}
 
-	// Generate proofs in batches
-	posts := make([]miner.SubmitWindowedPoStParams, 0, len(partitionBatches))
+	mid, err := address.IDFromAddress(s.actor)
+	if err != nil {
+		return nil, err
+	}
+
+	parallel := s.proving.ParallelBatches
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	// Generate proofs in batches, proving several batches at once spreads
+	// them over the window PoSt workers
+	results := make([]*miner.SubmitWindowedPoStParams, len(partitionBatches))
+	errs := make([]error, len(partitionBatches))
+
+	throttle := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	batchPartitionStartIdx := 0
 	for batchIdx, batch := range partitionBatches {
-		batchPartitionStartIdx := 0
-		for _, batch := range partitionBatches[:batchIdx] {
-			batchPartitionStartIdx += len(batch)
-		}
-
-		params := miner.SubmitWindowedPoStParams{
-			Deadline:   di.Index,
-			Partitions: make([]miner.PoStPartition, 0, len(batch)),
-			Proofs:     nil,
-		}
-
-		skipCount := uint64(0)
-		postSkipped := bitfield.New()
-		somethingToProve := false
-
-		// Retry until we run out of sectors to prove.
-		for retries := 0; ; retries++ {
-			var partitions []miner.PoStPartition
-			var sinfos []proof2.SectorInfo
-			for partIdx, partition := range batch {
-				// TODO: Can do this in parallel
-				toProve, err := bitfield.SubtractBitField(partition.LiveSectors, partition.FaultySectors)
-				if err != nil {
-					return nil, xerrors.Errorf("removing faults from set of sectors to prove: %w", err)
-				}
-				toProve, err = bitfield.MergeBitFields(toProve, partition.RecoveringSectors)
-				if err != nil {
-					return nil, xerrors.Errorf("adding recoveries to set of sectors to prove: %w", err)
-				}
+		select {
+		case throttle <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
 
-				good, err := s.checkSectors(ctx, toProve, ts.Key())
-				if err != nil {
-					return nil, xerrors.Errorf("checking sectors to skip: %w", err)
-				}
+		wg.Add(1)
+		go func(batchIdx, startIdx int, batch []api.Partition) {
+			defer wg.Done()
+			defer func() {
+				<-throttle
+			}()
 
-				good, err = bitfield.SubtractBitField(good, postSkipped)
-				if err != nil {
-					return nil, xerrors.Errorf("toProve - postSkipped: %w", err)
-				}
+			results[batchIdx], errs[batchIdx] = s.proveBatch(ctx, di, ts, abi.ActorID(mid), abi.PoStRandomness(rand), batchIdx, startIdx, batch)
+		}(batchIdx, batchPartitionStartIdx, batch)
 
-				skipped, err := bitfield.SubtractBitField(toProve, good)
-				if err != nil {
-					return nil, xerrors.Errorf("toProve - good: %w", err)
-				}
+		batchPartitionStartIdx += len(batch)
+	}
+	wg.Wait()
 
-				sc, err := skipped.Count()
-				if err != nil {
-					return nil, xerrors.Errorf("getting skipped sector count: %w", err)
-				}
+	posts := make([]miner.SubmitWindowedPoStParams, 0, len(partitionBatches))
+	for batchIdx, params := range results {
+		if errs[batchIdx] != nil {
+			return nil, errs[batchIdx]
+		}
 
-				skipCount += sc
+		// Nothing to prove for this batch
+		if params == nil {
+			continue
+		}
 
-				ssi, err := s.sectorsForProof(ctx, good, partition.AllSectors, ts)
-				if err != nil {
-					return nil, xerrors.Errorf("getting sorted sector info: %w", err)
-				}
+		posts = append(posts, *params)
+	}
 
-				if len(ssi) == 0 {
-					continue
-				}
+	return posts, nil
+}
 
-				sinfos = append(sinfos, ssi...)
-				partitions = append(partitions, miner.PoStPartition{
-					Index:   uint64(batchPartitionStartIdx + partIdx),
-					Skipped: skipped,
-				})
+// proveBatch generates the proof of a batch of partitions, starting at index
+// batchPartitionStartIdx of the deadline. It returns nil when there's nothing
+// to prove in the batch.
+func (s *WindowPoStScheduler) proveBatch(ctx context.Context, di dline.Info, ts *types.TipSet, mid abi.ActorID, rand abi.PoStRandomness, batchIdx, batchPartitionStartIdx int, batch []api.Partition) (*miner.SubmitWindowedPoStParams, error) {
+	params := miner.SubmitWindowedPoStParams{
+		Deadline:   di.Index,
+		Partitions: make([]miner.PoStPartition, 0, len(batch)),
+		Proofs:     nil,
+	}
+
+	skipCount := uint64(0)
+	postSkipped := bitfield.New()
+	somethingToProve := false
+
+	// Retry until we run out of sectors to prove.
+	for retries := 0; ; retries++ {
+		var partitions []miner.PoStPartition
+		var sinfos []proof2.SectorInfo
+		for partIdx, partition := range batch {
+			// TODO: Can do this in parallel
+			ssi, skipped, _, err := s.sectorsToProve(ctx, partition, postSkipped, ts)
+			if err != nil {
+				return nil, err
 			}
 
-			if len(sinfos) == 0 {
-				// nothing to prove for this batch
-				break
+			sc, err := skipped.Count()
+			if err != nil {
+				return nil, xerrors.Errorf("getting skipped sector count: %w", err)
 			}
 
-			snos := make([]uint64, 0, len(sinfos))
-			for _, si := range sinfos {
-				snos = append(snos, uint64(si.SectorNumber))
+			skipCount += sc
+
+			if len(ssi) == 0 {
+				continue
 			}
 
-			// Generate proof
-			log.Infow("running window post",
-				"chain-random", rand,
-				"deadline", di,
-				"height", ts.Height(),
-				"skipped", skipCount,
-				"sectorlen", len(sinfos),
-				"sectornumbers", snos)
+			sinfos = append(sinfos, ssi...)
+			partitions = append(partitions, miner.PoStPartition{
+				Index:   uint64(batchPartitionStartIdx + partIdx),
+				Skipped: skipped,
+			})
+		}
 
-			tsStart := build.Clock.Now()
+		if len(sinfos) == 0 {
+			// nothing to prove for this batch
+			break
+		}
 
-			mid, err := address.IDFromAddress(s.actor)
-			if err != nil {
-				return nil, err
-			}
+		snos := make([]uint64, 0, len(sinfos))
+		for _, si := range sinfos {
+			snos = append(snos, uint64(si.SectorNumber))
+		}
 
-			postOut, ps, err := s.prover.GenerateWindowPoSt(ctx, abi.ActorID(mid), sinfos, abi.PoStRandomness(rand))
-			elapsed := time.Since(tsStart)
+		// Generate proof
+		log.Infow("running window post",
+			"chain-random", rand,
+			"deadline", di,
+			"height", ts.Height(),
+			"skipped", skipCount,
+			"sectorlen", len(sinfos),
+			"sectornumbers", snos)
 
-			log.Infow("computing window post", "batch", batchIdx, "elapsed", elapsed)
+		tsStart := build.Clock.Now()
 
-			if err == nil {
-				if len(postOut) == 0 {
-					return nil, xerrors.Errorf("received no proofs back from generate window post")
-				}
+		postOut, ps, err := s.prover.GenerateWindowPoSt(ctx, mid, sinfos, rand)
+		elapsed := time.Since(tsStart)
+
+		log.Infow("computing window post", "batch", batchIdx, "elapsed", elapsed)
 
-				// Proof generation successful, stop retrying
-				somethingToProve = true
-				params.Partitions = partitions
-				params.Proofs = postOut
-				break
+		if err == nil {
+			if len(postOut) == 0 {
+				return nil, xerrors.Errorf("received no proofs back from generate window post")
 			}
 
-			// Proof generation failed, so retry
+			// Proof generation successful, stop retrying
+			somethingToProve = true
+			params.Partitions = partitions
+			params.Proofs = postOut
+			break
+		}
 
-			if len(ps) == 0 {
-				// If we didn't skip any new sectors, we failed
-				// for some other reason and we need to abort.
-				return nil, xerrors.Errorf("running window post failed: %w", err)
-			}
-			// TODO: maybe mark these as faulty somewhere?
+		// Proof generation failed, so retry
 
-			log.Warnw("generate window post skipped sectors", "sectors", ps, "error", err, "try", retries)
+		if len(ps) == 0 {
+			// If we didn't skip any new sectors, we failed
+			// for some other reason and we need to abort.
+			return nil, xerrors.Errorf("running window post failed: %w", err)
+		}
+		// TODO: maybe mark these as faulty somewhere?
 
-			// Explicitly make sure we haven't aborted this PoSt
-			// (GenerateWindowPoSt may or may not check this).
-			// Otherwise, we could try to continue proving a
-			// deadline after the deadline has ended.
-			if ctx.Err() != nil {
-				log.Warnw("aborting PoSt due to context cancellation", "error", ctx.Err(), "deadline", di.Index)
-				return nil, ctx.Err()
-			}
+		log.Warnw("generate window post skipped sectors", "sectors", ps, "error", err, "try", retries)
 
-			skipCount += uint64(len(ps))
-			for _, sector := range ps {
-				postSkipped.Set(uint64(sector.Number))
-			}
+		// Explicitly make sure we haven't aborted this PoSt
+		// (GenerateWindowPoSt may or may not check this).
+		// Otherwise, we could try to continue proving a
+		// deadline after the deadline has ended.
+		if ctx.Err() != nil {
+			log.Warnw("aborting PoSt due to context cancellation", "error", ctx.Err(), "deadline", di.Index)
+			return nil, ctx.Err()
 		}
 
-		// Nothing to prove for this batch, try the next batch
-		if !somethingToProve {
-			continue
+		skipCount += uint64(len(ps))
+		for _, sector := range ps {
+			postSkipped.Set(uint64(sector.Number))
 		}
+	}
 
-		posts = append(posts, params)
+	// Nothing to prove for this batch
+	if !somethingToProve {
+		return nil, nil
 	}
 
-	return posts, nil
+	return &params, nil
+}
+
+// sectorsToProve returns the sectors to include in the proof of a partition,
+// and the sectors to skip because they are in postSkipped or failed the
+// provable checks, with the reasons of the failures.
+func (s *WindowPoStScheduler) sectorsToProve(ctx context.Context, partition api.Partition, postSkipped bitfield.BitField, ts *types.TipSet) ([]proof2.SectorInfo, bitfield.BitField, map[abi.SectorNumber]string, error) {
+	toProve, err := bitfield.SubtractBitField(partition.LiveSectors, partition.FaultySectors)
+	if err != nil {
+		return nil, bitfield.BitField{}, nil, xerrors.Errorf("removing faults from set of sectors to prove: %w", err)
+	}
+	toProve, err = bitfield.MergeBitFields(toProve, partition.RecoveringSectors)
+	if err != nil {
+		return nil, bitfield.BitField{}, nil, xerrors.Errorf("adding recoveries to set of sectors to prove: %w", err)
+	}
+
+	good, bad, err := s.checkSectors(ctx, toProve, ts.Key())
+	if err != nil {
+		return nil, bitfield.BitField{}, nil, xerrors.Errorf("checking sectors to skip: %w", err)
+	}
+
+	good, err = bitfield.SubtractBitField(good, postSkipped)
+	if err != nil {
+		return nil, bitfield.BitField{}, nil, xerrors.Errorf("toProve - postSkipped: %w", err)
+	}
+
+	skipped, err := bitfield.SubtractBitField(toProve, good)
+	if err != nil {
+		return nil, bitfield.BitField{}, nil, xerrors.Errorf("toProve - good: %w", err)
+	}
+
+	ssi, err := s.sectorsForProof(ctx, good, partition.AllSectors, ts)
+	if err != nil {
+		return nil, bitfield.BitField{}, nil, xerrors.Errorf("getting sorted sector info: %w", err)
+	}
+
+	return ssi, skipped, bad, nil
 }
 
 func (s *WindowPoStScheduler) batchPartitions(partitions []api.Partition) ([][]api.Partition, error) {
@@ -665,6 +734,11 @@ func (s *WindowPoStScheduler) batchPartitions(partitions []api.Partition) ([][]a
 		return nil, xerrors.Errorf("getting sectors per partition: %w", err)
 	}
 
+	// Smaller batches can be proven in parallel by more workers
+	if limit := s.proving.MaxPartitionsPerPoStMessage; limit > 0 && limit < partitionsPerMsg {
+		partitionsPerMsg = limit
+	}
+
 	// The number of messages will be:
 	// ceiling(number of partitions / partitions per message)
 	batchCount := len(partitions) / partitionsPerMsg
@@ -29,6 +29,7 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/node/config"
 )
 
 type mockStorageMinerAPI struct {
@@ -183,14 +184,15 @@ func TestWDPostDoPost(t *testing.T) {
 		addrSel:      &AddressSelector{},
 	}
 
+	ts := mockTipSet(t)
 	di := &dline.Info{
+		Close:                  ts.Height() + miner2.WPoStChallengeWindow,
 		WPoStPeriodDeadlines:   miner2.WPoStPeriodDeadlines,
 		WPoStProvingPeriod:     miner2.WPoStProvingPeriod,
 		WPoStChallengeWindow:   miner2.WPoStChallengeWindow,
 		WPoStChallengeLookback: miner2.WPoStChallengeLookback,
 		FaultDeclarationCutoff: miner2.FaultDeclarationCutoff,
 	}
-	ts := mockTipSet(t)
 
 	scheduler.startGeneratePoST(ctx, ts, di, func(posts []miner.SubmitWindowedPoStParams, err error) {
 		scheduler.startSubmitPoST(ctx, ts, di, posts, func(err error) {})
@@ -214,6 +216,60 @@ func TestWDPostDoPost(t *testing.T) {
 	}
 }
 
+func TestWDPostParallelBatches(t *testing.T) {
+	ctx := context.Background()
+
+	proofType := abi.RegisteredPoStProof_StackedDrgWindow2KiBV1
+	sectorsPerPartition, err := builtin2.PoStProofWindowPoStPartitionSectors(proofType)
+	require.NoError(t, err)
+
+	mockStgMinerAPI := newMockStorageMinerAPI()
+
+	var partitions []api.Partition
+	for p := 0; p < 5; p++ {
+		sectors := bitfield.New()
+		for s := uint64(0); s < sectorsPerPartition; s++ {
+			sectors.Set(s)
+		}
+		partitions = append(partitions, api.Partition{
+			AllSectors:        sectors,
+			FaultySectors:     bitfield.New(),
+			RecoveringSectors: bitfield.New(),
+			LiveSectors:       sectors,
+			ActiveSectors:     sectors,
+		})
+	}
+	mockStgMinerAPI.setPartitions(partitions)
+
+	scheduler := &WindowPoStScheduler{
+		api:          mockStgMinerAPI,
+		prover:       &mockProver{},
+		faultTracker: &mockFaultTracker{},
+		proofType:    proofType,
+		actor:        tutils.NewIDAddr(t, 100),
+		journal:      journal.NilJournal(),
+		addrSel:      &AddressSelector{},
+		proving: config.ProvingConfig{
+			ParallelBatches:             3,
+			MaxPartitionsPerPoStMessage: 2,
+		},
+	}
+
+	posts, err := scheduler.runPost(ctx, dline.Info{}, mockTipSet(t))
+	require.NoError(t, err)
+
+	// batches are proven at once, but keep the order of the partitions
+	var batches [][]uint64
+	for _, post := range posts {
+		var idxs []uint64
+		for _, p := range post.Partitions {
+			idxs = append(idxs, p.Index)
+		}
+		batches = append(batches, idxs)
+	}
+	require.Equal(t, [][]uint64{{0, 1}, {2, 3}, {4}}, batches)
+}
+
 func mockTipSet(t *testing.T) *types.TipSet {
 	minerAct := tutils.NewActorAddr(t, "miner")
 	c, err := cid.Decode("QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH")